@@ -0,0 +1,171 @@
+// Package audit implements the tamper-evident audit log.
+// Every event carries the keyed hash of the previous one so any modified,
+// removed or reordered event breaks the chain. The chain head is kept
+// apart from the events so removed last events are detected too.
+package audit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"gophkeeper/db/db"
+)
+
+const keyLength = 32 // bytes
+
+var ErrInvalidKey = fmt.Errorf("audit key must be %d hex encoded bytes", keyLength)
+
+// Event types.
+const (
+	EventRegister         = "register"
+	EventLogin            = "login"
+	EventLoginFailed      = "login_failed"
+	EventPasswordChanged  = "password_changed"
+	EventAccountDeleted   = "account_deleted"
+	EventTOTPEnabled      = "totp_enabled"
+	EventSessionRevoked   = "session_revoked"
+	EventSecretCreated    = "secret_created"
+	EventSecretUpdated    = "secret_updated"
+	EventSecretDeleted    = "secret_deleted"
+	EventSecretRestored   = "secret_restored"
+	EventSecretsForgotten = "secrets_forgotten"
+	EventVaultKeyCreated  = "vault_key_created"
+	EventVaultRekeyed     = "vault_rekeyed"
+)
+
+// verifyBatchSize is how many events are read from the db at once during verification.
+const verifyBatchSize = 1000
+
+// GenerateKey returns a new hex encoded audit key.
+func GenerateKey() (string, error) {
+	key := make([]byte, keyLength)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(key), nil
+}
+
+// ParseKey decodes the hex encoded audit key.
+func ParseKey(key string) ([]byte, error) {
+	decoded, err := hex.DecodeString(key)
+	if err != nil || len(decoded) != keyLength {
+		return nil, ErrInvalidKey
+	}
+
+	return decoded, nil
+}
+
+// Hash returns the keyed hash of the event chained to the previous event hash.
+// The event ID and hashes are not hashed - the chain itself keeps the order.
+// Without the key the hashes can't be recomputed after the event is changed.
+func Hash(key []byte, prevHash string, event db.AuditEvent) string {
+	hash := hmac.New(sha256.New, key)
+	fmt.Fprintf(
+		hash,
+		"%q|%q|%q|%q|%d|%q|%s",
+		prevHash,
+		event.Type,
+		event.Username,
+		event.Peer,
+		event.SecretKind,
+		event.SecretName,
+		event.Created.UTC().Format(time.RFC3339Nano),
+	)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// HeadMAC returns the keyed hash of the chain head. Unlike the event hash it can't be
+// taken from an earlier event, so the head can't be moved back over removed events.
+func HeadMAC(key []byte, eventID int64, hash string) string {
+	mac := hmac.New(sha256.New, key)
+	fmt.Fprintf(mac, "head|%d|%q", eventID, hash)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Timestamp returns the event time with the precision the db keeps
+// so the event hash is the same after it is read back.
+func Timestamp(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Microsecond)
+}
+
+// Verify checks the whole audit log chain up to its head and returns the number of verified events.
+// The error names the first event the chain is broken at.
+func Verify(ctx context.Context, storage db.Querier, key []byte) (int, error) {
+	var (
+		prevHash string
+		lastID   int64
+		verified int
+	)
+
+	// Events appended after the head is read are verified next time
+	head, err := storage.GetAuditHead(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return verified, errors.New("audit log head is missing")
+	}
+	if err != nil {
+		return verified, fmt.Errorf("failed to read audit log head: %w", err)
+	}
+
+	// The head is authenticated when the first event is recorded
+	if head.EventID == 0 && head.Hash == "" && head.Mac == "" {
+		events, err := storage.GetAuditEvents(ctx, db.GetAuditEventsParams{ID: 0, Limit: verifyBatchSize})
+		if err != nil {
+			return verified, fmt.Errorf("failed to read audit events: %w", err)
+		}
+		if len(events) > 0 {
+			return verified, errors.New("audit log head is reset")
+		}
+
+		return verified, nil
+	}
+
+	if !hmac.Equal([]byte(HeadMAC(key, head.EventID, head.Hash)), []byte(head.Mac)) {
+		return verified, errors.New("audit log head hash mismatch")
+	}
+
+walk:
+	for {
+		events, err := storage.GetAuditEvents(ctx, db.GetAuditEventsParams{ID: lastID, Limit: verifyBatchSize})
+		if err != nil {
+			return verified, fmt.Errorf("failed to read audit events: %w", err)
+		}
+
+		for _, event := range events {
+			if event.ID > head.EventID {
+				break walk
+			}
+
+			if event.PrevHash != prevHash {
+				return verified, fmt.Errorf("audit event %d is not linked to the previous event", event.ID)
+			}
+
+			if !hmac.Equal([]byte(Hash(key, prevHash, event)), []byte(event.Hash)) {
+				return verified, fmt.Errorf("audit event %d hash mismatch", event.ID)
+			}
+
+			prevHash = event.Hash
+			lastID = event.ID
+			verified++
+		}
+
+		if len(events) < verifyBatchSize {
+			break
+		}
+	}
+
+	if lastID != head.EventID || prevHash != head.Hash {
+		return verified, fmt.Errorf("audit events after event %d are missing", lastID)
+	}
+
+	return verified, nil
+}
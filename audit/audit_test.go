@@ -0,0 +1,188 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/db/db"
+	"gophkeeper/db/mock"
+	"gophkeeper/random"
+)
+
+var testKey = []byte("the-key-has-to-be-32-bytes-long!")
+
+func newTestChain(n int) []db.AuditEvent {
+	events := []db.AuditEvent{}
+
+	prevHash := ""
+	for i := 0; i < n; i++ {
+		event := db.AuditEvent{
+			ID:         int64(i + 1),
+			Type:       EventSecretCreated,
+			Username:   random.RandomOwner(),
+			Peer:       "127.0.0.1:54321",
+			SecretKind: 1,
+			SecretName: random.RandomString(10),
+			Created:    Timestamp(time.Now()),
+			PrevHash:   prevHash,
+		}
+		event.Hash = Hash(testKey, prevHash, event)
+
+		events = append(events, event)
+		prevHash = event.Hash
+	}
+
+	return events
+}
+
+func TestHash(t *testing.T) {
+	events := newTestChain(2)
+
+	require.Equal(t, events[1].Hash, Hash(testKey, events[0].Hash, events[1]))
+	require.NotEqual(t, events[1].Hash, Hash(testKey, "", events[1]))
+
+	// Same time in another location
+	event := events[1]
+	event.Created = event.Created.In(time.FixedZone("UTC+3", 3*60*60))
+	require.Equal(t, events[1].Hash, Hash(testKey, events[0].Hash, event))
+
+	event.SecretName = "tampered"
+	require.NotEqual(t, events[1].Hash, Hash(testKey, events[0].Hash, event))
+
+	// Hash can't be recomputed without the key
+	require.NotEqual(t, events[1].Hash, Hash([]byte("another-key-of-the-same-32-bytes"), events[0].Hash, events[1]))
+}
+
+func TestParseKey(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+
+	decoded, err := ParseKey(key)
+	require.NoError(t, err)
+	require.Len(t, decoded, keyLength)
+
+	_, err = ParseKey("")
+	require.ErrorIs(t, err, ErrInvalidKey)
+
+	_, err = ParseKey(key[:10])
+	require.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func newTestHead(event db.AuditEvent) db.AuditHead {
+	return db.AuditHead{EventID: event.ID, Hash: event.Hash, Mac: HeadMAC(testKey, event.ID, event.Hash)}
+}
+
+func TestVerify(t *testing.T) {
+	events := newTestChain(3)
+	head := newTestHead(events[2])
+
+	tests := []struct {
+		name     string
+		head     db.AuditHead
+		events   func() []db.AuditEvent
+		verified int
+		err      string
+	}{
+		{
+			name:     "intact",
+			head:     head,
+			events:   func() []db.AuditEvent { return events },
+			verified: 3,
+		},
+		{
+			name:     "events appended after the head",
+			head:     newTestHead(events[1]),
+			events:   func() []db.AuditEvent { return events },
+			verified: 2,
+		},
+		{
+			name: "tampered event",
+			head: head,
+			events: func() []db.AuditEvent {
+				tampered := append([]db.AuditEvent{}, events...)
+				tampered[1].SecretName = "tampered"
+				return tampered
+			},
+			verified: 1,
+			err:      "audit event 2 hash mismatch",
+		},
+		{
+			name: "removed event",
+			head: head,
+			events: func() []db.AuditEvent {
+				return []db.AuditEvent{events[0], events[2]}
+			},
+			verified: 1,
+			err:      "audit event 3 is not linked to the previous event",
+		},
+		{
+			name: "removed last event",
+			head: head,
+			events: func() []db.AuditEvent {
+				return events[:2]
+			},
+			verified: 2,
+			err:      "audit events after event 2 are missing",
+		},
+		{
+			name: "removed all events",
+			head: head,
+			events: func() []db.AuditEvent {
+				return []db.AuditEvent{}
+			},
+			err: "audit events after event 0 are missing",
+		},
+		{
+			name:   "no events",
+			head:   db.AuditHead{ID: 1},
+			events: func() []db.AuditEvent { return []db.AuditEvent{} },
+		},
+		{
+			name:   "head reset",
+			head:   db.AuditHead{ID: 1},
+			events: func() []db.AuditEvent { return events },
+			err:    "audit log head is reset",
+		},
+		{
+			name: "head moved back",
+			head: db.AuditHead{EventID: events[1].ID, Hash: events[1].Hash, Mac: head.Mac},
+			events: func() []db.AuditEvent {
+				return events[:2]
+			},
+			err: "audit log head hash mismatch",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mock storage
+			controller := gomock.NewController(t)
+			mockStorage := mock.NewMockQuerier(controller)
+
+			mockStorage.EXPECT().
+				GetAuditHead(gomock.Any()).
+				Times(1).
+				Return(tc.head, nil)
+
+			mockStorage.EXPECT().
+				GetAuditEvents(
+					gomock.Any(),
+					db.GetAuditEventsParams{ID: 0, Limit: verifyBatchSize},
+				).
+				MaxTimes(1).
+				Return(tc.events(), nil)
+
+			verified, err := Verify(context.Background(), mockStorage, testKey)
+			if tc.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.err)
+			}
+			require.Equal(t, tc.verified, verified)
+		})
+	}
+}
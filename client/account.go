@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"gophkeeper/pb"
+)
+
+// authorize gets the token for one-off account commands.
+// Unlike login it never registers a new user.
+func (c *Client) authorize(ctx context.Context) error {
+	_, err := c.g.Ping(ctx, &emptypb.Empty{})
+	if err != nil {
+		return err
+	}
+
+	err = c.loadCachedRefreshToken(ctx)
+	if err == nil && c.refresh(ctx) == nil {
+		return nil
+	}
+
+	tokenResponse, err := c.g.Login(c.deviceContext(ctx), &pb.User{Name: c.config.User, Password: c.config.Password})
+	if err != nil {
+		if !isSecondFactorError(err) || c.codePrompt == nil {
+			return err
+		}
+
+		code, err := c.codePrompt()
+		if err != nil {
+			return err
+		}
+
+		return c.loginWithCode(ctx, code)
+	}
+
+	c.setTokens(tokenResponse)
+
+	return nil
+}
+
+// SetCodePrompt sets the function asking the user for the second factor code in one-off account commands.
+func (c *Client) SetCodePrompt(prompt func() (string, error)) {
+	c.codePrompt = prompt
+}
+
+// ChangePassword sets the new user password. All the other user sessions are revoked.
+func (c *Client) ChangePassword(ctx context.Context, newPassword string) error {
+	err := c.authorize(ctx)
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to authorize user '%s'", c.config.User)
+		return err
+	}
+
+	_, err = c.g.ChangePassword(
+		c.authContext(ctx),
+		&pb.ChangePasswordRequest{
+			Password:    c.config.Password,
+			NewPassword: newPassword,
+		},
+	)
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to change user '%s' password", c.config.User)
+		return err
+	}
+
+	c.log.Info().Msgf("successfully changed user '%s' password", c.config.User)
+
+	return nil
+}
+
+// DeleteAccount deletes the user with all the user secrets on the server
+// and wipes the local ones so they are not synced back.
+func (c *Client) DeleteAccount(ctx context.Context) error {
+	err := c.authorize(ctx)
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to authorize user '%s'", c.config.User)
+		return err
+	}
+
+	_, err = c.g.DeleteAccount(c.authContext(ctx), &pb.DeleteAccountRequest{Password: c.config.Password})
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to delete user '%s' account", c.config.User)
+		return err
+	}
+
+	c.log.Info().Msgf("successfully deleted user '%s' account", c.config.User)
+
+	err = c.storage.DeleteUserWithSecrets(ctx, c.config.User)
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to delete user '%s' local secrets", c.config.User)
+		return err
+	}
+
+	c.token = ""
+	c.refreshToken = ""
+
+	return nil
+}
+
+// EnrollTOTP starts two-factor authentication enrollment.
+// It is not enabled until the first code is confirmed with ConfirmTOTP.
+func (c *Client) EnrollTOTP(ctx context.Context) (*pb.TOTPEnrollment, error) {
+	err := c.authorize(ctx)
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to authorize user '%s'", c.config.User)
+		return nil, err
+	}
+
+	enrollment, err := c.g.EnrollTOTP(c.authContext(ctx), &pb.TOTPEnrollRequest{Password: c.config.Password})
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to enroll user '%s' totp", c.config.User)
+		return nil, err
+	}
+
+	return enrollment, nil
+}
+
+// ConfirmTOTP enables two-factor authentication with the first code from the authenticator.
+func (c *Client) ConfirmTOTP(ctx context.Context, code string) error {
+	_, err := c.g.ConfirmTOTP(c.authContext(ctx), &pb.TOTPCode{Code: code})
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to confirm user '%s' totp", c.config.User)
+		return err
+	}
+
+	c.log.Info().Msgf("successfully enabled user '%s' two-factor authentication", c.config.User)
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package client
+
+import (
+	"context"
+
+	"gophkeeper/pb"
+)
+
+// ListAuditEvents returns the latest audit events of the user, newest first.
+// End-to-end encrypted secrets are recorded without their names.
+func (c *Client) ListAuditEvents(ctx context.Context, limit int32) ([]*pb.AuditEvent, error) {
+	err := c.authorize(ctx)
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to authorize user '%s'", c.config.User)
+		return nil, err
+	}
+
+	pbEvents, err := c.g.ListAuditEvents(c.authContext(ctx), &pb.AuditEventsRequest{Limit: limit})
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to list user '%s' audit events", c.config.User)
+		return nil, err
+	}
+
+	return pbEvents.Events, nil
+}
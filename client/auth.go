@@ -2,30 +2,88 @@ package client
 
 import (
 	"context"
+	"crypto/ed25519"
 	"errors"
 	"fmt"
 	"os"
 	"time"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
 
+	"gophkeeper/db/db"
 	"gophkeeper/pb"
 	"gophkeeper/token"
+	"gophkeeper/totp"
 )
 
 var (
-	tokenCachedDir      = os.Getenv("HOME") + "/.cache/gophkeeper/"
-	tokenCachedFileName = "token"
+	legacyTokenDir = os.Getenv("HOME") + "/.cache/gophkeeper/"
+
+	errNoTokenKeys = errors.New("token keys are not loaded")
 )
 
-func (c *Client) loadCachedToken(filepath string) error {
-	tokenBytes, err := os.ReadFile(filepath)
+// defaultLoginRetryDelay is used if the server throttles login with no retry info.
+const defaultLoginRetryDelay = time.Minute
+
+// Version is the client version reported to the server on login.
+var Version = "dev"
+
+// authContext provides the token to the server.
+func (c *Client) authContext(ctx context.Context) context.Context {
+	md := metadata.Pairs("token", c.token)
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// deviceContext provides the device info for the new session to the server.
+func (c *Client) deviceContext(ctx context.Context) context.Context {
+	md := metadata.Pairs("device", c.config.Device, "version", Version)
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// loadTokenKeys fetches server public keys to verify tokens with
+// unless they are already loaded (e.g. set in the config).
+func (c *Client) loadTokenKeys(ctx context.Context) error {
+	if c.tv != nil {
+		return nil
+	}
+
+	pbKeys, err := c.g.GetTokenKeys(ctx, &emptypb.Empty{})
+	if err != nil {
+		return err
+	}
+
+	keys := []token.PublicKey{}
+	for _, pbKey := range pbKeys.Keys {
+		if len(pbKey.Key) != ed25519.PublicKeySize {
+			return fmt.Errorf("server sent invalid token key '%s'", pbKey.Id)
+		}
+
+		keys = append(keys, token.PublicKey{ID: pbKey.Id, Key: ed25519.PublicKey(pbKey.Key)})
+	}
+
+	verifier := token.NewPasetoVerifier(keys...)
+	c.tv = &verifier
+
+	c.log.Info().Msgf("successfully loaded %v token keys", len(keys))
+
+	return nil
+}
+
+func (c *Client) loadCachedToken(ctx context.Context) error {
+	if c.tv == nil {
+		return errNoTokenKeys
+	}
+
+	tokens, err := c.storage.GetCachedTokens(ctx, c.config.User)
 	if err != nil {
 		return err
 	}
 
-	payload, err := c.tm.VerifyToken(string(tokenBytes))
+	payload, err := c.tv.VerifyToken(tokens.Token)
 	if err != nil {
 		if errors.Is(err, token.ErrInvalidToken) {
 			return err
@@ -39,35 +97,40 @@ func (c *Client) loadCachedToken(filepath string) error {
 		return fmt.Errorf("config user name differs from token user name")
 	}
 
-	c.token = string(tokenBytes)
+	c.token = tokens.Token
 
 	return nil
 }
 
-func (c *Client) saveToken(dir, filename, token string) error {
-	err := os.MkdirAll(dir, 0700)
+func (c *Client) loadCachedRefreshToken(ctx context.Context) error {
+	tokens, err := c.storage.GetCachedTokens(ctx, c.config.User)
 	if err != nil {
 		return err
 	}
 
-	file, err := os.OpenFile(dir+filename, os.O_WRONLY|os.O_CREATE, 0666)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	_, err = file.Write([]byte(token))
-	if err != nil {
-		return err
-	}
+	c.refreshToken = tokens.RefreshToken
 
 	return nil
 }
 
+// removeLegacyTokens removes the plaintext token files the tokens were cached in
+// before they moved to the local database.
+func removeLegacyTokens() {
+	for _, filename := range []string{"token", "refresh"} {
+		os.Remove(legacyTokenDir + filename)
+	}
+}
+
 func (c *Client) login(ctx context.Context) {
 	c.log.Info().Msg("periodic auth check started...")
 
-	_, err := c.tm.VerifyToken(string(c.token))
+	err := c.loadTokenKeys(ctx)
+	if err != nil {
+		c.log.Error().Err(err).Msg("failed to load token keys")
+		return
+	}
+
+	_, err = c.tv.VerifyToken(string(c.token))
 	if err == nil {
 		c.log.Info().Msg("existing token is still valid")
 		return
@@ -76,8 +139,42 @@ func (c *Client) login(ctx context.Context) {
 		c.log.Warn().Msg("token has expired...renewing")
 	}
 
-	tokenResponse, err := c.g.Login(ctx, &pb.User{Name: c.config.User, Password: c.config.Password})
+	// Prefer refresh token over sending the password
+	if c.refreshToken != "" {
+		err = c.refresh(ctx)
+		if err == nil {
+			return
+		}
+
+		e, _ := status.FromError(err)
+		if e.Code() != codes.Unauthenticated {
+			c.log.Error().Err(err).Msg("failed to refresh token")
+			return
+		}
+
+		c.log.Warn().Msgf("refresh token rejected: %s...logging in", e.Message())
+		c.refreshToken = ""
+	}
+
+	// Password login is pointless until the user enters the code
+	if c.secondFactorRequired.Load() {
+		c.log.Info().Msg("waiting for second factor code")
+		return
+	}
+
+	if time.Now().Before(c.loginRetryAt) {
+		c.log.Info().Msgf("login attempts are throttled until %s", c.loginRetryAt.Format(time.RFC3339))
+		return
+	}
+
+	tokenResponse, err := c.g.Login(c.deviceContext(ctx), &pb.User{Name: c.config.User, Password: c.config.Password})
 	if err != nil {
+		if isSecondFactorError(err) {
+			c.secondFactorRequired.Store(true)
+			c.log.Warn().Msgf("second factor required for user '%s'", c.config.User)
+			return
+		}
+
 		e, ok := status.FromError(err)
 		if !ok {
 			c.log.Error().Err(err).Msgf("failed to parse login attempt error")
@@ -89,24 +186,97 @@ func (c *Client) login(ctx context.Context) {
 			c.log.Warn().Msgf("server connection failed: %s", e.Message())
 		case codes.InvalidArgument:
 			c.log.Error().Msgf("%s: user must be 3-100 letter/digits, password - 6-100 letters", e.Message())
-		case codes.NotFound:
-			switch e.Message() {
-			case "user not found":
-				c.log.Info().Msg("user does not exist")
-				c.register(ctx)
-			case "incorrect password":
-				c.log.Error().Msgf("incorrect password for user, %s", c.config.User)
-			}
+		case codes.Unauthenticated:
+			// Server doesn't tell unknown users from wrong passwords so try to register
+			c.log.Info().Msgf("%s...trying to register", e.Message())
+			c.register(ctx)
+		case codes.ResourceExhausted:
+			c.throttleLogin(e)
 		case codes.Internal:
 			c.log.Error().Msgf("failed to login: %s", e.Message())
 		}
 		return
 	}
 
-	c.token = tokenResponse.Value
+	c.setTokens(tokenResponse)
 	c.log.Info().Msgf("successfully logged in with user '%s'", c.config.User)
+}
+
+// loginWithCode logs in with the second factor code.
+func (c *Client) loginWithCode(ctx context.Context, code string) error {
+	tokenResponse, err := c.g.Login(c.deviceContext(ctx), &pb.User{Name: c.config.User, Password: c.config.Password, Otp: code})
+	if err != nil {
+		return err
+	}
+
+	c.setTokens(tokenResponse)
+	c.secondFactorRequired.Store(false)
+	c.log.Info().Msgf("successfully logged in with user '%s' using second factor", c.config.User)
+
+	return nil
+}
+
+// throttleLogin postpones login attempts for the time the server asked to wait.
+func (c *Client) throttleLogin(e *status.Status) {
+	wait := defaultLoginRetryDelay
+	for _, detail := range e.Details() {
+		if retryInfo, ok := detail.(*errdetails.RetryInfo); ok {
+			wait = retryInfo.RetryDelay.AsDuration()
+		}
+	}
+
+	c.loginRetryAt = time.Now().Add(wait)
+	c.log.Warn().Msgf("%s: next login attempt in %s", e.Message(), wait)
+}
+
+// isSecondFactorError reports whether login failed because of a missing or invalid second factor code.
+func isSecondFactorError(err error) bool {
+	e, ok := status.FromError(err)
+	if !ok || e.Code() != codes.Unauthenticated {
+		return false
+	}
 
-	c.saveToken(tokenCachedDir, tokenCachedFileName, tokenResponse.Value)
+	for _, detail := range e.Details() {
+		if errorInfo, ok := detail.(*errdetails.ErrorInfo); ok && errorInfo.Domain == totp.ErrorDomain {
+			return errorInfo.Reason == totp.SecondFactorRequired || errorInfo.Reason == totp.SecondFactorInvalid
+		}
+	}
+
+	return false
+}
+
+// refresh exchanges the refresh token for a new pair of tokens.
+func (c *Client) refresh(ctx context.Context) error {
+	tokenResponse, err := c.g.RefreshToken(ctx, &pb.RefreshRequest{Refresh: c.refreshToken})
+	if err != nil {
+		return err
+	}
+
+	c.setTokens(tokenResponse)
+	c.log.Info().Msgf("successfully refreshed token of user '%s'", c.config.User)
+
+	return nil
+}
+
+// setTokens sets and caches the access and refresh tokens.
+func (c *Client) setTokens(tokenResponse *pb.Token) {
+	c.token = tokenResponse.Value
+	c.refreshToken = tokenResponse.Refresh
+
+	err := c.storage.SaveCachedTokens(
+		context.Background(),
+		db.SaveCachedTokensParams{
+			Owner:        c.config.User,
+			Token:        tokenResponse.Value,
+			RefreshToken: tokenResponse.Refresh,
+		},
+	)
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to save tokens")
+		return
+	}
+
+	c.log.Info().Msgf("successfully saved tokens to the local database")
 }
 
 func (c *Client) loginJob(ctx context.Context) {
@@ -128,7 +298,7 @@ func (c *Client) loginJob(ctx context.Context) {
 func (c *Client) register(ctx context.Context) {
 	c.log.Info().Msgf("trying to register with user '%s'...", c.config.User)
 
-	tokenResponse, err := c.g.Register(ctx, &pb.User{Name: c.config.User, Password: c.config.Password})
+	tokenResponse, err := c.g.Register(c.deviceContext(ctx), &pb.User{Name: c.config.User, Password: c.config.Password})
 	if err != nil {
 		e, ok := status.FromError(err)
 		if !ok {
@@ -137,22 +307,17 @@ func (c *Client) register(ctx context.Context) {
 		}
 
 		switch e.Code() {
-		case codes.AlreadyExists:
-			c.log.Error().Msgf("user '%s' already exists", c.config.User)
+		case codes.Unauthenticated:
+			c.log.Error().Msgf("failed to register: %s", e.Message())
+		case codes.ResourceExhausted:
+			c.throttleLogin(e)
 		case codes.Internal:
 			c.log.Error().Msgf("failed to register: %s", e.Message())
 		}
 		return
 	}
 
-	err = c.saveToken(tokenCachedDir, tokenCachedFileName, tokenResponse.Value)
-	if err != nil {
-		c.log.Error().Err(err).Msgf("failed to save token")
-	} else {
-		c.log.Info().Msgf("successfully saved token to cache file")
-	}
-
-	c.token = tokenResponse.Value
+	c.setTokens(tokenResponse)
 
 	c.log.Info().Msgf("successfully registerd with user '%s'", c.config.User)
 }
@@ -1,13 +1,21 @@
 package client
 
 import (
-	"os"
+	"context"
+	"database/sql"
 	"testing"
 	"time"
 
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 
+	"gophkeeper/pb"
 	"gophkeeper/token"
+	"gophkeeper/totp"
 )
 
 const (
@@ -15,58 +23,106 @@ const (
 )
 
 func TestLoadCachedToken(t *testing.T) {
+	key, err := token.GenerateKey()
+	require.NoError(t, err)
+
+	keyring, err := token.NewKeyring(key)
+	require.NoError(t, err)
+
+	tm := token.NewPasetoMaker(keyring)
+
 	client := Client{
-		config: Config{User: testUser},
-		tm:     token.NewPasetoMaker(),
+		config:  Config{User: testUser},
+		storage: newTestStorage(t, testUser),
+		log:     zerolog.Nop(),
 	}
 
-	// Test load token from missing file
-	err := client.loadCachedToken("/tmp/doesnotexist")
+	// Test load token without token keys
+	err = client.loadCachedToken(context.Background())
 	require.Error(t, err)
+	require.Equal(t, err, errNoTokenKeys)
 
-	// Test load token from empty file
-	emptyFile, err := os.CreateTemp("", "empty")
-	require.NoError(t, err)
-	defer os.Remove(emptyFile.Name())
+	verifier := token.NewPasetoVerifier(tm.PublicKeys()...)
+	client.tv = &verifier
+
+	// Test load token never saved
+	err = client.loadCachedToken(context.Background())
+	require.ErrorIs(t, err, sql.ErrNoRows)
 
-	err = client.loadCachedToken(emptyFile.Name())
+	// Test load empty token
+	client.setTokens(&pb.Token{})
+
+	err = client.loadCachedToken(context.Background())
 	require.Error(t, err)
 	require.Equal(t, err, token.ErrInvalidToken)
 
 	// Test load expired token
-	tm := token.NewPasetoMaker()
-	expiredToken, err := tm.CreateToken(testUser, -time.Minute)
-
-	expiredTokenFile, err := os.CreateTemp("", "testToken")
-	require.NoError(t, err, "failed to create temp file for test token")
-	defer os.Remove(expiredTokenFile.Name())
+	expiredToken, err := tm.CreateToken(testUser, "", -time.Minute)
+	require.NoError(t, err)
 
-	_, err = expiredTokenFile.Write([]byte(expiredToken))
-	require.NoError(t, err, "failed to write test token to temp file")
+	client.setTokens(&pb.Token{Value: expiredToken})
 
-	err = client.loadCachedToken(expiredTokenFile.Name())
+	err = client.loadCachedToken(context.Background())
 	require.Error(t, err)
 	require.Equal(t, err, token.ErrExpiredToken)
 
 	// Test load valid token
-	testToken, err := tm.CreateToken(testUser, time.Minute)
+	testToken, err := tm.CreateToken(testUser, "", time.Minute)
+	require.NoError(t, err)
 
-	validTokenFile, err := os.CreateTemp("", "testToken")
-	require.NoError(t, err, "failed to create temp file for test token")
-	defer os.Remove(validTokenFile.Name())
+	client.setTokens(&pb.Token{Value: testToken})
+	client.token = ""
 
-	_, err = validTokenFile.Write([]byte(testToken))
-	require.NoError(t, err, "failed to write test token to temp file")
+	err = client.loadCachedToken(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, testToken, client.token)
+}
 
-	err = client.loadCachedToken(validTokenFile.Name())
+func TestLoadCachedRefreshToken(t *testing.T) {
+	client := Client{
+		config:  Config{User: testUser},
+		storage: newTestStorage(t, testUser),
+		log:     zerolog.Nop(),
+	}
+
+	// Test load refresh token never saved
+	err := client.loadCachedRefreshToken(context.Background())
+	require.Error(t, err)
+
+	// Test rotated refresh token replaces the old one
+	client.setTokens(&pb.Token{Value: "token", Refresh: "oldRefreshToken"})
+	client.setTokens(&pb.Token{Value: "token", Refresh: "newRefreshToken"})
+	client.refreshToken = ""
+
+	err = client.loadCachedRefreshToken(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "newRefreshToken", client.refreshToken)
+}
+
+func TestIsSecondFactorError(t *testing.T) {
+	st, err := status.New(codes.Unauthenticated, "second factor required").
+		WithDetails(&errdetails.ErrorInfo{Reason: totp.SecondFactorRequired, Domain: totp.ErrorDomain})
 	require.NoError(t, err)
+	require.True(t, isSecondFactorError(st.Err()))
+
+	require.False(t, isSecondFactorError(status.Error(codes.Unauthenticated, "token is invalid")))
+	require.False(t, isSecondFactorError(status.Error(codes.NotFound, "incorrect password")))
 }
 
-func TestSaveToken(t *testing.T) {
-	tmpDir, err := os.MkdirTemp("", "tokenTmpDir")
+func TestThrottleLogin(t *testing.T) {
+	client := Client{
+		config: Config{User: testUser},
+		log:    zerolog.Nop(),
+	}
+
+	st, err := status.New(codes.ResourceExhausted, "too many attempts, try again later").
+		WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(time.Hour)})
 	require.NoError(t, err)
-	defer os.RemoveAll(tmpDir)
 
-	client := Client{}
-	err = client.saveToken(tmpDir, "tmpToken", "testToken")
+	client.throttleLogin(st)
+	require.WithinDuration(t, time.Now().Add(time.Hour), client.loginRetryAt, time.Minute)
+
+	// No retry info
+	client.throttleLogin(status.New(codes.ResourceExhausted, "too many attempts, try again later"))
+	require.WithinDuration(t, time.Now().Add(defaultLoginRetryDelay), client.loginRetryAt, time.Minute)
 }
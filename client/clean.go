@@ -21,12 +21,19 @@ func (c *Client) cleanJob(ctx context.Context) {
 	}
 }
 
+// clean runs every cleanup on its own, a failed one doesn't skip the others.
 func (c *Client) clean(ctx context.Context) {
-	deletedSecrets, err := c.storage.CleanSecrets(ctx)
+	deletedSecrets, err := c.storage.CleanSecrets(ctx, time.Now().Add(-c.config.Trash))
 	if err != nil {
-		c.log.Error().Msg("failed to clean deleted secrets")
-		return
+		c.log.Error().Err(err).Msg("failed to clean deleted secrets")
+	} else {
+		c.log.Info().Msgf("cleaned up %v deleted secrets", len(deletedSecrets))
 	}
 
-	c.log.Info().Msgf("cleaned up %v deleted secrets", len(deletedSecrets))
+	oldVersions, err := c.storage.PruneSecretVersions(ctx, c.config.Versions)
+	if err != nil {
+		c.log.Error().Err(err).Msg("failed to clean old secret versions")
+	} else {
+		c.log.Info().Msgf("cleaned up %v old secret versions", oldVersions)
+	}
 }
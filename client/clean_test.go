@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -18,12 +19,21 @@ func TestClean(t *testing.T) {
 	mockStorage.EXPECT().
 		CleanSecrets(
 			gomock.Any(),
+			gomock.Any(),
 		).
 		Times(2).
 		Return([]db.Secret{}, nil)
 
+	mockStorage.EXPECT().
+		PruneSecretVersions(
+			gomock.Any(),
+			int32(10),
+		).
+		Times(2).
+		Return(int64(0), nil)
+
 	client := &Client{
-		config:  Config{Clean: time.Second},
+		config:  Config{Clean: time.Second, Versions: 10},
 		storage: mockStorage,
 	}
 
@@ -32,3 +42,32 @@ func TestClean(t *testing.T) {
 
 	client.cleanJob(ctx)
 }
+
+func TestCleanFailure(t *testing.T) {
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockQuerier(controller)
+
+	mockStorage.EXPECT().
+		CleanSecrets(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(1).
+		Return(nil, sql.ErrConnDone)
+
+	// Failed trash cleanup doesn't skip the versions
+	mockStorage.EXPECT().
+		PruneSecretVersions(
+			gomock.Any(),
+			int32(10),
+		).
+		Times(1).
+		Return(int64(0), nil)
+
+	client := &Client{
+		config:  Config{Versions: 10},
+		storage: mockStorage,
+	}
+
+	client.clean(context.Background())
+}
@@ -2,56 +2,96 @@ package client
 
 import (
 	"context"
-	"database/sql"
+	"io"
 	"sync"
+	"sync/atomic"
+	"time"
 
-	_ "github.com/lib/pq"
 	"github.com/rs/zerolog"
 	"google.golang.org/grpc"
 	"google.golang.org/protobuf/types/known/emptypb"
 
 	"gophkeeper/certs"
 	"gophkeeper/db/db"
-	migrate "gophkeeper/db"
 	"gophkeeper/pb"
 	"gophkeeper/token"
 )
 
 type Client struct {
-	config    Config
-	storage   db.Querier
-	tm        token.PasetoMaker
-	g         pb.GophKeeperClient
-	log       zerolog.Logger
-	token     string
-	workGroup sync.WaitGroup
+	config       Config
+	storage      db.Querier
+	closer       io.Closer
+	tv           *token.PasetoVerifier
+	g            pb.GophKeeperClient
+	log          zerolog.Logger
+	token        string
+	refreshToken string
+	workGroup    sync.WaitGroup
+
+	// vaultKey is derived from the master password to encrypt the secrets
+	vaultKey []byte
+	// unboundValues is set until the vault made before the values were bound
+	// to their identity is migrated, the values of older formats are decrypted till then
+	unboundValues bool
+	// plainValues is set only while the vault is migrated: values stored unencrypted
+	// are not accepted otherwise with the encryption on. Values without the header that
+	// can't be decrypted are taken for the unencrypted ones only with headerlessPlain set.
+	plainValues     bool
+	headerlessPlain bool
+
+	// secondFactorRequired is set when login waits for the user to enter a TOTP code
+	secondFactorRequired atomic.Bool
+	codePrompt           func() (string, error)
+
+	// loginRetryAt is the time the server allows the next login attempt after throttling
+	loginRetryAt time.Time
+
+	// syncNotice is the last sync problem to be shown to the user
+	syncNotice atomic.Pointer[string]
 }
 
 func NewClient(cfg Config, logger zerolog.Logger) (*Client, error) {
-	pool, err := sql.Open("postgres", cfg.DSN)
+	tokenKeys, err := token.LoadPublicKeys(cfg.TokenKeys, cfg.TokenKey)
 	if err != nil {
 		return nil, err
 	}
 
-	err = pool.Ping()
+	// Token keys are fetched from the server later if they are not set in the config
+	var verifier *token.PasetoVerifier
+	if len(tokenKeys) > 0 {
+		v := token.NewPasetoVerifier(tokenKeys...)
+		verifier = &v
+	}
+
+	// Local database is unlocked with the master key
+	var masterKey []byte
+	if cfg.Encrypt {
+		masterKey = []byte(cfg.Key)
+	}
+
+	queries, storageCloser, err := openStorage(cfg.DSN, masterKey)
 	if err != nil {
 		return nil, err
 	}
 
-	err = migrate.RunDBMigration(cfg.DSN)
+	// Local secrets reference their owner
+	err = queries.CreateLocalUser(context.Background(), cfg.User)
 	if err != nil {
+		storageCloser.Close()
 		return nil, err
 	}
 
-	queries := db.New(pool)
+	removeLegacyTokens()
 
 	creds, err := certs.LoadClientCreds()
 	if err != nil {
+		storageCloser.Close()
 		return nil, err
 	}
 
 	conn, err := grpc.Dial(cfg.Address, grpc.WithTransportCredentials(creds))
 	if err != nil {
+		storageCloser.Close()
 		return nil, err
 	}
 
@@ -60,43 +100,73 @@ func NewClient(cfg Config, logger zerolog.Logger) (*Client, error) {
 	return &Client{
 		cfg,
 		queries,
-		token.NewPasetoMaker(),
+		storageCloser,
+		verifier,
 		client,
 		logger,
 		"",
+		"",
 		sync.WaitGroup{},
+		nil,
+		false,
+		false,
+		false,
+		atomic.Bool{},
+		nil,
+		time.Time{},
+		atomic.Pointer[string]{},
 	}, nil
 }
 
-func (c *Client) Run() {
+func (c *Client) Run() error {
 	c.log.Info().Msg("started gophkeeper client")
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	// Try to load token from cache
-	err := c.loadCachedToken(tokenCachedDir + tokenCachedFileName)
-	if err != nil {
-		c.log.Error().Err(err).Msg("failed to load token")
-	} else {
-		c.log.Info().Msg("successfully loaded cached token")
-	}
-
 	// Check server connection and run initial sync
-	_, err = c.g.Ping(ctx, &emptypb.Empty{})
+	_, err := c.g.Ping(ctx, &emptypb.Empty{})
 	if err != nil {
 		c.log.Warn().Msg("server unavailable...working offline")
 	} else {
+		err = c.loadTokenKeys(ctx)
+		if err != nil {
+			c.log.Error().Err(err).Msg("failed to load token keys")
+		}
+
+		// Try to load token from cache
+		err = c.loadCachedToken(ctx)
+		if err != nil {
+			c.log.Error().Err(err).Msg("failed to load token")
+		} else {
+			c.log.Info().Msg("successfully loaded cached token")
+		}
+
+		err = c.loadCachedRefreshToken(ctx)
+		if err != nil {
+			c.log.Error().Err(err).Msg("failed to load refresh token")
+		}
+
 		if c.token == "" {
 			c.login(ctx)
 		}
 
 		if c.token == "" {
 			c.log.Warn().Msg("not authorized...working offline")
-		} else {
-			c.sync(ctx)
 		}
 	}
 
+	// Secrets are never read or written with a wrong master password
+	err = c.unlock(ctx)
+	if err != nil {
+		cancel()
+		c.log.Error().Err(err).Msg("failed to unlock vault")
+		return err
+	}
+
+	if c.token != "" {
+		c.sync(ctx)
+	}
+
 	// Run periodic login job to refresh token
 	c.workGroup.Add(1)
 	go func() {
@@ -124,4 +194,11 @@ func (c *Client) Run() {
 
 	c.workGroup.Wait()
 	c.log.Info().Msg("successfully shut down")
+
+	return nil
+}
+
+// Close closes the local database. Encrypted database is saved on close.
+func (c *Client) Close() error {
+	return c.closer.Close()
 }
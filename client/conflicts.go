@@ -0,0 +1,261 @@
+package client
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"gophkeeper/db/db"
+	"gophkeeper/server/validation"
+)
+
+// SecretConflict is the secret changed both locally and on another device.
+type SecretConflict struct {
+	Mine   db.Secret
+	Theirs db.Secret
+}
+
+// saveConflict keeps the server version of the locally changed secret
+// until the user resolves the conflict. Local deletion just loses to the remote change.
+func (c *Client) saveConflict(ctx context.Context, localSecret, remoteSecret db.Secret) error {
+	if localSecret.Deleted {
+		return c.saveRemoteSecret(ctx, remoteSecret)
+	}
+
+	err := c.storage.SaveSecretConflict(
+		ctx,
+		db.SaveSecretConflictParams{
+			Owner:    remoteSecret.Owner,
+			Kind:     remoteSecret.Kind,
+			Name:     remoteSecret.Name,
+			Value:    remoteSecret.Value,
+			Created:  remoteSecret.Created,
+			Modified: remoteSecret.Modified,
+			Deleted:  remoteSecret.Deleted,
+			Revision: remoteSecret.Revision,
+			RemoteID: remoteSecret.RemoteID,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save user '%s' secret '%s' conflict: %w", remoteSecret.Owner, remoteSecret.Name, err)
+	}
+
+	c.log.Warn().Msgf("secret '%s' was changed on another device", remoteSecret.Name)
+	c.notify(fmt.Sprintf("Conflict on %s, open it to resolve", remoteSecret.Name))
+
+	return nil
+}
+
+// GetSecretConflicts returns the names of the conflicted secrets by kind.
+func (c *Client) GetSecretConflicts() (map[SecretKind]map[string]bool, error) {
+	conflicts, err := c.storage.GetSecretConflicts(context.Background(), c.config.User)
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to get user '%s' secret conflicts", c.config.User)
+		return nil, err
+	}
+
+	names := map[SecretKind]map[string]bool{}
+	for _, conflict := range conflicts {
+		kind := SecretKind(conflict.Kind)
+		if names[kind] == nil {
+			names[kind] = map[string]bool{}
+		}
+		names[kind][conflict.Name] = true
+	}
+
+	return names, nil
+}
+
+// GetSecretConflict returns both decrypted versions of the conflicted secret.
+func (c *Client) GetSecretConflict(kind SecretKind, name string) (SecretConflict, error) {
+	mine, theirs, err := c.loadConflict(context.Background(), kind, name)
+	if err != nil {
+		return SecretConflict{}, err
+	}
+
+	mine.Value, err = c.decrypt(mine.Value, mine.Kind, mine.Name)
+	if err != nil {
+		return SecretConflict{}, fmt.Errorf("failed to decrypt secret '%s' payload: %w", name, err)
+	}
+
+	// Deleted secrets have nothing to show
+	if !theirs.Deleted {
+		theirs.Value, err = c.decrypt(theirs.Value, theirs.Kind, theirs.Name)
+		if err != nil {
+			return SecretConflict{}, fmt.Errorf("failed to decrypt secret '%s' payload: %w", name, err)
+		}
+	}
+
+	return SecretConflict{Mine: mine, Theirs: theirs}, nil
+}
+
+// KeepMine resolves the conflict with the local version pushed over the server one.
+func (c *Client) KeepMine(kind SecretKind, name string) error {
+	ctx := context.Background()
+
+	_, theirs, err := c.loadConflict(ctx, kind, name)
+	if err != nil {
+		return err
+	}
+
+	err = c.storage.RebaseLocalSecret(
+		ctx,
+		db.RebaseLocalSecretParams{
+			Owner:    c.config.User,
+			Kind:     int32(kind),
+			Name:     name,
+			Revision: theirs.Revision,
+			RemoteID: theirs.RemoteID,
+		},
+	)
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to rebase user '%s' secret '%s'", c.config.User, name)
+		return err
+	}
+
+	return c.deleteConflict(ctx, kind, name)
+}
+
+// KeepTheirs resolves the conflict with the server version replacing the local one.
+func (c *Client) KeepTheirs(kind SecretKind, name string) error {
+	ctx := context.Background()
+
+	_, theirs, err := c.loadConflict(ctx, kind, name)
+	if err != nil {
+		return err
+	}
+
+	err = c.saveRemoteSecret(ctx, theirs)
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to save user '%s' secret '%s' server version", c.config.User, name)
+		return err
+	}
+
+	return c.deleteConflict(ctx, kind, name)
+}
+
+// KeepBoth resolves the conflict saving the local version under the new name
+// and the server version under the original one.
+func (c *Client) KeepBoth(kind SecretKind, name, newName string) (db.Secret, error) {
+	ctx := context.Background()
+
+	// The copy is rejected on push with the name the server doesn't accept
+	if err := validation.ValidateSecretName(newName); err != nil {
+		return db.Secret{}, fmt.Errorf("invalid secret name '%s': %w", newName, err)
+	}
+
+	mine, _, err := c.loadConflict(ctx, kind, name)
+	if err != nil {
+		return db.Secret{}, err
+	}
+
+	_, err = c.storage.GetSecret(
+		ctx,
+		db.GetSecretParams{
+			Owner: c.config.User,
+			Kind:  int32(kind),
+			Name:  newName,
+		},
+	)
+	if err == nil {
+		return db.Secret{}, fmt.Errorf("secret '%s' already exists", newName)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return db.Secret{}, err
+	}
+
+	// Encrypted value is bound to the secret name
+	value, err := c.reseal(mine.Value, int32(kind), name, newName)
+	if err != nil {
+		return db.Secret{}, fmt.Errorf("failed to encrypt secret '%s' payload: %w", newName, err)
+	}
+
+	remoteID, err := c.newRemoteID()
+	if err != nil {
+		return db.Secret{}, err
+	}
+
+	now := time.Now()
+	copied, err := c.storage.UpsertLocalSecret(
+		ctx,
+		db.UpsertLocalSecretParams{
+			Owner:    c.config.User,
+			Kind:     int32(kind),
+			Name:     newName,
+			Value:    value,
+			Created:  now,
+			Modified: now,
+			RemoteID: remoteID,
+		},
+	)
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to save user '%s' secret '%s' as '%s'", c.config.User, name, newName)
+		return db.Secret{}, err
+	}
+
+	return copied, c.KeepTheirs(kind, name)
+}
+
+// loadConflict returns both encrypted versions of the conflicted secret.
+func (c *Client) loadConflict(ctx context.Context, kind SecretKind, name string) (db.Secret, db.Secret, error) {
+	mine, err := c.storage.GetSecret(
+		ctx,
+		db.GetSecretParams{
+			Owner: c.config.User,
+			Kind:  int32(kind),
+			Name:  name,
+		},
+	)
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to get user '%s' secret '%s'", c.config.User, name)
+		return db.Secret{}, db.Secret{}, err
+	}
+
+	conflict, err := c.storage.GetSecretConflict(
+		ctx,
+		db.GetSecretConflictParams{
+			Owner: c.config.User,
+			Kind:  int32(kind),
+			Name:  name,
+		},
+	)
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to get user '%s' secret '%s' conflict", c.config.User, name)
+		return db.Secret{}, db.Secret{}, err
+	}
+
+	theirs := db.Secret{
+		Owner:    conflict.Owner,
+		Kind:     conflict.Kind,
+		Name:     conflict.Name,
+		Value:    conflict.Value,
+		Created:  conflict.Created,
+		Modified: conflict.Modified,
+		Deleted:  conflict.Deleted,
+		Revision: conflict.Revision,
+		RemoteID: conflict.RemoteID,
+	}
+
+	return mine, theirs, nil
+}
+
+func (c *Client) deleteConflict(ctx context.Context, kind SecretKind, name string) error {
+	err := c.storage.DeleteSecretConflict(
+		ctx,
+		db.DeleteSecretConflictParams{
+			Owner: c.config.User,
+			Kind:  int32(kind),
+			Name:  name,
+		},
+	)
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to delete user '%s' secret '%s' conflict", c.config.User, name)
+		return err
+	}
+
+	c.log.Info().Msgf("resolved user '%s' secret '%s' conflict", c.config.User, name)
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package client
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/crypto"
+	"gophkeeper/db/db"
+	"gophkeeper/db/mock"
+	"gophkeeper/random"
+	"gophkeeper/server/validation"
+)
+
+func newConflictStorage(t *testing.T, owner, name string) *mock.MockQuerier {
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockQuerier(controller)
+
+	mockStorage.EXPECT().
+		GetSecret(
+			gomock.Any(),
+			db.GetSecretParams{Owner: owner, Kind: int32(SecretText), Name: name},
+		).
+		AnyTimes().
+		Return(
+			db.Secret{Owner: owner, Kind: int32(SecretText), Name: name, Value: []byte("mine"), Revision: 1, Dirty: true},
+			nil,
+		)
+
+	mockStorage.EXPECT().
+		GetSecretConflict(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		Return(
+			db.SecretConflict{Owner: owner, Kind: int32(SecretText), Name: name, Value: []byte("theirs"), Revision: 5},
+			nil,
+		)
+
+	return mockStorage
+}
+
+func TestKeepMine(t *testing.T) {
+	testOwner := random.RandomOwner()
+	testName := random.RandomString(10)
+
+	mockStorage := newConflictStorage(t, testOwner, testName)
+
+	mockStorage.EXPECT().
+		RebaseLocalSecret(
+			gomock.Any(),
+			db.RebaseLocalSecretParams{
+				Owner:    testOwner,
+				Kind:     int32(SecretText),
+				Name:     testName,
+				Revision: 5,
+			},
+		).
+		Times(1).
+		Return(nil)
+
+	mockStorage.EXPECT().
+		DeleteSecretConflict(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(nil)
+
+	client := Client{
+		config:  Config{User: testOwner},
+		storage: mockStorage,
+	}
+
+	err := client.KeepMine(SecretText, testName)
+	require.NoError(t, err)
+}
+
+func TestKeepTheirs(t *testing.T) {
+	testOwner := random.RandomOwner()
+	testName := random.RandomString(10)
+
+	mockStorage := newConflictStorage(t, testOwner, testName)
+
+	mockStorage.EXPECT().
+		ReplaceLocalSecret(
+			gomock.Any(),
+			db.ReplaceLocalSecretParams{
+				Owner:    testOwner,
+				Kind:     int32(SecretText),
+				Name:     testName,
+				Value:    []byte("theirs"),
+				Revision: 5,
+			},
+		).
+		Times(1).
+		Return(nil)
+
+	mockStorage.EXPECT().
+		DeleteSecretConflict(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(nil)
+
+	client := Client{
+		config:  Config{User: testOwner},
+		storage: mockStorage,
+	}
+
+	err := client.KeepTheirs(SecretText, testName)
+	require.NoError(t, err)
+}
+
+func TestKeepBoth(t *testing.T) {
+	testOwner := random.RandomOwner()
+	testName := random.RandomString(10)
+	testNewName := random.RandomString(10)
+	testExistingName := random.RandomString(10)
+
+	mockStorage := newConflictStorage(t, testOwner, testName)
+
+	mockStorage.EXPECT().
+		GetSecret(
+			gomock.Any(),
+			db.GetSecretParams{Owner: testOwner, Kind: int32(SecretText), Name: testExistingName},
+		).
+		Times(1).
+		Return(db.Secret{}, nil)
+
+	mockStorage.EXPECT().
+		GetSecret(
+			gomock.Any(),
+			db.GetSecretParams{Owner: testOwner, Kind: int32(SecretText), Name: testNewName},
+		).
+		Times(1).
+		Return(db.Secret{}, sql.ErrNoRows)
+
+	mockStorage.EXPECT().
+		UpsertLocalSecret(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ interface{}, params db.UpsertLocalSecretParams) (db.Secret, error) {
+			require.Equal(t, testNewName, params.Name)
+			// Unencrypted copy gets the format header
+			require.Equal(t, crypto.Plain([]byte("mine")), params.Value)
+			return db.Secret{Name: params.Name, Value: params.Value}, nil
+		})
+
+	mockStorage.EXPECT().
+		ReplaceLocalSecret(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(nil)
+
+	mockStorage.EXPECT().
+		DeleteSecretConflict(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(nil)
+
+	client := Client{
+		config:  Config{User: testOwner},
+		storage: mockStorage,
+	}
+
+	// Existing secret is not overwritten
+	// The server doesn't sync secrets with empty names
+	_, err := client.KeepBoth(SecretText, testName, "")
+	require.ErrorIs(t, err, validation.ErrEmptySecretName)
+
+	_, err = client.KeepBoth(SecretText, testName, testExistingName)
+	require.Error(t, err)
+
+	copied, err := client.KeepBoth(SecretText, testName, testNewName)
+	require.NoError(t, err)
+	require.Equal(t, testNewName, copied.Name)
+}
+
+func TestHighlightChanges(t *testing.T) {
+	mine, theirs := highlightChanges(
+		[]string{"same", "mine", "only mine"},
+		[]string{"same", "theirs"},
+	)
+
+	require.Len(t, mine, 3)
+	require.Len(t, theirs, 2)
+	require.Equal(t, "same", mine[0])
+	require.Equal(t, "same", theirs[0])
+	require.Contains(t, mine[1], "mine")
+	require.Contains(t, mine[2], "only mine")
+	require.Contains(t, theirs[1], "theirs")
+}
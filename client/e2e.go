@@ -0,0 +1,287 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gophkeeper/converter"
+	"gophkeeper/crypto"
+	"gophkeeper/db/db"
+	"gophkeeper/pb"
+)
+
+// sealedKind is the server kind of the end-to-end encrypted secrets. Their kind and name
+// are sealed into the value, the server only knows them by the opaque ID used as the name.
+const sealedKind int32 = -1
+
+const remoteIDLength = 16 // bytes
+
+var errSealedSecret = errors.New("secret is encrypted end-to-end, enable encryption to sync it")
+
+// sealedSecret is the plaintext of the end-to-end encrypted secret value.
+// Secret metadata like tags and notes belongs here so the server never sees it.
+type sealedSecret struct {
+	Kind int32  `json:"kind"`
+	Name string `json:"name"`
+	// Value is sealed with the vault key for the secret kind and name
+	Value []byte `json:"value"`
+}
+
+// newRemoteID returns the server identity of a new secret.
+// It is empty unless the secrets are encrypted end-to-end.
+func (c *Client) newRemoteID() (string, error) {
+	if !c.config.E2E {
+		return "", nil
+	}
+
+	id := make([]byte, remoteIDLength)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate secret id: %w", err)
+	}
+
+	return hex.EncodeToString(id), nil
+}
+
+// toRemote returns the local secret as it is sent to the server.
+func (c *Client) toRemote(secret db.Secret) (*pb.Secret, error) {
+	return c.sealRemote(secret, c.vaultKey)
+}
+
+// sealRemote seals the kind and the name of the end-to-end encrypted secret along with
+// its value with the key. Secrets without the remote ID are sent as they are.
+func (c *Client) sealRemote(secret db.Secret, key []byte) (*pb.Secret, error) {
+	pbSecret := converter.DBSecretToPBSecret(secret)
+	if secret.RemoteID == "" {
+		return pbSecret, nil
+	}
+
+	pbSecret.Kind = sealedKind
+	pbSecret.Name = secret.RemoteID
+
+	// Purged secret has nothing to seal
+	if isPurged(secret) {
+		return pbSecret, nil
+	}
+
+	plaintext, err := json.Marshal(sealedSecret{Kind: secret.Kind, Name: secret.Name, Value: secret.Value})
+	if err != nil {
+		return nil, err
+	}
+
+	pbSecret.Value, err = crypto.Seal(plaintext, key, c.secretAD(sealedKind, secret.RemoteID))
+	if err != nil {
+		return nil, err
+	}
+
+	return pbSecret, nil
+}
+
+// fromRemote returns the local copy of the server secret.
+// End-to-end encrypted secrets get their kind and name back.
+func (c *Client) fromRemote(pbSecret *pb.Secret) (db.Secret, error) {
+	secret := converter.PBSecretToDBSecret(pbSecret)
+	if secret.Kind != sealedKind {
+		return secret, nil
+	}
+
+	if !c.config.Encrypt {
+		return db.Secret{}, errSealedSecret
+	}
+
+	secret.RemoteID = pbSecret.Name
+
+	// Purged secret is only known by its ID
+	if isPurged(secret) {
+		local, err := c.storage.GetSecretByRemoteID(
+			context.Background(),
+			db.GetSecretByRemoteIDParams{
+				Owner:    secret.Owner,
+				RemoteID: secret.RemoteID,
+			},
+		)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return db.Secret{}, err
+		}
+		if err == nil {
+			secret.Kind = local.Kind
+			secret.Name = local.Name
+		}
+
+		return secret, nil
+	}
+
+	sealed, err := c.openRemote(secret.Value, pbSecret.Name)
+	if err != nil {
+		return db.Secret{}, fmt.Errorf("failed to open secret '%s': %w", pbSecret.Name, err)
+	}
+
+	secret.Kind = sealed.Kind
+	secret.Name = sealed.Name
+	secret.Value = sealed.Value
+
+	return secret, nil
+}
+
+// openRemote decrypts the end-to-end encrypted secret value.
+func (c *Client) openRemote(value []byte, remoteID string) (sealedSecret, error) {
+	plaintext, err := crypto.Open(value, c.vaultKey, c.secretAD(sealedKind, remoteID))
+	if err != nil {
+		return sealedSecret{}, err
+	}
+
+	var sealed sealedSecret
+	err = json.Unmarshal(plaintext, &sealed)
+
+	return sealed, err
+}
+
+// rewrapRemote seals the end-to-end encrypted secret value with the new vault key.
+func (c *Client) rewrapRemote(value []byte, remoteID string, newKey []byte) ([]byte, error) {
+	sealed, err := c.openRemote(value, remoteID)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed.Value, err = c.rewrap(sealed.Value, sealed.Kind, sealed.Name, newKey)
+	if err != nil {
+		return nil, err
+	}
+
+	pbSecret, err := c.sealRemote(
+		db.Secret{Kind: sealed.Kind, Name: sealed.Name, Value: sealed.Value, RemoteID: remoteID},
+		newKey,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return pbSecret.Value, nil
+}
+
+// remoteIdentity returns the server kind and name of the local secret.
+func remoteIdentity(secret db.Secret) (int32, string) {
+	if secret.RemoteID != "" {
+		return sealedKind, secret.RemoteID
+	}
+
+	return secret.Kind, secret.Name
+}
+
+// getRemoteIdentity returns the server kind and name of the local secret by its local ones.
+func (c *Client) getRemoteIdentity(ctx context.Context, kind SecretKind, name string) (int32, string, error) {
+	secret, err := c.storage.GetSecret(
+		ctx,
+		db.GetSecretParams{
+			Owner: c.config.User,
+			Kind:  int32(kind),
+			Name:  name,
+		},
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return int32(kind), name, nil
+	}
+	if err != nil {
+		return 0, "", err
+	}
+
+	remoteKind, remoteName := remoteIdentity(secret)
+
+	return remoteKind, remoteName, nil
+}
+
+// sealNames moves the secrets synced by their plaintext names to the opaque server IDs.
+// The server creates the sealed copies and removes the plaintext ones with their versions
+// in a single transaction, no tombstones with the names are left. A plaintext secret
+// is only removed once its sealed copy is created, the rest keep their names and are
+// sealed on the next run. The other devices replace their local secrets with the sealed
+// copies they pull.
+func (c *Client) sealNames(ctx context.Context) error {
+	secrets, err := c.storage.GetSecretsByUser(ctx, c.config.User)
+	if err != nil {
+		return err
+	}
+
+	var plain []db.Secret
+	for _, secret := range secrets {
+		// Trashed secrets are left to expire and never pushed ones get their IDs on push
+		if secret.RemoteID != "" || secret.Revision == 0 || secret.Deleted {
+			continue
+		}
+
+		plain = append(plain, secret)
+	}
+
+	if len(plain) == 0 {
+		return nil
+	}
+
+	batch := make([]*pb.SealedSecret, 0, len(plain))
+	sealed := make([]db.Secret, len(plain))
+	for i, secret := range plain {
+		sealed[i] = secret
+		sealed[i].Revision = 0
+		sealed[i].RemoteID, err = c.newRemoteID()
+		if err != nil {
+			return err
+		}
+
+		pbSecret, err := c.toRemote(sealed[i])
+		if err != nil {
+			return fmt.Errorf("failed to seal secret '%s': %w", secret.Name, err)
+		}
+
+		batch = append(
+			batch,
+			&pb.SealedSecret{
+				Plain:  &pb.SecretRequest{Kind: secret.Kind, Name: secret.Name},
+				Sealed: pbSecret,
+			},
+		)
+	}
+
+	result, err := c.g.SealSecrets(c.authContext(ctx), &pb.SealSecretsRequest{Secrets: batch})
+	if err != nil {
+		return err
+	}
+	if len(result.Results) != len(batch) {
+		return fmt.Errorf("got %v results for %v secrets", len(result.Results), len(batch))
+	}
+
+	var done int
+	for i, secret := range sealed {
+		created := result.Results[i]
+		if created.Outcome != pb.SecretResult_CREATED {
+			c.log.Warn().Msgf("secret '%s' was not sealed: %s", secret.Name, created.Outcome)
+			continue
+		}
+
+		err = c.storage.SetLocalSecretRemoteID(
+			ctx,
+			db.SetLocalSecretRemoteIDParams{
+				Owner:    secret.Owner,
+				Kind:     secret.Kind,
+				Name:     secret.Name,
+				RemoteID: secret.RemoteID,
+				Revision: created.Revision,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save secret '%s' id: %w", secret.Name, err)
+		}
+
+		done++
+	}
+
+	c.log.Info().Msgf("sealed names of %v of %v user '%s' secrets", done, len(plain), c.config.User)
+
+	if done < len(plain) {
+		return fmt.Errorf("%v secrets were not sealed, run the migration again", len(plain)-done)
+	}
+
+	return nil
+}
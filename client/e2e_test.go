@@ -0,0 +1,197 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"gophkeeper/db/db"
+	"gophkeeper/pb"
+	"gophkeeper/random"
+)
+
+func TestSealRemote(t *testing.T) {
+	client := Client{
+		config:   Config{User: random.RandomOwner(), Encrypt: true, Key: random.RandomString(12), E2E: true},
+		vaultKey: []byte(random.RandomString(32)),
+	}
+
+	remoteID, err := client.newRemoteID()
+	require.NoError(t, err)
+	require.Len(t, remoteID, 2*remoteIDLength)
+
+	value, err := client.encrypt([]byte("value"), int32(SecretCreds), "bank")
+	require.NoError(t, err)
+
+	secret := db.Secret{
+		Owner:    client.config.User,
+		Kind:     int32(SecretCreds),
+		Name:     "bank",
+		Value:    value,
+		Created:  time.Now().UTC(),
+		Modified: time.Now().UTC(),
+		Revision: 3,
+		RemoteID: remoteID,
+	}
+
+	// The server only gets the opaque ID
+	pbSecret, err := client.toRemote(secret)
+	require.NoError(t, err)
+	require.Equal(t, sealedKind, pbSecret.Kind)
+	require.Equal(t, remoteID, pbSecret.Name)
+	require.NotContains(t, string(pbSecret.Value), "bank")
+	require.Equal(t, secret.Revision, pbSecret.Revision)
+
+	remoteSecret, err := client.fromRemote(pbSecret)
+	require.NoError(t, err)
+	require.Equal(t, secret.Kind, remoteSecret.Kind)
+	require.Equal(t, secret.Name, remoteSecret.Name)
+	require.Equal(t, secret.Value, remoteSecret.Value)
+	require.Equal(t, remoteID, remoteSecret.RemoteID)
+
+	// Sealed value can't be moved to another ID
+	pbSecret.Name = random.RandomString(32)
+	_, err = client.fromRemote(pbSecret)
+	require.Error(t, err)
+
+	// Purged secret is pushed by its ID only and pulled back by it
+	client.storage = newTestStorage(t, client.config.User)
+	err = client.storage.ReplaceLocalSecret(
+		context.Background(),
+		db.ReplaceLocalSecretParams{
+			Owner:    secret.Owner,
+			Kind:     secret.Kind,
+			Name:     secret.Name,
+			Value:    secret.Value,
+			Created:  secret.Created,
+			Modified: secret.Modified,
+			Revision: secret.Revision,
+			RemoteID: remoteID,
+		},
+	)
+	require.NoError(t, err)
+
+	purged := secret
+	purged.Value = []byte{}
+	purged.Deleted = true
+
+	pbSecret, err = client.toRemote(purged)
+	require.NoError(t, err)
+	require.Equal(t, sealedKind, pbSecret.Kind)
+	require.Equal(t, remoteID, pbSecret.Name)
+	require.Empty(t, pbSecret.Value)
+
+	remoteSecret, err = client.fromRemote(pbSecret)
+	require.NoError(t, err)
+	require.Equal(t, secret.Kind, remoteSecret.Kind)
+	require.Equal(t, secret.Name, remoteSecret.Name)
+	require.True(t, isPurged(remoteSecret))
+
+	// Secrets synced before keep their plaintext names
+	secret.RemoteID = ""
+
+	pbSecret, err = client.toRemote(secret)
+	require.NoError(t, err)
+	require.Equal(t, secret.Kind, pbSecret.Kind)
+	require.Equal(t, secret.Name, pbSecret.Name)
+	require.Equal(t, secret.Value, pbSecret.Value)
+
+	// Sealed secrets are never opened without the vault key
+	client.config.Encrypt = false
+
+	_, err = client.fromRemote(&pb.Secret{Kind: sealedKind, Name: remoteID})
+	require.ErrorIs(t, err, errSealedSecret)
+}
+
+// sealSecretsServer creates every sealed secret but the ones with the conflicting IDs.
+type sealSecretsServer struct {
+	pb.GophKeeperClient
+	sealed   []*pb.SealedSecret
+	conflict bool
+}
+
+func (s *sealSecretsServer) SealSecrets(_ context.Context, in *pb.SealSecretsRequest, _ ...grpc.CallOption) (*pb.SyncResult, error) {
+	s.sealed = in.Secrets
+
+	results := []*pb.SecretResult{}
+	for i, secret := range in.Secrets {
+		outcome := pb.SecretResult_CREATED
+		if s.conflict {
+			outcome = pb.SecretResult_CONFLICT
+		}
+
+		results = append(
+			results,
+			&pb.SecretResult{Kind: secret.Sealed.Kind, Name: secret.Sealed.Name, Outcome: outcome, Revision: int64(10 + i)},
+		)
+	}
+
+	return &pb.SyncResult{Results: results}, nil
+}
+
+func TestSealNames(t *testing.T) {
+	testOwner := random.RandomOwner()
+	storage := newTestStorage(t, testOwner)
+	server := &sealSecretsServer{conflict: true}
+
+	client := Client{
+		config:   Config{User: testOwner, Encrypt: true, Key: random.RandomString(12), E2E: true},
+		storage:  storage,
+		log:      zerolog.Nop(),
+		g:        server,
+		vaultKey: []byte(random.RandomString(32)),
+	}
+
+	ctx := context.Background()
+
+	err := storage.ReplaceLocalSecret(
+		ctx,
+		db.ReplaceLocalSecretParams{Owner: testOwner, Kind: 1, Name: "synced", Value: []byte("value"), Revision: 3},
+	)
+	require.NoError(t, err)
+
+	_, err = client.SetSecret(SecretText, "new", []byte("value"))
+	require.NoError(t, err)
+
+	// Secrets without the sealed copies keep their plaintext names
+	err = client.sealNames(ctx)
+	require.Error(t, err)
+
+	unsealed, err := storage.GetSecret(ctx, db.GetSecretParams{Owner: testOwner, Kind: 1, Name: "synced"})
+	require.NoError(t, err)
+	require.Empty(t, unsealed.RemoteID)
+	require.Equal(t, int64(3), unsealed.Revision)
+
+	server.conflict = false
+
+	err = client.sealNames(ctx)
+	require.NoError(t, err)
+
+	// The plaintext copy is replaced with the sealed one in a single call
+	require.Len(t, server.sealed, 1)
+	require.Equal(t, int32(1), server.sealed[0].Plain.Kind)
+	require.Equal(t, "synced", server.sealed[0].Plain.Name)
+	require.Equal(t, sealedKind, server.sealed[0].Sealed.Kind)
+	require.False(t, server.sealed[0].Sealed.Deleted)
+	require.NotContains(t, string(server.sealed[0].Sealed.Value), "synced")
+
+	synced, err := storage.GetSecret(ctx, db.GetSecretParams{Owner: testOwner, Kind: 1, Name: "synced"})
+	require.NoError(t, err)
+	require.Equal(t, server.sealed[0].Sealed.Name, synced.RemoteID)
+	require.Equal(t, int64(10), synced.Revision)
+	require.False(t, synced.Dirty)
+
+	// Never pushed secrets got their IDs when they were saved
+	created, err := storage.GetSecret(ctx, db.GetSecretParams{Owner: testOwner, Kind: 1, Name: "new"})
+	require.NoError(t, err)
+	require.NotEmpty(t, created.RemoteID)
+
+	// Sealed secrets are found by their IDs
+	found, err := storage.GetSecretByRemoteID(ctx, db.GetSecretByRemoteIDParams{Owner: testOwner, RemoteID: synced.RemoteID})
+	require.NoError(t, err)
+	require.Equal(t, "synced", found.Name)
+}
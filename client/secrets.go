@@ -8,7 +8,6 @@ import (
 	"os"
 	"time"
 
-	"gophkeeper/crypto"
 	"gophkeeper/db/db"
 )
 
@@ -53,75 +52,68 @@ func (c *Client) GetSecret(kind SecretKind, name string) (db.Secret, error) {
 		return db.Secret{}, err
 	}
 
-	if c.config.Encrypt {
-		decryptedPayload, err := crypto.Decrypt(dbSecret.Value, []byte(c.config.Key))
-		if err != nil {
-			c.log.Error().Err(err).Msgf("failed to decrypt secret '%s' payload", dbSecret.Name)
-			return db.Secret{}, fmt.Errorf("failed to decrypt secret '%s' payload: %w", dbSecret.Name, err)
-		}
-
-		dbSecret.Value = decryptedPayload
-		return dbSecret, nil
+	// Every value is decrypted by its own format, unencrypted ones included
+	decryptedPayload, err := c.decrypt(dbSecret.Value, dbSecret.Kind, dbSecret.Name)
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to decrypt secret '%s' payload", dbSecret.Name)
+		return db.Secret{}, fmt.Errorf("failed to decrypt secret '%s' payload: %w", dbSecret.Name, err)
 	}
 
+	dbSecret.Value = decryptedPayload
 	return dbSecret, nil
 }
 
 func (c *Client) SetSecret(kind SecretKind, name string, payload []byte) (db.Secret, error) {
-	localSecret, err := c.storage.GetSecret(
+	remoteID, err := c.newRemoteID()
+	if err != nil {
+		return db.Secret{}, err
+	}
+
+	now := time.Now()
+
+	// Created and the server ID are only set for the new secret, existing ones keep theirs
+	secret, err := c.storage.UpsertLocalSecret(
 		context.Background(),
-		db.GetSecretParams{
-			Owner: c.config.User,
-			Kind:  int32(kind),
-			Name:  name,
+		db.UpsertLocalSecretParams{
+			Owner:    c.config.User,
+			Kind:     int32(kind),
+			Name:     name,
+			Value:    payload,
+			Created:  now,
+			Modified: now,
+			RemoteID: remoteID,
 		},
 	)
-	if errors.Is(err, sql.ErrNoRows) {
-		newSecret, err := c.storage.CreateSecret(
-			context.Background(),
-			db.CreateSecretParams{
-				Owner: c.config.User,
-				Kind:  int32(kind),
-				Name:  name,
-				Value: payload,
-				Created: time.Now(),
-				Modified: time.Now(),
-			},
-		)
-		if err != nil {
-			c.log.Error().Err(err).Msgf("failed to save user '%s' new secret '%s'", c.config.User, name)
-			return db.Secret{}, err
-		}
-
-		c.log.Info().Msgf("successfully created user '%s' new secret '%s'", c.config.User, name)
-		return newSecret, nil
-	}
 	if err != nil {
-		c.log.Error().Err(err).Msgf("failed got user '%s' local secret '%s'", c.config.User, name)
+		c.log.Error().Err(err).Msgf("failed to save user '%s' secret '%s'", c.config.User, name)
 		return db.Secret{}, err
 	}
 
-	updateSecret, err := c.storage.UpdateSecret(
+	c.log.Info().Msgf("successfully saved user '%s' secret '%s'", c.config.User, name)
+
+	return secret, nil
+}
+
+func (c *Client) DeleteSecret(kind SecretKind, name string) error {
+	// Deletion of the conflicted secret wins over the server version
+	_, err := c.storage.GetSecretConflict(
 		context.Background(),
-		db.UpdateSecretParams{
+		db.GetSecretConflictParams{
 			Owner: c.config.User,
 			Kind:  int32(kind),
 			Name:  name,
-			Value: payload,
-			Created: localSecret.Created,
-			Modified: time.Now(),
 		},
 	)
+	if err == nil {
+		err = c.KeepMine(kind, name)
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
 
-	c.log.Info().Msgf("successfully updated user '%s' secret '%s'", c.config.User, name)
-
-	return updateSecret, nil
-}
-
-func (c *Client) DeleteSecret(kind SecretKind, name string) error {
-	return c.storage.MarkSecretDeleted(
+	return c.storage.MarkLocalSecretDeleted(
 		context.Background(),
-		db.MarkSecretDeletedParams{
+		db.MarkLocalSecretDeletedParams{
 			Owner: c.config.User,
 			Kind:  int32(kind),
 			Name:  name,
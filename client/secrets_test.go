@@ -46,7 +46,7 @@ func TestGetSecret(t *testing.T) {
 	require.Equal(t, secret.Value, expectedSecret.Value)
 }
 
-func TestSetNewSecret(t *testing.T) {
+func TestSetSecret(t *testing.T) {
 	controller := gomock.NewController(t)
 	mockStorage := mock.NewMockQuerier(controller)
 
@@ -57,22 +57,11 @@ func TestSetNewSecret(t *testing.T) {
 		Kind:  random.RandomSecretKind(),
 		Name:  random.RandomString(10),
 		Value: []byte(random.RandomString(100)),
+		Dirty: true,
 	}
 
 	mockStorage.EXPECT().
-		GetSecret(
-			gomock.Any(),
-			db.GetSecretParams{
-				Owner: newSecret.Owner,
-				Kind:  newSecret.Kind,
-				Name:  newSecret.Name,
-			},
-		).
-		Times(1).
-		Return(db.Secret{}, sql.ErrNoRows)
-
-	mockStorage.EXPECT().
-		CreateSecret(
+		UpsertLocalSecret(
 			gomock.Any(),
 			gomock.Any(),
 		).
@@ -89,47 +78,25 @@ func TestSetNewSecret(t *testing.T) {
 	require.Equal(t, secret.Value, newSecret.Value)
 }
 
-func TestSetExistingSecret(t *testing.T) {
+func TestSetSecretFailed(t *testing.T) {
 	controller := gomock.NewController(t)
 	mockStorage := mock.NewMockQuerier(controller)
 
-	testOwner := random.RandomOwner()
-
-	existingSecret := db.Secret{
-		Owner: testOwner,
-		Kind:  random.RandomSecretKind(),
-		Name:  random.RandomString(10),
-		Value: []byte(random.RandomString(100)),
-	}
-
 	mockStorage.EXPECT().
-		GetSecret(
-			gomock.Any(),
-			db.GetSecretParams{
-				Owner: existingSecret.Owner,
-				Kind:  existingSecret.Kind,
-				Name:  existingSecret.Name,
-			},
-		).
-		Times(1).
-		Return(existingSecret, nil)
-
-	mockStorage.EXPECT().
-		UpdateSecret(
+		UpsertLocalSecret(
 			gomock.Any(),
 			gomock.Any(),
 		).
 		Times(1).
-		Return(existingSecret, nil)
+		Return(db.Secret{}, sql.ErrConnDone)
 
 	client := Client{
-		config:  Config{User: testOwner},
+		config:  Config{User: random.RandomOwner()},
 		storage: mockStorage,
 	}
 
-	secret, err := client.SetSecret(SecretKind(existingSecret.Kind), existingSecret.Name, existingSecret.Value)
-	require.NoError(t, err)
-	require.Equal(t, secret.Value, existingSecret.Value)
+	_, err := client.SetSecret(SecretCreds, random.RandomString(10), []byte(random.RandomString(100)))
+	require.Error(t, err)
 }
 
 func TestDeleteSecret(t *testing.T) {
@@ -137,7 +104,15 @@ func TestDeleteSecret(t *testing.T) {
 	mockStorage := mock.NewMockQuerier(controller)
 
 	mockStorage.EXPECT().
-		MarkSecretDeleted(
+		GetSecretConflict(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(1).
+		Return(db.SecretConflict{}, sql.ErrNoRows)
+
+	mockStorage.EXPECT().
+		MarkLocalSecretDeleted(
 			gomock.Any(),
 			gomock.Any(),
 		).
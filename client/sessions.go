@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"gophkeeper/pb"
+)
+
+func (c *Client) ListSessions(ctx context.Context) ([]*pb.Session, error) {
+	pbSessions, err := c.g.ListSessions(c.authContext(ctx), &emptypb.Empty{})
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to list user '%s' sessions", c.config.User)
+		return nil, err
+	}
+
+	return pbSessions.Sessions, nil
+}
+
+func (c *Client) RevokeSession(ctx context.Context, id string) error {
+	_, err := c.g.RevokeSession(c.authContext(ctx), &pb.SessionRequest{Id: id})
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to revoke user '%s' session", c.config.User)
+		return err
+	}
+
+	c.log.Info().Msgf("successfully revoked user '%s' session", c.config.User)
+
+	return nil
+}
+
+// RevokeAllSessions revokes all the user sessions except the current one.
+func (c *Client) RevokeAllSessions(ctx context.Context) error {
+	_, err := c.g.RevokeAllSessions(c.authContext(ctx), &emptypb.Empty{})
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to revoke user '%s' sessions", c.config.User)
+		return err
+	}
+
+	c.log.Info().Msgf("successfully revoked user '%s' other sessions", c.config.User)
+
+	return nil
+}
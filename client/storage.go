@@ -0,0 +1,59 @@
+package client
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	_ "github.com/lib/pq"
+
+	migrate "gophkeeper/db"
+	"gophkeeper/db/db"
+	"gophkeeper/db/sqlite"
+)
+
+// isPostgresDSN reports whether the local database is kept in PostgreSQL.
+func isPostgresDSN(dsn string) bool {
+	return strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://")
+}
+
+// openStorage opens the local database. PostgreSQL URLs are used as they are,
+// any other DSN is a path to the embedded SQLite database file encrypted with the master key.
+// PostgreSQL is only used with the encryption off.
+func openStorage(dsn string, masterKey []byte) (db.Querier, io.Closer, error) {
+	if !isPostgresDSN(dsn) {
+		conn, err := sqlite.Open(dsn, masterKey)
+		if errors.Is(err, sqlite.ErrWrongKey) {
+			// Devices not synced before the rekey keep the local database encrypted with the replaced key
+			return nil, nil, fmt.Errorf(
+				"%w: if the master password was changed on another device remove %s to pull the secrets again",
+				err,
+				dsn,
+			)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return sqlite.NewQuerier(conn), conn, nil
+	}
+
+	pool, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = pool.Ping()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = migrate.RunDBMigration(dsn)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return db.New(pool), pool, nil
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/db/db"
+	"gophkeeper/random"
+)
+
+// newTestStorage opens a plain local database with the user created.
+func newTestStorage(t *testing.T, owner string) db.Querier {
+	storage, closer, err := openStorage(filepath.Join(t.TempDir(), "gophkeeper.db"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { closer.Close() })
+
+	err = storage.CreateLocalUser(context.Background(), owner)
+	require.NoError(t, err)
+
+	return storage
+}
+
+func TestOpenStorage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gophkeeper.db")
+	owner := random.RandomOwner()
+	masterKey := []byte(random.RandomString(32))
+
+	storage, closer, err := openStorage(path, masterKey)
+	require.NoError(t, err)
+
+	err = storage.CreateLocalUser(context.Background(), owner)
+	require.NoError(t, err)
+
+	err = storage.SaveCachedTokens(
+		context.Background(),
+		db.SaveCachedTokensParams{Owner: owner, Token: "token", RefreshToken: "refresh"},
+	)
+	require.NoError(t, err)
+	require.NoError(t, closer.Close())
+
+	// Encrypted database is never opened without the key
+	_, _, err = openStorage(path, nil)
+	require.Error(t, err)
+
+	storage, closer, err = openStorage(path, masterKey)
+	require.NoError(t, err)
+	defer closer.Close()
+
+	tokens, err := storage.GetCachedTokens(context.Background(), owner)
+	require.NoError(t, err)
+	require.Equal(t, "refresh", tokens.RefreshToken)
+}
@@ -4,12 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
-	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/types/known/emptypb"
 
-	"gophkeeper/converter"
 	"gophkeeper/db/db"
 	"gophkeeper/pb"
 )
@@ -46,146 +46,411 @@ func (c *Client) sync(ctx context.Context) {
 	c.log.Info().Msg("secrets sync started...")
 
 	// Provide token
-	md := metadata.Pairs("token", c.token)
-	ctx = metadata.NewOutgoingContext(ctx, md)
+	ctx = c.authContext(ctx)
 
-	// Pull remote
-	remotePBSecrets, err := c.g.GetSecrets(ctx, &pb.SecretsRequest{
-		Owner: c.config.User,
-	})
+	err := c.pull(ctx)
 	if err != nil {
 		c.log.Error().Err(err).Msgf("failed to pull user '%s' remote secrets", c.config.User)
 		return
 	}
 
-	c.log.Info().Msgf("sync got %v secrets", len(remotePBSecrets.Secrets))
+	err = c.push(ctx)
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to push user '%s' local secrets", c.config.User)
+		return
+	}
+
+	c.log.Info().Msg("secrets sync finished")
+}
+
+// errResync is returned by the server changes when the tombstones
+// of the secrets deleted since the last pull are already purged.
+var errResync = errors.New("sync revision is older than the server tombstones")
+
+// secretKey identifies the secret of the user.
+type secretKey struct {
+	kind int32
+	name string
+}
 
-	for _, pbSecret := range remotePBSecrets.Secrets {
-		remoteSecret := converter.PBSecretToDBSecret(pbSecret)
+// pull applies the remote secret changes made after the last pulled revision.
+// The first sync of the local database pulls all the secrets and pushes all the local ones.
+func (c *Client) pull(ctx context.Context) error {
+	cursor, err := c.storage.GetSyncCursor(ctx, c.config.User)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = c.storage.MarkSecretsDirty(ctx, c.config.User)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = c.pullChanges(ctx, cursor, nil)
+	if errors.Is(err, errResync) {
+		c.log.Warn().Msgf("user '%s' was offline longer than the server keeps deleted secrets, resyncing", c.config.User)
+		return c.resync(ctx)
+	}
 
-		localSecret, err := c.storage.GetSecret(
+	return err
+}
+
+// pullChanges applies the remote changes after the revision page by page and moves the cursor.
+// If seen is not nil the remote secrets are collected into it and the full resync is requested.
+func (c *Client) pullChanges(ctx context.Context, cursor int64, seen map[secretKey]bool) error {
+	for {
+		changes, err := c.g.GetChanges(
 			ctx,
-			db.GetSecretParams{
-				Owner: remoteSecret.Owner,
-				Kind:  remoteSecret.Kind,
-				Name:  remoteSecret.Name,
+			&pb.ChangesRequest{
+				SinceRevision: cursor,
+				Resync:        seen != nil,
 			},
 		)
-		if err != nil && !errors.Is(err, sql.ErrNoRows) {
-			c.log.Error().Err(err).Msgf(
-				"failed to get user '%s' secret '%s' from local db",
-				remoteSecret.Owner,
-				remoteSecret.Name,
-			)
-			continue
+		if err != nil {
+			return err
 		}
-		if errors.Is(err, sql.ErrNoRows) && !remoteSecret.Deleted {
-			_, err := c.storage.CreateSecret(
-				ctx,
-				db.CreateSecretParams{
-					Owner:    remoteSecret.Owner,
-					Kind:     remoteSecret.Kind,
-					Name:     remoteSecret.Name,
-					Value:    remoteSecret.Value,
-					Created:  remoteSecret.Created,
-					Modified: remoteSecret.Modified,
-				},
-			)
-			if err != nil {
-				c.log.Error().Err(err).Msgf(
-					"failed to sync new user '%s' secret '%s'",
-					remoteSecret.Owner,
-					remoteSecret.Name,
-				)
-				continue
-			}
 
-			c.log.Info().Msgf(
-				"successfully synced new user '%s' secret '%s'",
-				remoteSecret.Owner,
-				remoteSecret.Name,
-			)
-			continue
+		if changes.Resync {
+			return errResync
 		}
 
-		if remoteSecret.Deleted {
-			err := c.storage.DeleteSecret(
-				ctx,
-				db.DeleteSecretParams{
-					Owner: remoteSecret.Owner,
-					Kind:  remoteSecret.Kind,
-					Name:  remoteSecret.Name,
-				},
-			)
+		c.log.Info().Msgf("sync got %v changed secrets", len(changes.Secrets))
+
+		for _, pbSecret := range changes.Secrets {
+			// The cursor is not moved past the secret failed to apply
+			remoteSecret, err := c.fromRemote(pbSecret)
 			if err != nil {
-				c.log.Error().Err(err).Msgf(
-					"failed to delete user '%s' secret '%s'",
-					remoteSecret.Owner,
-					remoteSecret.Name,
-				)
-				continue
+				return err
 			}
 
-			c.log.Info().Msgf(
-				"successfully deleted user '%s' secret '%s'",
-				remoteSecret.Owner,
-				remoteSecret.Name,
-			)
-			continue
-		}
+			if seen != nil {
+				seen[secretKey{kind: remoteSecret.Kind, name: remoteSecret.Name}] = true
+			}
 
-		if remoteSecret.Modified.After(localSecret.Modified) {
-			_, err := c.storage.UpdateSecret(
-				ctx,
-				db.UpdateSecretParams{
-					Owner:    remoteSecret.Owner,
-					Kind:     remoteSecret.Kind,
-					Name:     remoteSecret.Name,
-					Value:    remoteSecret.Value,
-					Created:  remoteSecret.Created,
-					Modified: remoteSecret.Modified,
-				},
-			)
+			err = c.applyRemoteSecret(ctx, remoteSecret)
 			if err != nil {
-				c.log.Error().Err(err).Msgf(
-					"failed to update user '%s' secret '%s'",
-					remoteSecret.Owner,
-					remoteSecret.Name,
-				)
-				continue
+				return err
 			}
+		}
 
-			c.log.Info().Msgf(
-				"successfully synced update of user '%s' secret '%s'",
-				remoteSecret.Owner,
-				remoteSecret.Name,
-			)
+		cursor = changes.Revision
+		err = c.storage.SetSyncCursor(
+			ctx,
+			db.SetSyncCursorParams{
+				Owner:    c.config.User,
+				Revision: cursor,
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		if !changes.More {
+			return nil
 		}
 	}
+}
+
+// resync pulls all the remote secrets. Local secrets synced before but missing on the server
+// were deleted while the client was offline. Unchanged ones are moved to the trash,
+// changed ones are kept as conflicts with the deleted server copy.
+func (c *Client) resync(ctx context.Context) error {
+	seen := map[secretKey]bool{}
+
+	err := c.pullChanges(ctx, 0, seen)
+	if err != nil {
+		return err
+	}
 
-	// Push local
 	localSecrets, err := c.storage.GetSecretsByUser(ctx, c.config.User)
 	if err != nil {
-		c.log.Error().Err(err).Msgf(
-			"failed to get user '%s' local secrets",
-			c.config.User,
+		return err
+	}
+
+	for _, localSecret := range localSecrets {
+		// Never pushed secrets are not expected on the server
+		if localSecret.Revision == 0 || seen[secretKey{kind: localSecret.Kind, name: localSecret.Name}] {
+			continue
+		}
+
+		// Already in the trash
+		if localSecret.Deleted && !localSecret.Dirty {
+			continue
+		}
+
+		deletedSecret := db.Secret{
+			Owner:    localSecret.Owner,
+			Kind:     localSecret.Kind,
+			Name:     localSecret.Name,
+			Value:    []byte{},
+			Created:  localSecret.Created,
+			Modified: localSecret.Modified,
+			Deleted:  true,
+			RemoteID: localSecret.RemoteID,
+		}
+
+		if localSecret.Dirty {
+			err = c.saveConflict(ctx, localSecret, deletedSecret)
+		} else {
+			err = c.saveRemoteSecret(ctx, deletedSecret)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyRemoteSecret saves the remote secret changed after the local one.
+// If the local secret has unpushed changes too the conflict is kept for the user to resolve.
+func (c *Client) applyRemoteSecret(ctx context.Context, remoteSecret db.Secret) error {
+	localSecret, err := c.storage.GetSecret(
+		ctx,
+		db.GetSecretParams{
+			Owner: remoteSecret.Owner,
+			Kind:  remoteSecret.Kind,
+			Name:  remoteSecret.Name,
+		},
+	)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to get user '%s' secret '%s' from local db: %w", remoteSecret.Owner, remoteSecret.Name, err)
+	}
+	if err == nil {
+		// Local secret is up to date e.g. it was just pushed
+		if localSecret.Revision >= remoteSecret.Revision {
+			return nil
+		}
+
+		if localSecret.Dirty {
+			return c.saveConflict(ctx, localSecret, remoteSecret)
+		}
+
+		// Secret purged on another device is removed from the trash here too
+		if isPurged(remoteSecret) {
+			return c.purgeRemoteSecret(ctx, remoteSecret)
+		}
+	}
+
+	return c.saveRemoteSecret(ctx, remoteSecret)
+}
+
+// purgeRemoteSecret removes the secret purged on another device along with its versions.
+func (c *Client) purgeRemoteSecret(ctx context.Context, remoteSecret db.Secret) error {
+	err := c.storage.PurgeSecretVersions(
+		ctx,
+		db.PurgeSecretVersionsParams{
+			Owner: remoteSecret.Owner,
+			Kind:  remoteSecret.Kind,
+			Name:  remoteSecret.Name,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to purge user '%s' secret '%s' versions: %w", remoteSecret.Owner, remoteSecret.Name, err)
+	}
+
+	err = c.storage.DeleteSecret(
+		ctx,
+		db.DeleteSecretParams{
+			Owner: remoteSecret.Owner,
+			Kind:  remoteSecret.Kind,
+			Name:  remoteSecret.Name,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to purge user '%s' secret '%s': %w", remoteSecret.Owner, remoteSecret.Name, err)
+	}
+
+	c.log.Info().Msgf(
+		"successfully purged user '%s' secret '%s'",
+		remoteSecret.Owner,
+		remoteSecret.Name,
+	)
+	return nil
+}
+
+// saveRemoteSecret replaces the local secret with the server one.
+// Secrets deleted on the server are moved to the trash.
+func (c *Client) saveRemoteSecret(ctx context.Context, remoteSecret db.Secret) error {
+	if remoteSecret.Deleted {
+		err := c.storage.TrashLocalSecret(
+			ctx,
+			db.TrashLocalSecretParams{
+				Owner:    remoteSecret.Owner,
+				Kind:     remoteSecret.Kind,
+				Name:     remoteSecret.Name,
+				Revision: remoteSecret.Revision,
+			},
 		)
-		return
+		if err != nil {
+			return fmt.Errorf("failed to delete user '%s' secret '%s': %w", remoteSecret.Owner, remoteSecret.Name, err)
+		}
+
+		c.log.Info().Msgf(
+			"successfully moved user '%s' secret '%s' to trash",
+			remoteSecret.Owner,
+			remoteSecret.Name,
+		)
+		return nil
+	}
+
+	err := c.storage.ReplaceLocalSecret(
+		ctx,
+		db.ReplaceLocalSecretParams{
+			Owner:    remoteSecret.Owner,
+			Kind:     remoteSecret.Kind,
+			Name:     remoteSecret.Name,
+			Value:    remoteSecret.Value,
+			Created:  remoteSecret.Created,
+			Modified: remoteSecret.Modified,
+			Revision: remoteSecret.Revision,
+			RemoteID: remoteSecret.RemoteID,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to sync user '%s' secret '%s': %w", remoteSecret.Owner, remoteSecret.Name, err)
+	}
+
+	c.log.Info().Msgf(
+		"successfully synced user '%s' secret '%s'",
+		remoteSecret.Owner,
+		remoteSecret.Name,
+	)
+	return nil
+}
+
+// push sends the local secrets changed since the last sync.
+// Accepted secrets get their new revisions and are no longer dirty.
+func (c *Client) push(ctx context.Context) error {
+	dirtySecrets, err := c.storage.GetDirtySecrets(ctx, c.config.User)
+	if err != nil {
+		return err
+	}
+
+	if len(dirtySecrets) == 0 {
+		return nil
 	}
 
+	// Secrets recreated over their server tombstones are pushed once more as undeletes
+	recreated, err := c.pushSecrets(ctx, dirtySecrets, true)
+	if err == nil && len(recreated) > 0 {
+		c.log.Info().Msgf("pushing %v secrets recreated over their tombstones", len(recreated))
+		_, err = c.pushSecrets(ctx, recreated, false)
+	}
+	if err != nil {
+		return err
+	}
+
+	c.log.Info().Msgf("pushed %v changed secrets", len(dirtySecrets))
+
+	return nil
+}
+
+// pushSecrets sends the secrets and saves the outcome of every one. If retry is set
+// secrets conflicting with the server tombstones are not kept as conflicts,
+// they are returned based on the tombstones to be pushed again.
+func (c *Client) pushSecrets(ctx context.Context, secrets []db.Secret, retry bool) ([]db.Secret, error) {
 	localPBSecrets := []*pb.Secret{}
-	for _, secret := range localSecrets {
-		localPBSecrets = append(localPBSecrets, converter.DBSecretToPBSecret(secret))
+	for _, secret := range secrets {
+		pbSecret, err := c.toRemote(secret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to seal secret '%s': %w", secret.Name, err)
+		}
+
+		localPBSecrets = append(localPBSecrets, pbSecret)
 	}
 
-	_, err = c.g.SetSecrets(ctx, &pb.Secrets{Secrets: localPBSecrets})
+	result, err := c.g.SetSecrets(ctx, &pb.Secrets{Secrets: localPBSecrets})
 	if err != nil {
-		c.log.Error().Err(err).Msgf(
-			"failed to push user '%s' local secrets",
-			c.config.User,
+		return nil, err
+	}
+
+	// End-to-end encrypted secrets are reported by their local names
+	for i, secretResult := range result.Results {
+		if i < len(secrets) {
+			secretResult.Kind = secrets[i].Kind
+			secretResult.Name = secrets[i].Name
+		}
+	}
+
+	c.reportSyncResult(result)
+
+	var recreated []db.Secret
+	for i, secretResult := range result.Results {
+		if i >= len(secrets) {
+			break
+		}
+		secret := secrets[i]
+
+		switch secretResult.Outcome {
+		case pb.SecretResult_FAILED:
+			// Rejected secrets are pushed again after they are fixed
+			continue
+		case pb.SecretResult_CONFLICT:
+			// Local deletion loses to the server one unless it is a purge
+			tombstone := secretResult.Current.Deleted && (!secret.Deleted || isPurged(secret))
+			if retry && tombstone {
+				secret.Revision = secretResult.Current.Revision
+				recreated = append(recreated, secret)
+				continue
+			}
+
+			current, err := c.fromRemote(secretResult.Current)
+			if err != nil {
+				return nil, err
+			}
+
+			err = c.saveConflict(ctx, secret, current)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		err := c.storage.MarkSecretSynced(
+			ctx,
+			db.MarkSecretSyncedParams{
+				Owner:    secret.Owner,
+				Kind:     secret.Kind,
+				Name:     secret.Name,
+				Revision: secretResult.Revision,
+				Modified: secret.Modified,
+				Deleted:  secret.Deleted,
+			},
 		)
-		return
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	c.log.Info().Msg("secrets sync finished")
+	return recreated, nil
+}
+
+// reportSyncResult logs the outcome of every pushed secret.
+// Secrets rejected by the server are also reported to the UI.
+func (c *Client) reportSyncResult(result *pb.SyncResult) {
+	rejected := []string{}
+
+	for _, secretResult := range result.Results {
+		switch secretResult.Outcome {
+		case pb.SecretResult_FAILED:
+			c.log.Error().Msgf("server rejected secret '%s': %s", secretResult.Name, secretResult.Reason)
+			rejected = append(rejected, secretResult.Name)
+		case pb.SecretResult_CONFLICT:
+			c.log.Warn().Msgf("secret '%s' was changed on the server since the last sync", secretResult.Name)
+		default:
+			c.log.Info().Msgf(
+				"secret '%s' synced: %s",
+				secretResult.Name,
+				strings.ToLower(secretResult.Outcome.String()),
+			)
+		}
+	}
+
+	if len(rejected) > 0 {
+		c.notify("Failed to sync " + strings.Join(rejected, ", "))
+	}
+}
+
+// notify reports the sync problem to be shown in the UI.
+func (c *Client) notify(notice string) {
+	c.syncNotice.Store(&notice)
 }
@@ -0,0 +1,292 @@
+package client
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"gophkeeper/db/db"
+	"gophkeeper/db/mock"
+	"gophkeeper/pb"
+	"gophkeeper/random"
+)
+
+func TestReportSyncResult(t *testing.T) {
+	client := Client{}
+
+	client.reportSyncResult(&pb.SyncResult{
+		Results: []*pb.SecretResult{
+			{Name: "created", Outcome: pb.SecretResult_CREATED},
+			{Name: "conflicted", Outcome: pb.SecretResult_CONFLICT},
+		},
+	})
+	require.Nil(t, client.syncNotice.Load())
+
+	client.reportSyncResult(&pb.SyncResult{
+		Results: []*pb.SecretResult{
+			{Name: "created", Outcome: pb.SecretResult_CREATED},
+			{Name: "broken", Outcome: pb.SecretResult_FAILED, Reason: "empty secret name"},
+		},
+	})
+
+	notice := client.syncNotice.Swap(nil)
+	require.NotNil(t, notice)
+	require.Contains(t, *notice, "broken")
+	require.Nil(t, client.syncNotice.Load())
+}
+
+func TestApplyRemoteSecret(t *testing.T) {
+	testOwner := random.RandomOwner()
+
+	remoteSecret := db.Secret{
+		Owner:    testOwner,
+		Kind:     int32(SecretText),
+		Name:     random.RandomString(10),
+		Value:    []byte("theirs"),
+		Revision: 2,
+	}
+
+	tests := []struct {
+		name        string
+		localSecret db.Secret
+		localErr    error
+		conflict    bool
+		replace     bool
+	}{
+		{
+			name:     "new secret",
+			localErr: sql.ErrNoRows,
+			replace:  true,
+		},
+		{
+			name:        "pushed secret",
+			localSecret: db.Secret{Revision: 2, Value: []byte("mine")},
+		},
+		{
+			name:        "unchanged secret",
+			localSecret: db.Secret{Revision: 1, Value: []byte("mine")},
+			replace:     true,
+		},
+		{
+			name:        "locally deleted secret",
+			localSecret: db.Secret{Revision: 1, Value: []byte("mine"), Dirty: true, Deleted: true},
+			replace:     true,
+		},
+		{
+			name:        "conflicted secret",
+			localSecret: db.Secret{Revision: 1, Value: []byte("mine"), Dirty: true},
+			conflict:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			mockStorage := mock.NewMockQuerier(controller)
+
+			localSecret := tt.localSecret
+			localSecret.Owner = remoteSecret.Owner
+			localSecret.Kind = remoteSecret.Kind
+			localSecret.Name = remoteSecret.Name
+
+			mockStorage.EXPECT().
+				GetSecret(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(localSecret, tt.localErr)
+
+			conflictCalls := 0
+			if tt.conflict {
+				conflictCalls = 1
+			}
+			mockStorage.EXPECT().
+				SaveSecretConflict(gomock.Any(), gomock.Any()).
+				Times(conflictCalls).
+				DoAndReturn(func(_ context.Context, params db.SaveSecretConflictParams) error {
+					require.Equal(t, remoteSecret.Value, params.Value)
+					require.Equal(t, remoteSecret.Revision, params.Revision)
+					return nil
+				})
+
+			replaceCalls := 0
+			if tt.replace {
+				replaceCalls = 1
+			}
+			mockStorage.EXPECT().
+				ReplaceLocalSecret(gomock.Any(), gomock.Any()).
+				Times(replaceCalls).
+				Return(nil)
+
+			client := Client{
+				config:  Config{User: testOwner},
+				storage: mockStorage,
+			}
+
+			err := client.applyRemoteSecret(context.Background(), remoteSecret)
+			require.NoError(t, err)
+			require.Equal(t, tt.conflict, client.syncNotice.Load() != nil)
+		})
+	}
+}
+
+// changesServer serves the secret changes for the resync.
+type changesServer struct {
+	pb.GophKeeperClient
+	secrets []*pb.Secret
+}
+
+func (s *changesServer) GetChanges(_ context.Context, in *pb.ChangesRequest, _ ...grpc.CallOption) (*pb.Changes, error) {
+	if !in.Resync {
+		return &pb.Changes{Resync: true}, nil
+	}
+
+	return &pb.Changes{Secrets: s.secrets, Revision: 30}, nil
+}
+
+func TestPullResync(t *testing.T) {
+	testOwner := random.RandomOwner()
+
+	keptSecret := db.Secret{Owner: testOwner, Name: "kept", Revision: 11}
+	deletedSecret := db.Secret{Owner: testOwner, Name: "deleted", Revision: 5}
+	changedSecret := db.Secret{Owner: testOwner, Name: "changed", Revision: 6, Dirty: true}
+	newSecret := db.Secret{Owner: testOwner, Name: "new", Dirty: true}
+	trashedSecret := db.Secret{Owner: testOwner, Name: "trashed", Revision: 4, Deleted: true}
+
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockQuerier(controller)
+
+	mockStorage.EXPECT().
+		GetSyncCursor(gomock.Any(), testOwner).
+		Times(1).
+		Return(int64(20), nil)
+
+	mockStorage.EXPECT().
+		GetSecret(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(keptSecret, nil)
+
+	mockStorage.EXPECT().
+		SetSyncCursor(gomock.Any(), db.SetSyncCursorParams{Owner: testOwner, Revision: 30}).
+		Times(1).
+		Return(nil)
+
+	mockStorage.EXPECT().
+		GetSecretsByUser(gomock.Any(), testOwner).
+		Times(1).
+		Return([]db.Secret{keptSecret, deletedSecret, changedSecret, newSecret, trashedSecret}, nil)
+
+	// Synced secret missing on the server is moved to the trash
+	mockStorage.EXPECT().
+		TrashLocalSecret(
+			gomock.Any(),
+			db.TrashLocalSecretParams{Owner: testOwner, Name: deletedSecret.Name},
+		).
+		Times(1).
+		Return(nil)
+
+	// Changed one is kept as a conflict with the deletion
+	mockStorage.EXPECT().
+		SaveSecretConflict(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, params db.SaveSecretConflictParams) error {
+			require.Equal(t, changedSecret.Name, params.Name)
+			require.True(t, params.Deleted)
+			require.Zero(t, params.Revision)
+			return nil
+		})
+
+	client := Client{
+		config:  Config{User: testOwner},
+		storage: mockStorage,
+		g: &changesServer{
+			secrets: []*pb.Secret{{Owner: testOwner, Name: keptSecret.Name, Revision: keptSecret.Revision}},
+		},
+	}
+
+	err := client.pull(context.Background())
+	require.NoError(t, err)
+}
+
+// tombstoneServer keeps the tombstone of the secret and accepts the pushes based on it.
+type tombstoneServer struct {
+	pb.GophKeeperClient
+	revision int64
+	pushed   []int64
+}
+
+func (s *tombstoneServer) SetSecrets(_ context.Context, in *pb.Secrets, _ ...grpc.CallOption) (*pb.SyncResult, error) {
+	results := []*pb.SecretResult{}
+	for _, secret := range in.Secrets {
+		s.pushed = append(s.pushed, secret.Revision)
+
+		if secret.Revision != s.revision {
+			results = append(
+				results,
+				&pb.SecretResult{
+					Kind:    secret.Kind,
+					Name:    secret.Name,
+					Outcome: pb.SecretResult_CONFLICT,
+					Current: &pb.Secret{Owner: secret.Owner, Kind: secret.Kind, Name: secret.Name, Deleted: true, Revision: s.revision},
+				},
+			)
+			continue
+		}
+
+		s.revision++
+		results = append(
+			results,
+			&pb.SecretResult{Kind: secret.Kind, Name: secret.Name, Outcome: pb.SecretResult_UPDATED, Revision: s.revision},
+		)
+	}
+
+	return &pb.SyncResult{Results: results}, nil
+}
+
+func TestPushRecreated(t *testing.T) {
+	ctx := context.Background()
+	testOwner := random.RandomOwner()
+	storage := newTestStorage(t, testOwner)
+	server := &tombstoneServer{revision: 7}
+
+	client := &Client{
+		config:  Config{User: testOwner},
+		storage: storage,
+		g:       server,
+		log:     zerolog.Nop(),
+	}
+
+	// Secret purged from the trash is made again while its tombstone is kept on the server
+	_, err := storage.UpsertLocalSecret(
+		ctx,
+		db.UpsertLocalSecretParams{
+			Owner:    testOwner,
+			Kind:     int32(SecretText),
+			Name:     "recreated",
+			Value:    []byte("value"),
+			Created:  time.Now(),
+			Modified: time.Now(),
+		},
+	)
+	require.NoError(t, err)
+
+	err = client.push(ctx)
+	require.NoError(t, err)
+
+	// It is pushed again as the undelete of the tombstone
+	require.Equal(t, []int64{0, 7}, server.pushed)
+
+	secret, err := storage.GetSecret(ctx, db.GetSecretParams{Owner: testOwner, Kind: int32(SecretText), Name: "recreated"})
+	require.NoError(t, err)
+	require.Equal(t, int64(8), secret.Revision)
+	require.False(t, secret.Dirty)
+
+	conflicts, err := storage.GetSecretConflicts(ctx, testOwner)
+	require.NoError(t, err)
+	require.Empty(t, conflicts)
+	require.Nil(t, client.syncNotice.Load())
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"errors"
+
+	"gophkeeper/db/db"
+)
+
+// errNotTrashed is returned when the secret to restore or purge is not in the trash.
+var errNotTrashed = errors.New("secret is not in the trash")
+
+// GetTrash returns the deleted secrets, most recently deleted first.
+func (c *Client) GetTrash() ([]db.Secret, error) {
+	secrets, err := c.storage.GetTrashedSecrets(context.Background(), c.config.User)
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to get user '%s' trash", c.config.User)
+		return nil, err
+	}
+
+	return secrets, nil
+}
+
+// RestoreSecret moves the secret back from the trash.
+// The restore is pushed as an undelete on the next sync.
+func (c *Client) RestoreSecret(kind SecretKind, name string) error {
+	_, err := c.getTrashedSecret(kind, name)
+	if err != nil {
+		return err
+	}
+
+	err = c.storage.RestoreLocalSecret(
+		context.Background(),
+		db.RestoreLocalSecretParams{
+			Owner: c.config.User,
+			Kind:  int32(kind),
+			Name:  name,
+		},
+	)
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to restore user '%s' secret '%s'", c.config.User, name)
+		return err
+	}
+
+	c.log.Info().Msgf("successfully restored user '%s' secret '%s' from trash", c.config.User, name)
+
+	return nil
+}
+
+// PurgeSecret permanently deletes the secret from the trash along with its versions.
+// The deletion is pushed without the value on the next sync: the server drops the value
+// and the versions of the secret and the other devices remove it from their trash.
+func (c *Client) PurgeSecret(kind SecretKind, name string) error {
+	_, err := c.getTrashedSecret(kind, name)
+	if err != nil {
+		return err
+	}
+
+	err = c.storage.PurgeSecretVersions(
+		context.Background(),
+		db.PurgeSecretVersionsParams{
+			Owner: c.config.User,
+			Kind:  int32(kind),
+			Name:  name,
+		},
+	)
+	if err == nil {
+		err = c.storage.PurgeLocalSecret(
+			context.Background(),
+			db.PurgeLocalSecretParams{
+				Owner: c.config.User,
+				Kind:  int32(kind),
+				Name:  name,
+			},
+		)
+	}
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to purge user '%s' secret '%s'", c.config.User, name)
+		return err
+	}
+
+	c.log.Info().Msgf("successfully purged user '%s' secret '%s'", c.config.User, name)
+
+	return nil
+}
+
+// isPurged reports whether the deleted secret was purged: its deletion carries no value.
+func isPurged(secret db.Secret) bool {
+	return secret.Deleted && len(secret.Value) == 0
+}
+
+// getTrashedSecret returns the deleted secret.
+func (c *Client) getTrashedSecret(kind SecretKind, name string) (db.Secret, error) {
+	secret, err := c.storage.GetSecret(
+		context.Background(),
+		db.GetSecretParams{
+			Owner: c.config.User,
+			Kind:  int32(kind),
+			Name:  name,
+		},
+	)
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to get secret '%s' from db", name)
+		return db.Secret{}, err
+	}
+
+	if !secret.Deleted {
+		return db.Secret{}, errNotTrashed
+	}
+
+	return secret, nil
+}
@@ -0,0 +1,207 @@
+package client
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/db/db"
+	"gophkeeper/db/mock"
+	"gophkeeper/random"
+)
+
+func TestRestoreSecret(t *testing.T) {
+	testOwner := random.RandomOwner()
+	testName := random.RandomString(10)
+
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockQuerier(controller)
+
+	mockStorage.EXPECT().
+		GetSecret(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.Secret{Owner: testOwner, Name: testName, Deleted: true}, nil)
+
+	mockStorage.EXPECT().
+		RestoreLocalSecret(
+			gomock.Any(),
+			db.RestoreLocalSecretParams{Owner: testOwner, Kind: int32(SecretText), Name: testName},
+		).
+		Times(1).
+		Return(nil)
+
+	client := Client{
+		config:  Config{User: testOwner},
+		storage: mockStorage,
+	}
+
+	err := client.RestoreSecret(SecretText, testName)
+	require.NoError(t, err)
+}
+
+func TestRestoreSecretNotTrashed(t *testing.T) {
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockQuerier(controller)
+
+	mockStorage.EXPECT().
+		GetSecret(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.Secret{}, nil)
+
+	mockStorage.EXPECT().
+		RestoreLocalSecret(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	client := Client{
+		config:  Config{User: random.RandomOwner()},
+		storage: mockStorage,
+	}
+
+	err := client.RestoreSecret(SecretText, random.RandomString(10))
+	require.ErrorIs(t, err, errNotTrashed)
+}
+
+func TestPurgeSecret(t *testing.T) {
+	tests := []struct {
+		name  string
+		dirty bool
+	}{
+		{
+			name: "synced deletion",
+		},
+		{
+			name:  "deletion to push",
+			dirty: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testOwner := random.RandomOwner()
+			testName := random.RandomString(10)
+
+			controller := gomock.NewController(t)
+			mockStorage := mock.NewMockQuerier(controller)
+
+			mockStorage.EXPECT().
+				GetSecret(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.Secret{Deleted: true, Dirty: tt.dirty}, nil)
+
+			// Both are pushed again for the server to drop the value
+			mockStorage.EXPECT().
+				PurgeSecretVersions(
+					gomock.Any(),
+					db.PurgeSecretVersionsParams{Owner: testOwner, Kind: int32(SecretText), Name: testName},
+				).
+				Times(1).
+				Return(nil)
+
+			mockStorage.EXPECT().
+				PurgeLocalSecret(
+					gomock.Any(),
+					db.PurgeLocalSecretParams{Owner: testOwner, Kind: int32(SecretText), Name: testName},
+				).
+				Times(1).
+				Return(nil)
+
+			client := Client{
+				config:  Config{User: testOwner},
+				storage: mockStorage,
+			}
+
+			err := client.PurgeSecret(SecretText, testName)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestPurgeSecretSync(t *testing.T) {
+	ctx := context.Background()
+	testOwner := random.RandomOwner()
+	testName := random.RandomString(10)
+
+	// newTrashedSecret returns the storage with the synced secret, its version and its deletion
+	newTrashedSecret := func(t *testing.T) db.Querier {
+		storage := newTestStorage(t, testOwner)
+
+		for revision := int64(1); revision <= 2; revision++ {
+			err := storage.ReplaceLocalSecret(
+				ctx,
+				db.ReplaceLocalSecretParams{
+					Owner:    testOwner,
+					Kind:     int32(SecretText),
+					Name:     testName,
+					Value:    []byte(fmt.Sprintf("value %v", revision)),
+					Created:  time.Now(),
+					Modified: time.Now(),
+					Revision: revision,
+				},
+			)
+			require.NoError(t, err)
+		}
+
+		err := storage.TrashLocalSecret(
+			ctx,
+			db.TrashLocalSecretParams{Owner: testOwner, Kind: int32(SecretText), Name: testName, Revision: 3},
+		)
+		require.NoError(t, err)
+
+		return storage
+	}
+
+	versionsParams := db.GetSecretVersionsParams{Owner: testOwner, Kind: int32(SecretText), Name: testName, Limit: 10}
+
+	// The purge drops the value and the versions and is pushed again
+	storage := newTrashedSecret(t)
+	client := Client{config: Config{User: testOwner}, storage: storage, log: zerolog.Nop()}
+
+	versions, err := storage.GetSecretVersions(ctx, versionsParams)
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+
+	err = client.PurgeSecret(SecretText, testName)
+	require.NoError(t, err)
+
+	trash, err := client.GetTrash()
+	require.NoError(t, err)
+	require.Empty(t, trash)
+
+	versions, err = storage.GetSecretVersions(ctx, versionsParams)
+	require.NoError(t, err)
+	require.Empty(t, versions)
+
+	dirty, err := storage.GetDirtySecrets(ctx, testOwner)
+	require.NoError(t, err)
+	require.Len(t, dirty, 1)
+
+	pbSecret, err := client.toRemote(dirty[0])
+	require.NoError(t, err)
+	require.True(t, pbSecret.Deleted)
+	require.Empty(t, pbSecret.Value)
+	require.Equal(t, int64(3), pbSecret.Revision)
+
+	// Another device removes the purged secret from its trash
+	storage = newTrashedSecret(t)
+	client = Client{config: Config{User: testOwner}, storage: storage, log: zerolog.Nop()}
+
+	purged, err := client.fromRemote(pbSecret)
+	require.NoError(t, err)
+	purged.Revision = 4
+
+	err = client.applyRemoteSecret(ctx, purged)
+	require.NoError(t, err)
+
+	_, err = storage.GetSecret(ctx, db.GetSecretParams{Owner: testOwner, Kind: int32(SecretText), Name: testName})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	versions, err = storage.GetSecretVersions(ctx, versionsParams)
+	require.NoError(t, err)
+	require.Empty(t, versions)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
@@ -12,6 +13,7 @@ import (
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"google.golang.org/grpc/status"
 )
 
 var (
@@ -24,12 +26,56 @@ var (
 
 type item struct {
 	name, kind string
+	conflict   bool
 }
 
-func (i item) Title() string       { return i.name }
-func (i item) Description() string { return i.kind }
+func (i item) Title() string {
+	if i.conflict {
+		return "⚠ " + i.name
+	}
+	return i.name
+}
+func (i item) Description() string {
+	if i.conflict {
+		return i.kind + " • conflict"
+	}
+	return i.kind
+}
 func (i item) FilterValue() string { return i.name }
 
+type sessionItem struct {
+	id, device, version, ip, lastSeen string
+	current                           bool
+}
+
+func (s sessionItem) Title() string {
+	if s.current {
+		return s.device + " (current)"
+	}
+	return s.device
+}
+func (s sessionItem) Description() string {
+	return fmt.Sprintf("v%s from %s, last seen %s", s.version, s.ip, s.lastSeen)
+}
+func (s sessionItem) FilterValue() string { return s.device }
+
+type versionItem struct {
+	revision int64
+	modified string
+}
+
+func (v versionItem) Title() string       { return fmt.Sprintf("Revision %v", v.revision) }
+func (v versionItem) Description() string { return "replaced " + v.modified }
+func (v versionItem) FilterValue() string { return "" }
+
+type trashItem struct {
+	name, kind, deleted string
+}
+
+func (t trashItem) Title() string       { return t.name }
+func (t trashItem) Description() string { return t.kind + " • deleted " + t.deleted }
+func (t trashItem) FilterValue() string { return t.name }
+
 type choiceItem string
 
 func (c choiceItem) Title() string       { return string(c) }
@@ -43,26 +89,66 @@ const (
 	choice
 	entry
 	show
+	sessions
+	secondFactor
+	conflict
+	history
+	trash
 )
 
+// secondFactorMsg reports whether the login waits for a second factor code.
+type secondFactorMsg bool
+
+// syncNoticeMsg reports the problem of the background sync.
+type syncNoticeMsg string
+
 type model struct {
 	mode mode
 	goph *Client
 
-	list    list.Model // Main menu
-	choices list.Model // New secret kinds menu
+	list     list.Model // Main menu
+	choices  list.Model // New secret kinds menu
+	sessions list.Model // Active sessions menu
+	versions list.Model // Shown secret versions menu
+	trash    list.Model // Deleted secrets menu
 
 	inputs     []textinput.Model // New secret params input
 	focusIndex int               // Index for new secret param
 
 	selectedSecretKind SecretKind      // Selected secret kind for new secret
+	selectedSecret     item            // Shown secret
 	viewport           viewport.Model  // Display secret info
 	secretBytesContent []byte          // Content of bytes secret - file content
 	input              textinput.Model // File path to save bytes secret content on disk
+
+	codeInput   textinput.Model // Second factor code
+	codeSkipped bool            // User chose to work offline instead of entering the code
+
+	selectedConflict item            // Conflicted secret being resolved
+	theirsDeleted    bool            // Conflicted secret is deleted on another device
+	nameInput        textinput.Model // New name to keep both conflicted versions
 }
 
 func (m model) Init() tea.Cmd {
-	return nil
+	return tea.Batch(m.checkSecondFactor(), m.checkSyncNotice())
+}
+
+// checkSecondFactor periodically checks if the background login waits for a second factor code.
+func (m model) checkSecondFactor() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return secondFactorMsg(m.goph.secondFactorRequired.Load())
+	})
+}
+
+// checkSyncNotice periodically checks if the background sync has a problem to report.
+func (m model) checkSyncNotice() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		notice := m.goph.syncNotice.Swap(nil)
+		if notice == nil {
+			return syncNoticeMsg("")
+		}
+		return syncNoticeMsg(*notice)
+	})
 }
 
 func (m model) View() string {
@@ -94,7 +180,36 @@ func (m model) View() string {
 	}
 
 	if m.mode == show {
-		return shellStyle.Render(m.viewport.View())
+		help := "h history • esc back"
+		if m.selectedSecretKind == SecretBytes {
+			help = "s save • " + help
+		}
+		return shellStyle.Render(m.viewport.View() + "\n\n" + helpStyle.Render(help))
+	}
+
+	if m.mode == sessions {
+		return shellStyle.Render(m.sessions.View())
+	}
+
+	if m.mode == history {
+		return shellStyle.Render(m.versions.View())
+	}
+
+	if m.mode == trash {
+		return shellStyle.Render(m.trash.View())
+	}
+
+	if m.mode == conflict {
+		if m.nameInput.Focused() {
+			return shellStyle.Render("New name for your version (esc to cancel):\n\n" + m.nameInput.View())
+		}
+
+		help := helpStyle.Render("m keep mine • t keep theirs • b keep both • esc back")
+		return shellStyle.Render(m.viewport.View() + "\n\n" + help)
+	}
+
+	if m.mode == secondFactor {
+		return shellStyle.Render("Two-factor authentication code (esc to work offline):\n\n" + m.codeInput.View())
 	}
 
 	return shellStyle.Render(m.list.View())
@@ -109,6 +224,24 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		h, v := shellStyle.GetFrameSize()
 		m.list.SetSize(msg.Width-h, msg.Height-v)
 		m.choices.SetSize(msg.Width-h, msg.Height-v)
+		m.sessions.SetSize(msg.Width-h, msg.Height-v)
+		m.versions.SetSize(msg.Width-h, msg.Height-v)
+		m.trash.SetSize(msg.Width-h, msg.Height-v)
+	case secondFactorMsg:
+		if bool(msg) && m.mode == main && !m.codeSkipped && !m.input.Focused() {
+			m.mode = secondFactor
+			cmds = append(cmds, m.codeInput.Focus())
+		}
+
+		cmds = append(cmds, m.checkSecondFactor())
+		return m, tea.Batch(cmds...)
+	case syncNoticeMsg:
+		cmds = append(cmds, m.checkSyncNotice())
+		if msg != "" {
+			cmds = append(cmds, m.markConflicts())
+			cmds = append(cmds, m.list.NewStatusMessage(statusMessageStyle(string(msg))))
+		}
+		return m, tea.Batch(cmds...)
 	case tea.KeyMsg:
 		if m.input.Focused() {
 			switch {
@@ -135,6 +268,33 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		switch m.mode {
+		case conflict:
+			return m.updateConflict(msg)
+		case secondFactor:
+			switch {
+			case key.Matches(msg, keyMap.Back):
+				m.codeSkipped = true
+				m.codeInput.Blur()
+				m.mode = main
+				statusCmd := m.list.NewStatusMessage(statusMessageStyle("Not authorized...working offline"))
+				return m, statusCmd
+			case key.Matches(msg, keyMap.Enter):
+				err := m.goph.loginWithCode(context.Background(), strings.TrimSpace(m.codeInput.Value()))
+				m.codeInput.SetValue("")
+				if err != nil {
+					e, _ := status.FromError(err)
+					m.codeInput.Placeholder = e.Message()
+					return m, nil
+				}
+
+				m.codeInput.Blur()
+				m.mode = main
+				statusCmd := m.list.NewStatusMessage(statusMessageStyle("Logged in"))
+				return m, statusCmd
+			}
+
+			m.codeInput, cmd = m.codeInput.Update(msg)
+			return m, cmd
 		case choice:
 			switch {
 			case key.Matches(msg, keyMap.Back):
@@ -208,11 +368,121 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			cmd := m.updateInputs(msg)
 			return m, cmd
+		case sessions:
+			switch {
+			case key.Matches(msg, keyMap.Back):
+				m.mode = main
+				return m, nil
+			case key.Matches(msg, keyMap.Delete):
+				i, ok := m.sessions.SelectedItem().(sessionItem)
+				if !ok {
+					return m, nil
+				}
+
+				if i.current {
+					statusCmd := m.sessions.NewStatusMessage(statusMessageStyle("Current session can't be revoked"))
+					return m, statusCmd
+				}
+
+				err := m.goph.RevokeSession(context.Background(), i.id)
+				if err != nil {
+					statusCmd := m.sessions.NewStatusMessage(statusMessageStyle("Failed to revoke session: " + err.Error()))
+					return m, statusCmd
+				}
+
+				m.sessions.RemoveItem(m.sessions.Index())
+				statusCmd := m.sessions.NewStatusMessage(statusMessageStyle("Revoked " + i.device))
+				return m, statusCmd
+			case key.Matches(msg, keyMap.RevokeAll):
+				err := m.goph.RevokeAllSessions(context.Background())
+				if err != nil {
+					statusCmd := m.sessions.NewStatusMessage(statusMessageStyle("Failed to revoke sessions: " + err.Error()))
+					return m, statusCmd
+				}
+
+				cmd := m.loadSessions()
+				statusCmd := m.sessions.NewStatusMessage(statusMessageStyle("Revoked all other sessions"))
+				return m, tea.Batch(cmd, statusCmd)
+			default:
+				m.sessions, cmd = m.sessions.Update(msg)
+				cmds = append(cmds, cmd)
+				return m, tea.Batch(cmds...)
+			}
+		case trash:
+			switch {
+			case key.Matches(msg, keyMap.Back):
+				m.mode = main
+				return m, nil
+			case key.Matches(msg, keyMap.Restore):
+				t, ok := m.trash.SelectedItem().(trashItem)
+				if !ok {
+					return m, nil
+				}
+
+				err := m.goph.RestoreSecret(stringToSecretKind[t.kind], t.name)
+				if err != nil {
+					statusCmd := m.trash.NewStatusMessage(statusMessageStyle("Failed to restore secret: " + err.Error()))
+					return m, statusCmd
+				}
+
+				m.trash.RemoveItem(m.trash.Index())
+				keyMap.Delete.SetEnabled(true)
+				insCmd := m.list.InsertItem(0, item{name: t.name, kind: t.kind})
+				statusCmd := m.trash.NewStatusMessage(statusMessageStyle("Restored " + t.name))
+				return m, tea.Batch(insCmd, statusCmd)
+			case key.Matches(msg, keyMap.Purge):
+				t, ok := m.trash.SelectedItem().(trashItem)
+				if !ok {
+					return m, nil
+				}
+
+				err := m.goph.PurgeSecret(stringToSecretKind[t.kind], t.name)
+				if err != nil {
+					statusCmd := m.trash.NewStatusMessage(statusMessageStyle("Failed to purge secret: " + err.Error()))
+					return m, statusCmd
+				}
+
+				m.trash.RemoveItem(m.trash.Index())
+				statusCmd := m.trash.NewStatusMessage(statusMessageStyle("Purged " + t.name))
+				return m, statusCmd
+			default:
+				m.trash, cmd = m.trash.Update(msg)
+				return m, cmd
+			}
+		case history:
+			switch {
+			case key.Matches(msg, keyMap.Back):
+				m.mode = show
+				return m, nil
+			case key.Matches(msg, keyMap.Enter):
+				v, ok := m.versions.SelectedItem().(versionItem)
+				if !ok {
+					return m, nil
+				}
+
+				i := m.selectedSecret
+				err := m.goph.RestoreSecretVersion(context.Background(), stringToSecretKind[i.kind], i.name, v.revision)
+				if err != nil {
+					statusCmd := m.versions.NewStatusMessage(statusMessageStyle("Failed to restore version: " + err.Error()))
+					return m, statusCmd
+				}
+
+				m.mode = main
+				statusCmd := m.list.NewStatusMessage(statusMessageStyle(fmt.Sprintf("Restored %s to revision %v", i.name, v.revision)))
+				return m, tea.Batch(statusCmd, m.markConflicts())
+			default:
+				m.versions, cmd = m.versions.Update(msg)
+				return m, cmd
+			}
 		case show:
 			switch {
 			case key.Matches(msg, keyMap.Back):
 				m.mode = main
 				return m, nil
+			case key.Matches(msg, keyMap.History):
+				cmd := m.loadVersions()
+				m.mode = history
+				return m, cmd
 			case key.Matches(msg, keyMap.Save):
 				m.goph.log.Info().Msg(secretKindToString[m.selectedSecretKind])
 				if m.selectedSecretKind == SecretBytes {
@@ -230,6 +500,11 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case key.Matches(msg, keyMap.Enter):
 				i, _ := m.list.SelectedItem().(item)
 
+				if i.conflict {
+					m.openConflict(i)
+					return m, nil
+				}
+
 				m.viewport = viewport.New(200, 10)
 
 				// Load secret from DB. Decrypt if needed
@@ -255,11 +530,20 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.viewport.SetContent(secretContent)
 				}
 				m.selectedSecretKind = SecretKind(dbSecret.Kind)
+				m.selectedSecret = i
 				m.mode = show
 				return m, nil
 			case key.Matches(msg, keyMap.Create):
 				m.mode = choice
 				return m, nil
+			case key.Matches(msg, keyMap.Sessions):
+				cmd := m.loadSessions()
+				m.mode = sessions
+				return m, cmd
+			case key.Matches(msg, keyMap.Trash):
+				cmd := m.loadTrash()
+				m.mode = trash
+				return m, cmd
 			case key.Matches(msg, keyMap.Delete):
 				i, ok := m.list.SelectedItem().(item)
 				if !ok {
@@ -273,7 +557,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 
 				m.goph.DeleteSecret(stringToSecretKind[i.kind], i.name)
-				statusCmd := m.list.NewStatusMessage(statusMessageStyle("Deleted " + i.Title()))
+				statusCmd := m.list.NewStatusMessage(statusMessageStyle("Moved " + i.name + " to trash"))
 				return m, tea.Batch(statusCmd)
 			}
 		}
@@ -286,6 +570,195 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// openConflict shows both versions of the conflicted secret.
+func (m *model) openConflict(i item) {
+	m.viewport = viewport.New(200, 20)
+	m.selectedConflict = i
+	m.theirsDeleted = false
+	m.mode = conflict
+
+	secretConflict, err := m.goph.GetSecretConflict(stringToSecretKind[i.kind], i.name)
+	if err != nil {
+		m.viewport.SetContent(err.Error())
+		return
+	}
+
+	m.theirsDeleted = secretConflict.Theirs.Deleted
+	m.viewport.SetContent(m.goph.renderConflict(secretConflict))
+}
+
+// updateConflict resolves the selected conflict with the user choice.
+func (m model) updateConflict(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	i := m.selectedConflict
+	kind := stringToSecretKind[i.kind]
+
+	if m.nameInput.Focused() {
+		switch {
+		case key.Matches(msg, keyMap.Back):
+			m.nameInput.SetValue("")
+			m.nameInput.Blur()
+			return m, nil
+		case key.Matches(msg, keyMap.Enter):
+			newName := strings.TrimSpace(m.nameInput.Value())
+			_, err := m.goph.KeepBoth(kind, i.name, newName)
+			m.nameInput.SetValue("")
+			if err != nil {
+				m.nameInput.Placeholder = err.Error()
+				return m, nil
+			}
+
+			m.nameInput.Blur()
+			m.removeDeletedConflict()
+			insCmd := m.list.InsertItem(0, item{name: newName, kind: i.kind})
+			return m, tea.Batch(insCmd, m.resolveConflict("Saved your version as "+newName))
+		}
+
+		var cmd tea.Cmd
+		m.nameInput, cmd = m.nameInput.Update(msg)
+		return m, cmd
+	}
+
+	switch {
+	case key.Matches(msg, keyMap.Back):
+		m.mode = main
+		return m, nil
+	case key.Matches(msg, keyMap.KeepMine):
+		err := m.goph.KeepMine(kind, i.name)
+		if err != nil {
+			m.viewport.SetContent(err.Error())
+			return m, nil
+		}
+		return m, m.resolveConflict("Kept your version of " + i.name)
+	case key.Matches(msg, keyMap.KeepTheirs):
+		err := m.goph.KeepTheirs(kind, i.name)
+		if err != nil {
+			m.viewport.SetContent(err.Error())
+			return m, nil
+		}
+		m.removeDeletedConflict()
+		return m, m.resolveConflict("Kept the other version of " + i.name)
+	case key.Matches(msg, keyMap.KeepBoth):
+		m.nameInput.Placeholder = i.name + " (mine)"
+		return m, m.nameInput.Focus()
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+// resolveConflict returns to the secrets list and refreshes the conflict badges.
+func (m *model) resolveConflict(status string) tea.Cmd {
+	m.mode = main
+	return tea.Batch(m.markConflicts(), m.list.NewStatusMessage(statusMessageStyle(status)))
+}
+
+// removeDeletedConflict removes the conflicted secret replaced by its deletion from the list.
+func (m *model) removeDeletedConflict() {
+	if m.theirsDeleted {
+		m.list.RemoveItem(m.list.Index())
+	}
+}
+
+// markConflicts updates the conflict badges of the secrets list.
+func (m *model) markConflicts() tea.Cmd {
+	conflicts, err := m.goph.GetSecretConflicts()
+	if err != nil {
+		m.goph.log.Error().Err(err).Msgf("failed to list user '%s' secret conflicts", m.goph.config.User)
+		return nil
+	}
+
+	var cmds []tea.Cmd
+	for index, listItem := range m.list.Items() {
+		i, ok := listItem.(item)
+		if !ok {
+			continue
+		}
+
+		conflict := conflicts[stringToSecretKind[i.kind]][i.name]
+		if i.conflict != conflict {
+			i.conflict = conflict
+			cmds = append(cmds, m.list.SetItem(index, i))
+		}
+	}
+
+	return tea.Batch(cmds...)
+}
+
+// loadVersions fills versions menu with the previous values of the shown secret.
+func (m *model) loadVersions() tea.Cmd {
+	i := m.selectedSecret
+	m.versions.Title = "History of " + i.name
+
+	versions, err := m.goph.ListSecretVersions(context.Background(), stringToSecretKind[i.kind], i.name)
+	if err != nil {
+		m.versions.SetItems([]list.Item{})
+		return m.versions.NewStatusMessage(statusMessageStyle("Failed to load history: " + err.Error()))
+	}
+
+	items := []list.Item{}
+	for _, version := range versions {
+		items = append(
+			items,
+			versionItem{
+				revision: version.Revision,
+				modified: version.Modified.Local().Format(time.RFC822),
+			},
+		)
+	}
+
+	return m.versions.SetItems(items)
+}
+
+// loadTrash fills trash menu with the deleted secrets.
+func (m *model) loadTrash() tea.Cmd {
+	secrets, err := m.goph.GetTrash()
+	if err != nil {
+		m.trash.SetItems([]list.Item{})
+		return m.trash.NewStatusMessage(statusMessageStyle("Failed to load trash: " + err.Error()))
+	}
+
+	items := []list.Item{}
+	for _, secret := range secrets {
+		items = append(
+			items,
+			trashItem{
+				name:    secret.Name,
+				kind:    secretKindToString[SecretKind(secret.Kind)],
+				deleted: secret.DeletedAt.Time.Local().Format(time.RFC822),
+			},
+		)
+	}
+
+	return m.trash.SetItems(items)
+}
+
+// loadSessions fills sessions menu with the user active sessions from the server.
+func (m *model) loadSessions() tea.Cmd {
+	pbSessions, err := m.goph.ListSessions(context.Background())
+	if err != nil {
+		m.sessions.SetItems([]list.Item{})
+		return m.sessions.NewStatusMessage(statusMessageStyle("Failed to load sessions: " + err.Error()))
+	}
+
+	items := []list.Item{}
+	for _, pbSession := range pbSessions {
+		items = append(
+			items,
+			sessionItem{
+				id:       pbSession.Id,
+				device:   pbSession.Device,
+				version:  pbSession.Version,
+				ip:       pbSession.Ip,
+				lastSeen: pbSession.LastSeen.AsTime().Local().Format(time.RFC822),
+				current:  pbSession.Current,
+			},
+		)
+	}
+
+	return m.sessions.SetItems(items)
+}
+
 func (m *model) updateInputs(msg tea.Msg) tea.Cmd {
 	cmds := make([]tea.Cmd, len(m.inputs))
 
@@ -305,12 +778,29 @@ func (c *Client) runShell(ctx context.Context) {
 	input.Placeholder = "filepath save to"
 	input.CharLimit = 50
 
+	// Init second factor code input model
+	codeInput := textinput.New()
+	codeInput.Prompt = "> "
+	codeInput.Placeholder = "authenticator or recovery code"
+	codeInput.CharLimit = 20
+
+	// Init new name input model for keeping both conflicted versions
+	nameInput := textinput.New()
+	nameInput.Prompt = "> "
+	nameInput.CharLimit = 32
+
 	// Init list model
 	items := []list.Item{}
 
+	// Secrets are listed without the conflict badges if the conflicts can't be loaded
+	conflicts, err := c.GetSecretConflicts()
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to list user '%s' secret conflicts", c.config.User)
+	}
+
 	secrets, err := c.storage.GetSecretsByUser(ctx, c.config.User)
 	if err != nil {
-		c.log.Error().Err(err).Msg("failed to list user '%s' secrets")
+		c.log.Error().Err(err).Msgf("failed to list user '%s' secrets", c.config.User)
 		return
 	}
 
@@ -321,8 +811,9 @@ func (c *Client) runShell(ctx context.Context) {
 		items = append(
 			items,
 			item{
-				name: secret.Name,
-				kind: secretKindToString[SecretKind(secret.Kind)],
+				name:     secret.Name,
+				kind:     secretKindToString[SecretKind(secret.Kind)],
+				conflict: conflicts[SecretKind(secret.Kind)][secret.Name],
 			},
 		)
 	}
@@ -335,16 +826,49 @@ func (c *Client) runShell(ctx context.Context) {
 
 	// Setup TUI
 	m := model{
-		goph:    c,
-		list:    list.New(items, list.NewDefaultDelegate(), 0, 0),
-		choices: list.New(choices, list.NewDefaultDelegate(), 0, 0),
-		input:   input,
+		goph:     c,
+		list:     list.New(items, list.NewDefaultDelegate(), 0, 0),
+		choices:  list.New(choices, list.NewDefaultDelegate(), 0, 0),
+		sessions: list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0),
+		versions: list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0),
+		trash:    list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0),
+		input:    input,
+
+		codeInput: codeInput,
+		nameInput: nameInput,
 	}
 	m.list.Title = "My Secrets"
 	m.list.AdditionalShortHelpKeys = func() []key.Binding {
 		return []key.Binding{
 			keyMap.Create,
 			keyMap.Delete,
+			keyMap.Sessions,
+			keyMap.Trash,
+		}
+	}
+	m.sessions.Title = "Active sessions"
+	m.sessions.SetFilteringEnabled(false)
+	m.sessions.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{
+			keyMap.Delete,
+			keyMap.RevokeAll,
+			keyMap.Back,
+		}
+	}
+	m.versions.SetFilteringEnabled(false)
+	m.versions.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{
+			keyMap.Restore,
+			keyMap.Back,
+		}
+	}
+	m.trash.Title = "Trash"
+	m.trash.SetFilteringEnabled(false)
+	m.trash.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{
+			keyMap.Restore,
+			keyMap.Purge,
+			keyMap.Back,
 		}
 	}
 	m.choices.Title = "Choose new secret type"
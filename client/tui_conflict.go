@@ -0,0 +1,55 @@
+package client
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	conflictStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			Padding(0, 1)
+	changedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+)
+
+// renderConflict shows both versions of the conflicted secret side by side
+// with the differing lines highlighted.
+func (c *Client) renderConflict(conflict SecretConflict) string {
+	mine, err := c.loadSecretContentFromEntry(conflict.Mine)
+	if err != nil {
+		mine = err.Error()
+	}
+
+	theirs := " Deleted on another device\n"
+	if !conflict.Theirs.Deleted {
+		theirs, err = c.loadSecretContentFromEntry(conflict.Theirs)
+		if err != nil {
+			theirs = err.Error()
+		}
+	}
+
+	mineLines, theirsLines := highlightChanges(strings.Split(mine, "\n"), strings.Split(theirs, "\n"))
+
+	return lipgloss.JoinHorizontal(
+		lipgloss.Top,
+		conflictStyle.Render("Mine\n\n"+strings.Join(mineLines, "\n")),
+		conflictStyle.Render("Theirs\n\n"+strings.Join(theirsLines, "\n")),
+	)
+}
+
+// highlightChanges highlights the lines which differ from the lines of the other version.
+func highlightChanges(a, b []string) ([]string, []string) {
+	highlight := func(lines, other []string) []string {
+		highlighted := make([]string, len(lines))
+		for i, line := range lines {
+			if i >= len(other) || line != other[i] {
+				line = changedStyle.Render(line)
+			}
+			highlighted[i] = line
+		}
+		return highlighted
+	}
+
+	return highlight(a, b), highlight(b, a)
+}
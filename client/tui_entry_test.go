@@ -1,7 +1,6 @@
 package client
 
 import (
-	"database/sql"
 	"os"
 	"testing"
 
@@ -115,15 +114,7 @@ func TestSecretsFromToEntry(t *testing.T) {
 			mockStorage := mock.NewMockQuerier(controller)
 
 			mockStorage.EXPECT().
-				GetSecret(
-					gomock.Any(),
-					gomock.Any(),
-				).
-				Times(1).
-				Return(db.Secret{}, sql.ErrNoRows)
-
-			mockStorage.EXPECT().
-				CreateSecret(
+				UpsertLocalSecret(
 					gomock.Any(),
 					gomock.Any(),
 				).
@@ -3,13 +3,22 @@ package client
 import "github.com/charmbracelet/bubbles/key"
 
 type action struct {
-	Create key.Binding
-	Enter  key.Binding
-	Rename key.Binding
-	Delete key.Binding
-	Save   key.Binding
-	Back   key.Binding
-	Quit   key.Binding
+	Create     key.Binding
+	Enter      key.Binding
+	Rename     key.Binding
+	Delete     key.Binding
+	Save       key.Binding
+	Back       key.Binding
+	Quit       key.Binding
+	Sessions   key.Binding
+	RevokeAll  key.Binding
+	KeepMine   key.Binding
+	KeepTheirs key.Binding
+	KeepBoth   key.Binding
+	History    key.Binding
+	Restore    key.Binding
+	Trash      key.Binding
+	Purge      key.Binding
 }
 
 // Keymap reusable key mappings shared across models
@@ -42,4 +51,40 @@ var keyMap = action{
 		key.WithKeys("ctrl+c", "q"),
 		key.WithHelp("ctrl+c/q", "quit"),
 	),
+	Sessions: key.NewBinding(
+		key.WithKeys("S"),
+		key.WithHelp("S", "sessions"),
+	),
+	RevokeAll: key.NewBinding(
+		key.WithKeys("D"),
+		key.WithHelp("D", "revoke all others"),
+	),
+	KeepMine: key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "keep mine"),
+	),
+	KeepTheirs: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "keep theirs"),
+	),
+	KeepBoth: key.NewBinding(
+		key.WithKeys("b"),
+		key.WithHelp("b", "keep both"),
+	),
+	History: key.NewBinding(
+		key.WithKeys("h"),
+		key.WithHelp("h", "history"),
+	),
+	Restore: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "restore"),
+	),
+	Trash: key.NewBinding(
+		key.WithKeys("T"),
+		key.WithHelp("T", "trash"),
+	),
+	Purge: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "purge everywhere"),
+	),
 }
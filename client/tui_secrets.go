@@ -7,7 +7,6 @@ import (
 
 	"github.com/charmbracelet/bubbles/textinput"
 
-	"gophkeeper/crypto"
 	"gophkeeper/db/db"
 )
 
@@ -123,11 +122,9 @@ func (c *Client) storeSecretFromEntry(kind SecretKind, inputs []textinput.Model)
 		return db.Secret{}, fmt.Errorf("failed to build secret '%s' payload: %w", secretName, err)
 	}
 
-	if c.config.Encrypt {
-		payloadBytes, err = crypto.Encrypt(payloadBytes, []byte(c.config.Key))
-		if err != nil {
-			return db.Secret{}, fmt.Errorf("failed to encrypt secret '%s' payload: %w", secretName, err)
-		}
+	payloadBytes, err = c.seal(payloadBytes, int32(kind), secretName)
+	if err != nil {
+		return db.Secret{}, fmt.Errorf("failed to encrypt secret '%s' payload: %w", secretName, err)
 	}
 
 	dbSecret, err := c.SetSecret(kind, secretName, payloadBytes)
@@ -0,0 +1,616 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"gophkeeper/converter"
+	"gophkeeper/crypto"
+	"gophkeeper/db/db"
+	"gophkeeper/pb"
+)
+
+const vaultSaltLength = 16 // bytes
+
+var (
+	errWrongMasterPassword   = errors.New("wrong master password")
+	errMasterPasswordChanged = errors.New("master password was changed on another device, update the key in the config")
+	errNoVaultKey            = errors.New("vault key is not set up yet, connect to the server once to set it up")
+	errVaultNotEncrypted     = errors.New("secrets are not encrypted, there is no key to replace")
+	errUnsyncedSecrets       = errors.New("some local secrets are not synced, resolve the conflicts and retry")
+	errRekeyInterrupted      = errors.New("master password replacement was interrupted, run rekey again with the same new master password")
+	errSecretEncrypted       = errors.New("secret is encrypted, enable encryption to read it")
+	errSecretNotEncrypted    = errors.New("secret is not encrypted, run the migrate command to encrypt it")
+)
+
+// vaultKDF derives the vault keys of the new users.
+var vaultKDF = crypto.DefaultArgon2idHasher
+
+// unlock derives the vault key the secrets are encrypted with from the master password.
+// Wrong master password is detected with the key check value. Server key params are
+// cached in the local db to unlock offline once the master password is checked.
+func (c *Client) unlock(ctx context.Context) error {
+	if !c.config.Encrypt {
+		return nil
+	}
+
+	cached, cachedErr := c.storage.GetVaultKey(ctx, c.config.User)
+
+	pending, pendingErr := c.storage.GetPendingRekey(ctx, c.config.User)
+	if pendingErr != nil && !errors.Is(pendingErr, sql.ErrNoRows) {
+		return pendingErr
+	}
+
+	vaultKey, err := c.getVaultKey(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The key is replaced on the server when the vault is rekeyed on another device
+	rekeyed := cachedErr == nil && !bytes.Equal(cached.KeyCheck, vaultKey.KeyCheck)
+	// or by this one when the local database didn't follow the server
+	interrupted := pendingErr == nil && bytes.Equal(pending.KeyCheck, vaultKey.KeyCheck)
+
+	key := vaultHasher(vaultKey).Key([]byte(c.config.Key), vaultKey.Salt)
+
+	var unbound bool
+	switch {
+	case crypto.CheckKey(key, vaultKey.KeyCheck, crypto.CurrentVersion):
+	case crypto.CheckKey(key, vaultKey.KeyCheck, crypto.VersionUnbound):
+		// Vault is not migrated to the values bound to their identity yet
+		unbound = true
+	case interrupted:
+		return errRekeyInterrupted
+	case rekeyed:
+		return errMasterPasswordChanged
+	default:
+		return errWrongMasterPassword
+	}
+
+	// Local secrets of the interrupted rekey may be rewrapped only partly
+	if rekeyed || interrupted {
+		err = c.resetLocalSecrets(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to reset local secrets: %w", err)
+		}
+	}
+
+	err = c.saveVaultKey(ctx, vaultKey)
+	if err != nil {
+		return fmt.Errorf("failed to cache vault key: %w", err)
+	}
+
+	c.vaultKey = key
+	c.unboundValues = unbound
+	c.log.Info().Msg("successfully unlocked vault")
+
+	if unbound {
+		c.log.Warn().Msg("secrets are not bound to their names, run the migrate command to bind them")
+	}
+
+	return nil
+}
+
+// resetLocalSecrets drops the local secrets wrapped with the replaced vault key
+// so all of them are pulled again along with the rekey marker. Local changes
+// not synced before the rekey can't be decrypted anymore and are lost.
+func (c *Client) resetLocalSecrets(ctx context.Context) error {
+	dirty, err := c.storage.GetDirtySecrets(ctx, c.config.User)
+	if err != nil {
+		return err
+	}
+	if len(dirty) > 0 {
+		c.log.Warn().Msgf("dropping %v local secrets not synced before the vault was rekeyed", len(dirty))
+	}
+
+	err = c.storage.DeleteUserWithSecrets(ctx, c.config.User)
+	if err != nil {
+		return err
+	}
+
+	err = c.storage.CreateLocalUser(ctx, c.config.User)
+	if err != nil {
+		return err
+	}
+
+	if c.token != "" {
+		c.setTokens(&pb.Token{Value: c.token, Refresh: c.refreshToken})
+	}
+
+	c.log.Info().Msg("vault was rekeyed, local secrets will be pulled again")
+
+	return nil
+}
+
+// getVaultKey returns the key derivation params shared by all the user devices.
+// The first device creates them. Offline the cached params are used.
+func (c *Client) getVaultKey(ctx context.Context) (db.VaultKey, error) {
+	var pbVaultKey *pb.VaultKey
+	var err error
+
+	if c.token != "" {
+		pbVaultKey, err = c.g.GetVaultKey(c.authContext(ctx), &emptypb.Empty{})
+		if status.Code(err) == codes.NotFound {
+			pbVaultKey, err = c.createVaultKey(ctx)
+		}
+	}
+	if c.offline(err) {
+		vaultKey, err := c.storage.GetVaultKey(ctx, c.config.User)
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.VaultKey{}, errNoVaultKey
+		}
+
+		return vaultKey, err
+	}
+	if err != nil {
+		return db.VaultKey{}, fmt.Errorf("failed to get vault key: %w", err)
+	}
+
+	return converter.PBVaultKeyToDBVaultKey(c.config.User, pbVaultKey), nil
+}
+
+func (c *Client) saveVaultKey(ctx context.Context, vaultKey db.VaultKey) error {
+	return c.storage.SaveVaultKey(
+		ctx,
+		db.SaveVaultKeyParams{
+			Owner:       vaultKey.Owner,
+			Salt:        vaultKey.Salt,
+			Memory:      vaultKey.Memory,
+			Iterations:  vaultKey.Iterations,
+			Parallelism: vaultKey.Parallelism,
+			KeyCheck:    vaultKey.KeyCheck,
+		},
+	)
+}
+
+// createVaultKey makes the new key derivation params with a random salt.
+// The server returns the params of another device if it was first.
+func (c *Client) createVaultKey(ctx context.Context) (*pb.VaultKey, error) {
+	vaultKey, _, err := newVaultKey(c.config.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	c.log.Info().Msgf("creating user '%s' vault key", c.config.User)
+
+	return c.g.CreateVaultKey(c.authContext(ctx), vaultKey)
+}
+
+// newVaultKey makes the key derivation params with a random salt and derives the key with them.
+func newVaultKey(masterPassword string) (*pb.VaultKey, []byte, error) {
+	salt := make([]byte, vaultSaltLength)
+
+	_, err := rand.Read(salt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key := vaultKDF.Key([]byte(masterPassword), salt)
+
+	vaultKey := &pb.VaultKey{
+		Salt:        salt,
+		Memory:      vaultKDF.Memory,
+		Iterations:  vaultKDF.Iterations,
+		Parallelism: uint32(vaultKDF.Parallelism),
+		Check:       crypto.KeyCheck(key, crypto.CurrentVersion),
+	}
+
+	return vaultKey, key, nil
+}
+
+func vaultHasher(vaultKey db.VaultKey) crypto.Argon2idHasher {
+	return crypto.Argon2idHasher{
+		Memory:      uint32(vaultKey.Memory),
+		Iterations:  uint32(vaultKey.Iterations),
+		Parallelism: uint8(vaultKey.Parallelism),
+	}
+}
+
+// secretAD is the identity of the secret its value is bound to.
+func (c *Client) secretAD(kind int32, name string) []byte {
+	ad := binary.AppendUvarint(nil, uint64(len(c.config.User)))
+	ad = append(ad, c.config.User...)
+	ad = binary.BigEndian.AppendUint32(ad, uint32(kind))
+
+	return append(ad, name...)
+}
+
+// encrypt seals the secret value with a new data key wrapped with the vault key.
+// The value is bound to the secret so it can't be moved to another one.
+func (c *Client) encrypt(value []byte, kind int32, name string) ([]byte, error) {
+	return crypto.Seal(value, c.vaultKey, c.secretAD(kind, name))
+}
+
+// seal stores the secret value encrypted if the encryption is on, unencrypted otherwise.
+func (c *Client) seal(value []byte, kind int32, name string) ([]byte, error) {
+	if c.config.Encrypt {
+		return c.encrypt(value, kind, name)
+	}
+
+	return crypto.Plain(value), nil
+}
+
+// decrypt returns the plaintext of the stored secret value by its format header.
+// Values of the older formats are decrypted too until the vault is migrated.
+// With the encryption on the unencrypted values are only accepted while the vault
+// is migrated, so the server can't pass its own values for the encrypted ones.
+func (c *Client) decrypt(value []byte, kind int32, name string) ([]byte, error) {
+	version, ok := crypto.FormatVersion(value)
+
+	if !c.config.Encrypt {
+		if !ok {
+			return value, nil
+		}
+		if version != crypto.VersionPlain {
+			return nil, errSecretEncrypted
+		}
+
+		return crypto.OpenPlain(value)
+	}
+
+	if ok && version == crypto.VersionPlain {
+		if !c.plainValues {
+			return nil, errSecretNotEncrypted
+		}
+
+		return crypto.OpenPlain(value)
+	}
+
+	if ok {
+		return crypto.Open(value, c.vaultKey, c.secretAD(kind, name))
+	}
+
+	// Values without the header were encrypted before it was introduced
+	// or stored unencrypted before the encryption was turned on
+	if c.unboundValues {
+		plaintext, err := c.decryptUnbound(value)
+		if err == nil {
+			return plaintext, nil
+		}
+		if !c.headerlessPlain {
+			return nil, err
+		}
+	}
+
+	if !c.plainValues || !c.headerlessPlain {
+		return nil, errSecretNotEncrypted
+	}
+
+	return value, nil
+}
+
+// decryptUnbound decrypts the value sealed before the header was introduced, encrypted
+// with the vault key itself or with the 32 bytes key used as it is before the vault key was derived.
+func (c *Client) decryptUnbound(value []byte) ([]byte, error) {
+	plaintext, err := crypto.OpenUnbound(value, c.vaultKey)
+	if !errors.Is(err, crypto.ErrNotSealed) {
+		return plaintext, err
+	}
+
+	plaintext, err = crypto.Decrypt(value, c.vaultKey)
+	if err != nil && len(c.config.Key) == 32 {
+		return crypto.Decrypt(value, []byte(c.config.Key))
+	}
+
+	return plaintext, err
+}
+
+// rewrap wraps the data key of the secret value with the new vault key.
+// The payload ciphertext stays the same, values of the older formats
+// and the unencrypted ones accepted on migrate are sealed anew.
+// Values that can't be decrypted fail the rewrap.
+func (c *Client) rewrap(value []byte, kind int32, name string, newKey []byte) ([]byte, error) {
+	if len(value) == 0 {
+		return value, nil
+	}
+
+	// The value is checked to be bound to the secret before it is rewrapped
+	_, err := crypto.Open(value, c.vaultKey, c.secretAD(kind, name))
+	if err == nil {
+		return crypto.Rewrap(value, c.vaultKey, newKey)
+	}
+
+	plaintext, err := c.decrypt(value, kind, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return crypto.Seal(plaintext, newKey, c.secretAD(kind, name))
+}
+
+// reseal binds the secret value to the new name.
+func (c *Client) reseal(value []byte, kind int32, name, newName string) ([]byte, error) {
+	plaintext, err := c.decrypt(value, kind, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.seal(plaintext, kind, newName)
+}
+
+// Migrate binds the secret values made before they were bound to their identity
+// and encrypts the ones stored unencrypted before the encryption was turned on.
+// The vault is rekeyed with the same master password: legacy values are sealed anew
+// with the new vault key, which never accepts the values of the older formats.
+// Values without the format header that can't be decrypted are taken for the unencrypted
+// ones only with headerless set, the migration fails on them otherwise.
+// In the end-to-end mode the names of the secrets synced before are sealed too.
+func (c *Client) Migrate(ctx context.Context, headerless bool) error {
+	c.plainValues, c.headerlessPlain = true, headerless
+	defer func() {
+		c.plainValues, c.headerlessPlain = false, false
+	}()
+
+	err := c.Rekey(ctx, c.config.Key)
+	if err != nil || !c.config.E2E {
+		return err
+	}
+
+	return c.sealNames(ctx)
+}
+
+// rekeyer is the local database encrypted with the master password.
+type rekeyer interface {
+	Rekey(masterKey []byte) error
+}
+
+// syncer is the local database saved in the background.
+type syncer interface {
+	Sync() error
+}
+
+// Rekey replaces the master password. Data keys of all the secrets and their versions
+// are wrapped with the vault key derived from the new password, the encrypted values stay
+// the same. Local secrets are synced first so the server has all of them to rewrap.
+//
+// The rekey is marked in the local database before the server replaces the key. If the local
+// database doesn't follow the server, it is finished by the rekey run again with the same
+// new master password or by the unlock with it: the local secrets are pulled again.
+func (c *Client) Rekey(ctx context.Context, newMasterPassword string) error {
+	if !c.config.Encrypt {
+		return errVaultNotEncrypted
+	}
+
+	err := c.authorize(ctx)
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to authorize user '%s'", c.config.User)
+		return err
+	}
+
+	interrupted, err := c.rekeyInterrupted(ctx)
+	if err != nil {
+		return err
+	}
+	if interrupted {
+		return c.finishRekey(ctx, newMasterPassword)
+	}
+
+	err = c.unlock(ctx)
+	if err != nil {
+		return err
+	}
+
+	authCtx := c.authContext(ctx)
+
+	err = c.pull(authCtx)
+	if err == nil {
+		err = c.push(authCtx)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to sync secrets: %w", err)
+	}
+
+	dirty, err := c.storage.GetDirtySecrets(ctx, c.config.User)
+	if err != nil {
+		return err
+	}
+
+	conflicts, err := c.storage.GetSecretConflicts(ctx, c.config.User)
+	if err != nil {
+		return err
+	}
+
+	if len(dirty) > 0 || len(conflicts) > 0 {
+		return errUnsyncedSecrets
+	}
+
+	vaultKey, err := c.storage.GetVaultKey(ctx, c.config.User)
+	if err != nil {
+		return err
+	}
+
+	pbNewVaultKey, newKey, err := newVaultKey(newMasterPassword)
+	if err != nil {
+		return err
+	}
+
+	secrets, err := c.storage.GetSecretsByUser(ctx, c.config.User)
+	if err != nil {
+		return err
+	}
+
+	request := &pb.RekeyRequest{Check: vaultKey.KeyCheck, VaultKey: pbNewVaultKey, Password: c.config.Password}
+
+	// Trashed secrets are rewrapped on the server too and keep their versions
+	for i, secret := range secrets {
+		// Purged secrets have nothing left to rewrap
+		if isPurged(secret) {
+			continue
+		}
+
+		secrets[i].Value, err = c.rewrap(secret.Value, secret.Kind, secret.Name, newKey)
+		if err != nil {
+			return fmt.Errorf("failed to rewrap secret '%s': %w", secret.Name, err)
+		}
+
+		pbSecret, err := c.sealRemote(secrets[i], newKey)
+		if err != nil {
+			return fmt.Errorf("failed to seal secret '%s': %w", secret.Name, err)
+		}
+
+		request.Secrets = append(request.Secrets, pbSecret)
+
+		remoteKind, remoteName := remoteIdentity(secret)
+
+		versions, err := c.g.ListSecretVersions(
+			authCtx,
+			&pb.SecretVersionsRequest{Kind: remoteKind, Name: remoteName},
+		)
+		if err != nil {
+			return fmt.Errorf("failed to list secret '%s' versions: %w", secret.Name, err)
+		}
+
+		for _, version := range versions.Versions {
+			if remoteKind == sealedKind {
+				version.Value, err = c.rewrapRemote(version.Value, remoteName, newKey)
+			} else {
+				version.Value, err = c.rewrap(version.Value, secret.Kind, secret.Name, newKey)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to rewrap secret '%s' version: %w", secret.Name, err)
+			}
+
+			request.Versions = append(
+				request.Versions,
+				&pb.RewrappedVersion{Kind: remoteKind, Name: remoteName, Version: version},
+			)
+		}
+	}
+
+	// The marker has to be saved before the server replaces the key
+	err = c.storage.SavePendingRekey(
+		ctx,
+		db.SavePendingRekeyParams{
+			Owner:    c.config.User,
+			KeyCheck: pbNewVaultKey.Check,
+		},
+	)
+	if local, ok := c.closer.(syncer); ok && err == nil {
+		err = local.Sync()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to mark rekey: %w", err)
+	}
+
+	_, err = c.g.Rekey(authCtx, request)
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to rekey user '%s' vault", c.config.User)
+		return err
+	}
+
+	c.log.Info().Msgf("successfully rekeyed user '%s' vault", c.config.User)
+
+	// The server is rekeyed, the local copy follows it
+	err = c.saveVaultKey(ctx, converter.PBVaultKeyToDBVaultKey(c.config.User, pbNewVaultKey))
+	if err != nil {
+		return fmt.Errorf("failed to cache vault key: %w", err)
+	}
+
+	for _, secret := range secrets {
+		err = c.storage.RewrapLocalSecret(
+			ctx,
+			db.RewrapLocalSecretParams{
+				Owner: secret.Owner,
+				Kind:  secret.Kind,
+				Name:  secret.Name,
+				Value: secret.Value,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("failed to rewrap local secret '%s': %w", secret.Name, err)
+		}
+	}
+
+	c.vaultKey = newKey
+	c.unboundValues = false
+	c.config.Key = newMasterPassword
+
+	if local, ok := c.closer.(rekeyer); ok {
+		err = local.Rekey([]byte(newMasterPassword))
+		if err != nil {
+			return fmt.Errorf("failed to encrypt local database with the new master password: %w", err)
+		}
+	}
+
+	// Rewrapped secrets get new revisions. The rekey is done once the local database
+	// is encrypted with the new master password, the secrets failed to pull here
+	// are pulled again on the next unlock.
+	err = c.pull(authCtx)
+	if err == nil {
+		// Cached versions are wrapped with the replaced key
+		err = c.storage.DeleteSecretVersions(ctx, c.config.User)
+	}
+	if err == nil {
+		err = c.storage.DeletePendingRekey(ctx, c.config.User)
+	}
+	if err != nil {
+		c.log.Warn().Err(err).Msgf("failed to sync user '%s' rekeyed secrets, they are pulled again on the next start", c.config.User)
+	}
+
+	return nil
+}
+
+// rekeyInterrupted reports whether the server vault key was replaced by the rekey
+// the local database didn't follow. The marker of the rekey the server didn't take is dropped.
+func (c *Client) rekeyInterrupted(ctx context.Context) (bool, error) {
+	pending, err := c.storage.GetPendingRekey(ctx, c.config.User)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	vaultKey, err := c.g.GetVaultKey(c.authContext(ctx), &emptypb.Empty{})
+	if err != nil {
+		return false, fmt.Errorf("failed to get vault key: %w", err)
+	}
+
+	if bytes.Equal(pending.KeyCheck, vaultKey.Check) {
+		return true, nil
+	}
+
+	return false, c.storage.DeletePendingRekey(ctx, c.config.User)
+}
+
+// finishRekey finishes the interrupted rekey with its new master password. The local database
+// is encrypted with it before the vault is unlocked, so the rekey marker is kept until then,
+// the local secrets are pulled again.
+func (c *Client) finishRekey(ctx context.Context, newMasterPassword string) error {
+	vaultKey, err := c.getVaultKey(ctx)
+	if err != nil {
+		return err
+	}
+
+	key := vaultHasher(vaultKey).Key([]byte(newMasterPassword), vaultKey.Salt)
+	if !crypto.CheckKey(key, vaultKey.KeyCheck, crypto.CurrentVersion) {
+		return errWrongMasterPassword
+	}
+
+	if local, ok := c.closer.(rekeyer); ok {
+		err = local.Rekey([]byte(newMasterPassword))
+		if err != nil {
+			return fmt.Errorf("failed to encrypt local database with the new master password: %w", err)
+		}
+	}
+
+	c.config.Key = newMasterPassword
+
+	err = c.unlock(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.log.Info().Msgf("finished interrupted rekey of user '%s' vault", c.config.User)
+
+	return c.pull(c.authContext(ctx))
+}
@@ -0,0 +1,474 @@
+package client
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"gophkeeper/converter"
+	"gophkeeper/crypto"
+	"gophkeeper/db/db"
+	"gophkeeper/pb"
+	"gophkeeper/random"
+)
+
+func init() {
+	// Cheap key derivation for the tests
+	vaultKDF = crypto.Argon2idHasher{Memory: 1024, Iterations: 1, Parallelism: 1}
+}
+
+// vaultServer keeps the vault key of the first device.
+type vaultServer struct {
+	pb.GophKeeperClient
+	vaultKey *pb.VaultKey
+}
+
+func (s *vaultServer) GetVaultKey(_ context.Context, _ *emptypb.Empty, _ ...grpc.CallOption) (*pb.VaultKey, error) {
+	if s.vaultKey == nil {
+		return nil, status.Error(codes.NotFound, "vault key is not set up")
+	}
+
+	return s.vaultKey, nil
+}
+
+func (s *vaultServer) CreateVaultKey(_ context.Context, in *pb.VaultKey, _ ...grpc.CallOption) (*pb.VaultKey, error) {
+	if s.vaultKey == nil {
+		s.vaultKey = proto.Clone(in).(*pb.VaultKey)
+	}
+
+	return s.vaultKey, nil
+}
+
+func TestUnlock(t *testing.T) {
+	testOwner := random.RandomOwner()
+	masterPassword := random.RandomString(12)
+	server := &vaultServer{}
+
+	newClient := func(password, token string) *Client {
+		return &Client{
+			config:  Config{User: testOwner, Encrypt: true, Key: password},
+			storage: newTestStorage(t, testOwner),
+			g:       server,
+			token:   token,
+			log:     zerolog.Nop(),
+		}
+	}
+
+	// The first device creates the vault key
+	first := newClient(masterPassword, random.RandomString(32))
+
+	err := first.unlock(context.Background())
+	require.NoError(t, err)
+	require.Len(t, first.vaultKey, 32)
+	require.NotNil(t, server.vaultKey)
+
+	// Other devices derive the same key
+	second := newClient(masterPassword, random.RandomString(32))
+
+	err = second.unlock(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, first.vaultKey, second.vaultKey)
+
+	// Wrong master password is detected up front
+	wrong := newClient(random.RandomString(12), random.RandomString(32))
+
+	err = wrong.unlock(context.Background())
+	require.ErrorIs(t, err, errWrongMasterPassword)
+	require.Nil(t, wrong.vaultKey)
+
+	// Cached key params unlock offline
+	second.token = ""
+	second.vaultKey = nil
+
+	err = second.unlock(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, first.vaultKey, second.vaultKey)
+
+	// Key params are never created offline
+	offline := newClient(masterPassword, "")
+
+	err = offline.unlock(context.Background())
+	require.ErrorIs(t, err, errNoVaultKey)
+}
+
+func TestUnlockUnbound(t *testing.T) {
+	testOwner := random.RandomOwner()
+	masterPassword := random.RandomString(12)
+
+	// Vault made before the values were bound to their identity
+	vaultKey, key, err := newVaultKey(masterPassword)
+	require.NoError(t, err)
+	vaultKey.Check = crypto.KeyCheck(key, crypto.VersionUnbound)
+
+	client := &Client{
+		config:  Config{User: testOwner, Encrypt: true, Key: masterPassword},
+		storage: newTestStorage(t, testOwner),
+		g:       &vaultServer{vaultKey: vaultKey},
+		token:   random.RandomString(32),
+		log:     zerolog.Nop(),
+	}
+
+	err = client.unlock(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, key, client.vaultKey)
+	require.True(t, client.unboundValues)
+}
+
+func TestUnlockRekeyed(t *testing.T) {
+	testOwner := random.RandomOwner()
+	masterPassword := random.RandomString(12)
+	newMasterPassword := random.RandomString(12)
+	server := &vaultServer{}
+
+	client := &Client{
+		config:  Config{User: testOwner, Encrypt: true, Key: masterPassword},
+		storage: newTestStorage(t, testOwner),
+		g:       server,
+		token:   random.RandomString(32),
+		log:     zerolog.Nop(),
+	}
+
+	err := client.unlock(context.Background())
+	require.NoError(t, err)
+
+	err = client.storage.ReplaceLocalSecret(
+		context.Background(),
+		db.ReplaceLocalSecretParams{Owner: testOwner, Kind: 1, Name: "synced", Value: []byte("value"), Revision: 3},
+	)
+	require.NoError(t, err)
+
+	// Another device replaces the master password
+	server.vaultKey, _, err = newVaultKey(newMasterPassword)
+	require.NoError(t, err)
+
+	err = client.unlock(context.Background())
+	require.ErrorIs(t, err, errMasterPasswordChanged)
+
+	// Secrets wrapped with the replaced key are pulled again
+	client.config.Key = newMasterPassword
+
+	err = client.unlock(context.Background())
+	require.NoError(t, err)
+
+	secrets, err := client.storage.GetSecretsByUser(context.Background(), testOwner)
+	require.NoError(t, err)
+	require.Empty(t, secrets)
+
+	tokens, err := client.storage.GetCachedTokens(context.Background(), testOwner)
+	require.NoError(t, err)
+	require.Equal(t, client.token, tokens.Token)
+
+	// Nothing is reset once the new key is cached
+	err = client.storage.ReplaceLocalSecret(
+		context.Background(),
+		db.ReplaceLocalSecretParams{Owner: testOwner, Kind: 1, Name: "synced", Value: []byte("value"), Revision: 4},
+	)
+	require.NoError(t, err)
+
+	err = client.unlock(context.Background())
+	require.NoError(t, err)
+
+	secrets, err = client.storage.GetSecretsByUser(context.Background(), testOwner)
+	require.NoError(t, err)
+	require.Len(t, secrets, 1)
+}
+
+func TestRewrap(t *testing.T) {
+	legacyKey := random.RandomString(32)
+	vaultKey := []byte(random.RandomString(32))
+	newVaultKey := []byte(random.RandomString(32))
+	dataKey := []byte(random.RandomString(32))
+
+	client := Client{
+		config:        Config{User: random.RandomOwner(), Encrypt: true, Key: legacyKey},
+		vaultKey:      vaultKey,
+		unboundValues: true,
+	}
+
+	sealed, err := client.encrypt([]byte("sealed"), 1, "name")
+	require.NoError(t, err)
+
+	// Value sealed with a data key before the header was introduced
+	wrapped, err := crypto.Encrypt(dataKey, vaultKey)
+	require.NoError(t, err)
+
+	payload, err := crypto.Encrypt([]byte("unbound"), dataKey)
+	require.NoError(t, err)
+
+	vaultEncrypted, err := crypto.Encrypt([]byte("vault"), vaultKey)
+	require.NoError(t, err)
+
+	legacyEncrypted, err := crypto.Encrypt([]byte("legacy"), []byte(legacyKey))
+	require.NoError(t, err)
+
+	rekeyed := Client{
+		config:   Config{User: client.config.User, Encrypt: true, Key: random.RandomString(12)},
+		vaultKey: newVaultKey,
+	}
+
+	plain := crypto.Plain([]byte("plain"))
+
+	// Unencrypted values are only sealed on migrate
+	_, err = client.rewrap(plain, 1, "name", newVaultKey)
+	require.ErrorIs(t, err, errSecretNotEncrypted)
+
+	// Values without the header that can't be decrypted are never sealed unless the user says so
+	foreign, err := crypto.Encrypt([]byte("foreign"), []byte(random.RandomString(32)))
+	require.NoError(t, err)
+
+	client.plainValues = true
+
+	_, err = client.rewrap(foreign, 1, "name", newVaultKey)
+	require.Error(t, err)
+
+	client.headerlessPlain = true
+
+	for value, plaintext := range map[string]string{
+		string(sealed):                      "sealed",
+		string(append(wrapped, payload...)): "unbound",
+		string(vaultEncrypted):              "vault",
+		string(legacyEncrypted):             "legacy",
+		string(plain):                       "plain",
+		"unencrypted":                       "unencrypted",
+	} {
+		rewrapped, err := client.rewrap([]byte(value), 1, "name", newVaultKey)
+		require.NoError(t, err)
+
+		// Every value is bound to the secret after the rewrap
+		opened, err := rekeyed.decrypt(rewrapped, 1, "name")
+		require.NoError(t, err)
+		require.Equal(t, []byte(plaintext), opened)
+
+		_, err = rekeyed.decrypt(rewrapped, 1, "another name")
+		require.Error(t, err)
+
+		_, err = client.decrypt(rewrapped, 1, "name")
+		require.Error(t, err)
+	}
+
+	// Only the data key is rewrapped
+	rewrapped, err := client.rewrap(sealed, 1, "name", newVaultKey)
+	require.NoError(t, err)
+	require.Equal(t, sealed[len(sealed)-len("sealed")-28:], rewrapped[len(rewrapped)-len("sealed")-28:])
+
+	// Value of another secret is never rewrapped
+	_, err = client.rewrap(sealed, 1, "another name", newVaultKey)
+	require.Error(t, err)
+
+	// Tombstones have nothing to rewrap
+	rewrapped, err = client.rewrap([]byte{}, 1, "name", newVaultKey)
+	require.NoError(t, err)
+	require.Empty(t, rewrapped)
+}
+
+func TestDecryptBound(t *testing.T) {
+	vaultKey := []byte(random.RandomString(32))
+
+	client := Client{
+		config:   Config{User: random.RandomOwner(), Encrypt: true, Key: random.RandomString(12)},
+		vaultKey: vaultKey,
+	}
+
+	card, err := client.encrypt([]byte("card"), int32(SecretCard), "bank")
+	require.NoError(t, err)
+
+	plaintext, err := client.decrypt(card, int32(SecretCard), "bank")
+	require.NoError(t, err)
+	require.Equal(t, []byte("card"), plaintext)
+
+	// Values swapped by the server are rejected
+	_, err = client.decrypt(card, int32(SecretCreds), "bank")
+	require.Error(t, err)
+
+	_, err = client.decrypt(card, int32(SecretCard), "mail")
+	require.Error(t, err)
+
+	other := Client{
+		config:   Config{User: random.RandomOwner(), Encrypt: true, Key: client.config.Key},
+		vaultKey: vaultKey,
+	}
+
+	_, err = other.decrypt(card, int32(SecretCard), "bank")
+	require.Error(t, err)
+
+	// Values of the older formats are rejected once the vault is migrated
+	unbound, err := crypto.Encrypt([]byte("card"), vaultKey)
+	require.NoError(t, err)
+
+	_, err = client.decrypt(unbound, int32(SecretCard), "bank")
+	require.Error(t, err)
+}
+
+func TestDecryptMixed(t *testing.T) {
+	client := Client{
+		config:   Config{User: random.RandomOwner(), Encrypt: true, Key: random.RandomString(12)},
+		vaultKey: []byte(random.RandomString(32)),
+	}
+
+	sealed, err := client.seal([]byte("sealed"), int32(SecretText), "name")
+	require.NoError(t, err)
+
+	// Values stored before the encryption was turned on are only read on migrate
+	for _, value := range []string{string(crypto.Plain([]byte("plain"))), "legacy"} {
+		_, err := client.decrypt([]byte(value), int32(SecretText), "name")
+		require.ErrorIs(t, err, errSecretNotEncrypted)
+	}
+
+	client.plainValues = true
+
+	_, err = client.decrypt([]byte("legacy"), int32(SecretText), "name")
+	require.ErrorIs(t, err, errSecretNotEncrypted)
+
+	client.headerlessPlain = true
+
+	for value, plaintext := range map[string]string{
+		string(sealed):                        "sealed",
+		string(crypto.Plain([]byte("plain"))): "plain",
+		"legacy":                              "legacy",
+	} {
+		opened, err := client.decrypt([]byte(value), int32(SecretText), "name")
+		require.NoError(t, err)
+		require.Equal(t, []byte(plaintext), opened)
+	}
+
+	// Sealed value of another secret is never taken for the unencrypted one
+	_, err = client.decrypt(sealed, int32(SecretText), "another name")
+	require.Error(t, err)
+
+	// New values are stored unencrypted once the encryption is turned off
+	client.config.Encrypt = false
+
+	plain, err := client.seal([]byte("plain"), int32(SecretText), "name")
+	require.NoError(t, err)
+	require.Equal(t, crypto.Plain([]byte("plain")), plain)
+
+	_, err = client.decrypt(sealed, int32(SecretText), "name")
+	require.ErrorIs(t, err, errSecretEncrypted)
+
+	for value, plaintext := range map[string]string{
+		string(plain): "plain",
+		"legacy":      "legacy",
+	} {
+		opened, err := client.decrypt([]byte(value), int32(SecretText), "name")
+		require.NoError(t, err)
+		require.Equal(t, []byte(plaintext), opened)
+	}
+}
+
+func TestDecryptLegacyKey(t *testing.T) {
+	legacyKey := random.RandomString(32)
+	vaultKey := []byte(random.RandomString(32))
+
+	client := Client{
+		config:        Config{User: random.RandomOwner(), Encrypt: true, Key: legacyKey},
+		vaultKey:      vaultKey,
+		unboundValues: true,
+	}
+
+	encrypted, err := client.encrypt([]byte("new"), 1, "name")
+	require.NoError(t, err)
+
+	legacyEncrypted, err := crypto.Encrypt([]byte("old"), []byte(legacyKey))
+	require.NoError(t, err)
+
+	plaintext, err := client.decrypt(encrypted, 1, "name")
+	require.NoError(t, err)
+	require.Equal(t, []byte("new"), plaintext)
+
+	plaintext, err = client.decrypt(legacyEncrypted, 1, "name")
+	require.NoError(t, err)
+	require.Equal(t, []byte("old"), plaintext)
+
+	_, err = crypto.Decrypt(encrypted, []byte(legacyKey))
+	require.Error(t, err)
+}
+
+// rekeyedServer has no secret changes for the devices to pull.
+type rekeyedServer struct {
+	*vaultServer
+}
+
+func (s rekeyedServer) GetChanges(_ context.Context, _ *pb.ChangesRequest, _ ...grpc.CallOption) (*pb.Changes, error) {
+	return &pb.Changes{}, nil
+}
+
+func TestRekeyInterrupted(t *testing.T) {
+	ctx := context.Background()
+	testOwner := random.RandomOwner()
+	masterPassword := random.RandomString(12)
+	newMasterPassword := random.RandomString(12)
+	server := &vaultServer{}
+
+	client := &Client{
+		config:  Config{User: testOwner, Encrypt: true, Key: masterPassword},
+		storage: newTestStorage(t, testOwner),
+		g:       rekeyedServer{server},
+		token:   random.RandomString(32),
+		log:     zerolog.Nop(),
+	}
+
+	err := client.unlock(ctx)
+	require.NoError(t, err)
+
+	// Marker of the rekey the server didn't take is dropped
+	err = client.storage.SavePendingRekey(ctx, db.SavePendingRekeyParams{Owner: testOwner, KeyCheck: []byte("check")})
+	require.NoError(t, err)
+
+	interrupted, err := client.rekeyInterrupted(ctx)
+	require.NoError(t, err)
+	require.False(t, interrupted)
+
+	_, err = client.storage.GetPendingRekey(ctx, testOwner)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// The server replaced the key but the local secrets were rewrapped only partly
+	server.vaultKey, _, err = newVaultKey(newMasterPassword)
+	require.NoError(t, err)
+
+	err = client.storage.SavePendingRekey(ctx, db.SavePendingRekeyParams{Owner: testOwner, KeyCheck: server.vaultKey.Check})
+	require.NoError(t, err)
+
+	err = client.saveVaultKey(ctx, converter.PBVaultKeyToDBVaultKey(testOwner, server.vaultKey))
+	require.NoError(t, err)
+
+	err = client.storage.ReplaceLocalSecret(
+		ctx,
+		db.ReplaceLocalSecretParams{Owner: testOwner, Kind: 1, Name: "synced", Value: []byte("value"), Revision: 3},
+	)
+	require.NoError(t, err)
+
+	err = client.unlock(ctx)
+	require.ErrorIs(t, err, errRekeyInterrupted)
+
+	interrupted, err = client.rekeyInterrupted(ctx)
+	require.NoError(t, err)
+	require.True(t, interrupted)
+
+	err = client.finishRekey(ctx, random.RandomString(12))
+	require.ErrorIs(t, err, errWrongMasterPassword)
+	require.Equal(t, masterPassword, client.config.Key)
+
+	// The rekey is finished with its new master password, the secrets are pulled again
+	err = client.finishRekey(ctx, newMasterPassword)
+	require.NoError(t, err)
+	require.Equal(t, newMasterPassword, client.config.Key)
+	require.NotNil(t, client.vaultKey)
+
+	secrets, err := client.storage.GetSecretsByUser(ctx, testOwner)
+	require.NoError(t, err)
+	require.Empty(t, secrets)
+
+	_, err = client.storage.GetPendingRekey(ctx, testOwner)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	err = client.unlock(ctx)
+	require.NoError(t, err)
+}
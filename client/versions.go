@@ -0,0 +1,181 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"gophkeeper/db/db"
+	"gophkeeper/pb"
+)
+
+// offline reports whether the server can't be used for the request.
+func (c *Client) offline(err error) bool {
+	return c.token == "" || status.Code(err) == codes.Unavailable
+}
+
+// ListSecretVersions returns the latest previous values of the secret.
+// Server versions are cached in the local db to be available offline.
+func (c *Client) ListSecretVersions(ctx context.Context, kind SecretKind, name string) ([]db.SecretVersion, error) {
+	var pbVersions *pb.SecretVersions
+	var remoteKind int32
+	var remoteName string
+	var err error
+
+	if c.token != "" {
+		remoteKind, remoteName, err = c.getRemoteIdentity(ctx, kind, name)
+		if err != nil {
+			return nil, err
+		}
+
+		pbVersions, err = c.g.ListSecretVersions(
+			c.authContext(ctx),
+			&pb.SecretVersionsRequest{
+				Kind: remoteKind,
+				Name: remoteName,
+			},
+		)
+	}
+	if c.offline(err) {
+		c.log.Warn().Msgf("server unavailable...listing secret '%s' local versions", name)
+
+		return c.storage.GetSecretVersions(
+			ctx,
+			db.GetSecretVersionsParams{
+				Owner: c.config.User,
+				Kind:  int32(kind),
+				Name:  name,
+				Limit: c.config.Versions,
+			},
+		)
+	}
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to list user '%s' secret '%s' versions", c.config.User, name)
+		return nil, err
+	}
+
+	versions := []db.SecretVersion{}
+	for _, pbVersion := range pbVersions.Versions {
+		version := db.SecretVersion{
+			Owner:    c.config.User,
+			Kind:     int32(kind),
+			Name:     name,
+			Value:    pbVersion.Value,
+			Modified: pbVersion.Modified.AsTime(),
+			Revision: pbVersion.Revision,
+		}
+
+		if remoteKind == sealedKind {
+			sealed, err := c.openRemote(pbVersion.Value, remoteName)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open secret '%s' version %v: %w", name, version.Revision, err)
+			}
+
+			version.Value = sealed.Value
+		}
+
+		err := c.storage.AddSecretVersion(
+			ctx,
+			db.AddSecretVersionParams{
+				Owner:    version.Owner,
+				Kind:     version.Kind,
+				Name:     version.Name,
+				Value:    version.Value,
+				Modified: version.Modified,
+				Revision: version.Revision,
+			},
+		)
+		if err != nil {
+			c.log.Warn().Err(err).Msgf("failed to cache secret '%s' version %v", name, version.Revision)
+		}
+
+		versions = append(versions, version)
+	}
+
+	return versions, nil
+}
+
+// RestoreSecretVersion replaces the secret value with the previous one.
+// Offline the version is restored as a local change to be pushed on the next sync.
+func (c *Client) RestoreSecretVersion(ctx context.Context, kind SecretKind, name string, revision int64) error {
+	var pbSecret *pb.Secret
+	var remoteKind int32
+	var remoteName string
+	var err error
+
+	if c.token != "" {
+		remoteKind, remoteName, err = c.getRemoteIdentity(ctx, kind, name)
+		if err != nil {
+			return err
+		}
+
+		pbSecret, err = c.g.RestoreSecretVersion(
+			c.authContext(ctx),
+			&pb.RestoreVersionRequest{
+				Kind:     remoteKind,
+				Name:     remoteName,
+				Revision: revision,
+			},
+		)
+	}
+	if c.offline(err) {
+		return c.restoreLocalVersion(ctx, kind, name, revision)
+	}
+	if err != nil {
+		c.log.Error().Err(err).Msgf("failed to restore user '%s' secret '%s' version", c.config.User, name)
+		return err
+	}
+
+	restored, err := c.fromRemote(pbSecret)
+	if err != nil {
+		return err
+	}
+
+	err = c.applyRemoteSecret(ctx, restored)
+	if err != nil {
+		return err
+	}
+
+	c.log.Info().Msgf("successfully restored user '%s' secret '%s' to revision %v", c.config.User, name, revision)
+
+	return nil
+}
+
+// restoreLocalVersion restores the cached secret version as a local change.
+func (c *Client) restoreLocalVersion(ctx context.Context, kind SecretKind, name string, revision int64) error {
+	version, err := c.storage.GetSecretVersion(
+		ctx,
+		db.GetSecretVersionParams{
+			Owner:    c.config.User,
+			Kind:     int32(kind),
+			Name:     name,
+			Revision: revision,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to get secret '%s' version %v: %w", name, revision, err)
+	}
+
+	now := time.Now()
+	_, err = c.storage.UpsertLocalSecret(
+		ctx,
+		db.UpsertLocalSecretParams{
+			Owner:    c.config.User,
+			Kind:     int32(kind),
+			Name:     name,
+			Value:    version.Value,
+			Created:  now,
+			Modified: now,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to restore secret '%s' version %v: %w", name, revision, err)
+	}
+
+	c.log.Info().Msgf("restored user '%s' secret '%s' to revision %v locally", c.config.User, name, revision)
+
+	return nil
+}
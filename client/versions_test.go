@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"gophkeeper/db/db"
+	"gophkeeper/db/mock"
+	"gophkeeper/pb"
+	"gophkeeper/random"
+)
+
+// versionsServer serves the secret versions.
+type versionsServer struct {
+	pb.GophKeeperClient
+	versions []*pb.SecretVersion
+}
+
+func (s *versionsServer) ListSecretVersions(_ context.Context, _ *pb.SecretVersionsRequest, _ ...grpc.CallOption) (*pb.SecretVersions, error) {
+	return &pb.SecretVersions{Versions: s.versions}, nil
+}
+
+func TestListSecretVersions(t *testing.T) {
+	testOwner := random.RandomOwner()
+	testName := random.RandomString(10)
+
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockQuerier(controller)
+
+	mockStorage.EXPECT().
+		GetSecret(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.Secret{Owner: testOwner, Kind: int32(SecretText), Name: testName}, nil)
+
+	// Server versions are cached
+	mockStorage.EXPECT().
+		AddSecretVersion(gomock.Any(), gomock.Any()).
+		Times(2).
+		Return(nil)
+
+	client := Client{
+		config:  Config{User: testOwner, Versions: 5},
+		storage: mockStorage,
+		token:   random.RandomString(32),
+		g: &versionsServer{
+			versions: []*pb.SecretVersion{
+				{Value: []byte("second"), Modified: timestamppb.Now(), Revision: 7},
+				{Value: []byte("first"), Modified: timestamppb.Now(), Revision: 3},
+			},
+		},
+	}
+
+	versions, err := client.ListSecretVersions(context.Background(), SecretText, testName)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	require.Equal(t, testName, versions[0].Name)
+	require.Equal(t, int64(7), versions[0].Revision)
+
+	// Local versions are listed offline
+	mockStorage.EXPECT().
+		GetSecretVersions(
+			gomock.Any(),
+			db.GetSecretVersionsParams{
+				Owner: testOwner,
+				Kind:  int32(SecretText),
+				Name:  testName,
+				Limit: 5,
+			},
+		).
+		Times(1).
+		Return([]db.SecretVersion{{Revision: 3}}, nil)
+
+	client.token = ""
+
+	versions, err = client.ListSecretVersions(context.Background(), SecretText, testName)
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+}
+
+func TestRestoreLocalSecretVersion(t *testing.T) {
+	testOwner := random.RandomOwner()
+	testName := random.RandomString(10)
+
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockQuerier(controller)
+
+	mockStorage.EXPECT().
+		GetSecretVersion(
+			gomock.Any(),
+			db.GetSecretVersionParams{
+				Owner:    testOwner,
+				Kind:     int32(SecretText),
+				Name:     testName,
+				Revision: 3,
+			},
+		).
+		Times(1).
+		Return(db.SecretVersion{Value: []byte("first"), Modified: time.Now(), Revision: 3}, nil)
+
+	mockStorage.EXPECT().
+		UpsertLocalSecret(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, params db.UpsertLocalSecretParams) (db.Secret, error) {
+			require.Equal(t, testName, params.Name)
+			require.Equal(t, []byte("first"), params.Value)
+			return db.Secret{}, nil
+		})
+
+	client := Client{
+		config:  Config{User: testOwner},
+		storage: mockStorage,
+	}
+
+	err := client.RestoreSecretVersion(context.Background(), SecretText, testName, 3)
+	require.NoError(t, err)
+}
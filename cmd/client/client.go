@@ -1,8 +1,15 @@
 package main
 
 import (
+	"bufio"
+	"context"
 	"flag"
 	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
 
 	"gophkeeper/client"
 	"gophkeeper/logger"
@@ -16,6 +23,11 @@ var (
 func main() {
 	v := flag.Bool("v", false, "Gophkeeper version")
 	configFilePath := flag.String("c", "", "Client config file path")
+	headerless := flag.Bool(
+		"headerless-plain",
+		false,
+		"Migrate the values stored without the format header that can't be decrypted as unencrypted",
+	)
 	flag.Parse()
 
 	if *v {
@@ -23,6 +35,13 @@ func main() {
 		return
 	}
 
+	switch flag.Arg(0) {
+	case "", "passwd", "rekey", "migrate", "delete-account", "totp", "audit":
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command '%s'\n", flag.Arg(0))
+		os.Exit(2)
+	}
+
 	config, err := client.LoadConfig(*configFilePath)
 	if err != nil {
 		panic(err)
@@ -33,11 +52,211 @@ func main() {
 		panic(err)
 	}
 
+	client.Version = version
+
 	client, err := client.NewClient(config, logger)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to create new client")
-		return
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	client.SetCodePrompt(func() (string, error) { return readLine("Two-factor authentication code: ") })
+
+	switch flag.Arg(0) {
+	case "passwd":
+		err = passwd(client)
+	case "rekey":
+		err = rekey(client)
+	case "migrate":
+		err = migrate(client, *headerless)
+	case "totp":
+		err = enrollTOTP(client)
+	case "audit":
+		err = listAuditEvents(client)
+	case "delete-account":
+		err = deleteAccount(client, config.User)
+	default:
+		err = client.Run()
+	}
+
+	// Encrypted local database is saved on close
+	if closeErr := client.Close(); closeErr != nil {
+		logger.Error().Err(closeErr).Msg("failed to close local database")
+		if err == nil {
+			err = closeErr
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// passwd changes the user password. The current password is taken from the config.
+func passwd(c *client.Client) error {
+	newPassword, err := readPassword("New password: ")
+	if err != nil {
+		return err
+	}
+
+	confirmation, err := readPassword("Repeat new password: ")
+	if err != nil {
+		return err
+	}
+
+	if newPassword != confirmation {
+		return fmt.Errorf("passwords do not match")
+	}
+
+	err = c.ChangePassword(context.Background(), newPassword)
+	if err != nil {
+		return fmt.Errorf("failed to change password: %w", err)
+	}
+
+	fmt.Println("Password changed. Other sessions are logged out. Update the password in your config.")
+
+	return nil
+}
+
+// rekey replaces the master password the secrets are encrypted with.
+// The current one is taken from the config.
+func rekey(c *client.Client) error {
+	newKey, err := readPassword("New master password: ")
+	if err != nil {
+		return err
+	}
+
+	if newKey == "" {
+		return fmt.Errorf("master password is empty")
+	}
+
+	confirmation, err := readPassword("Repeat new master password: ")
+	if err != nil {
+		return err
+	}
+
+	if newKey != confirmation {
+		return fmt.Errorf("master passwords do not match")
+	}
+
+	err = c.Rekey(context.Background(), newKey)
+	if err != nil {
+		return fmt.Errorf("failed to replace master password: %w", err)
+	}
+
+	fmt.Println("Master password replaced. Update the key in the config of all your devices.")
+
+	return nil
+}
+
+// migrate seals the unencrypted secrets and the ones made by the older versions in the current format.
+// Values without the format header are taken for the unencrypted ones only if the user says so.
+func migrate(c *client.Client, headerless bool) error {
+	err := c.Migrate(context.Background(), headerless)
+	if err != nil {
+		return fmt.Errorf("failed to migrate secrets: %w", err)
+	}
+
+	fmt.Println("Secrets migrated. All of them are encrypted, values of the older formats are not accepted anymore.")
+
+	return nil
+}
+
+// deleteAccount deletes the user account after the user name is typed in to confirm.
+func deleteAccount(c *client.Client, user string) error {
+	answer, err := readLine(fmt.Sprintf("All secrets of user '%s' will be deleted. Type the user name to confirm: ", user))
+	if err != nil {
+		return err
+	}
+
+	if answer != user {
+		return fmt.Errorf("user name does not match...aborting")
+	}
+
+	err = c.DeleteAccount(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to delete account: %w", err)
+	}
+
+	fmt.Println("Account deleted.")
+
+	return nil
+}
+
+// enrollTOTP enables two-factor authentication for the user.
+func enrollTOTP(c *client.Client) error {
+	ctx := context.Background()
+
+	enrollment, err := c.EnrollTOTP(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to enroll two-factor authentication: %w", err)
+	}
+
+	fmt.Printf("Add this URI to your authenticator app:\n\n%s\n\n", enrollment.Uri)
+	fmt.Printf("Or enter the secret manually: %s\n\n", enrollment.Secret)
+	fmt.Println("Recovery codes (each could be used once instead of the code, keep them safe):")
+	for _, code := range enrollment.RecoveryCodes {
+		fmt.Printf("  %s\n", code)
+	}
+	fmt.Println()
+
+	code, err := readLine("Enter the code from the app to confirm: ")
+	if err != nil {
+		return err
+	}
+
+	err = c.ConfirmTOTP(ctx, code)
+	if err != nil {
+		return fmt.Errorf("failed to confirm two-factor authentication: %w", err)
+	}
+
+	fmt.Println("Two-factor authentication enabled.")
+
+	return nil
+}
+
+// listAuditEvents prints the latest user account activity.
+func listAuditEvents(c *client.Client) error {
+	events, err := c.ListAuditEvents(context.Background(), 0)
+	if err != nil {
+		return fmt.Errorf("failed to list audit events: %w", err)
+	}
+
+	for _, event := range events {
+		line := fmt.Sprintf("%s  %-16s  %s", event.Created.AsTime().Local().Format(time.RFC3339), event.Type, event.Peer)
+		if event.SecretName != "" {
+			line += fmt.Sprintf("  %s secret '%s'", client.SecretKind(event.SecretKind), event.SecretName)
+		}
+
+		fmt.Println(line)
+	}
+
+	return nil
+}
+
+var stdin = bufio.NewReader(os.Stdin)
+
+func readLine(prompt string) (string, error) {
+	fmt.Print(prompt)
+
+	line, err := stdin.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(line), nil
+}
+
+func readPassword(prompt string) (string, error) {
+	fmt.Print(prompt)
+	defer fmt.Println()
+
+	password, err := term.ReadPassword(int(os.Stdin.Fd()))
+	if err != nil {
+		return "", err
 	}
 
-	client.Run()
+	return string(password), nil
 }
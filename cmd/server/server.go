@@ -2,24 +2,44 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"flag"
+	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
 
+	"gophkeeper/audit"
+	"gophkeeper/db/db"
 	"gophkeeper/logger"
 	"gophkeeper/server"
+	"gophkeeper/token"
 )
 
 func main() {
 	configFilePath := flag.String("c", "", "Server config file path")
 	flag.Parse()
 
+	switch flag.Arg(0) {
+	case "", "audit":
+	case "keygen":
+		keygen()
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command '%s'\n", flag.Arg(0))
+		os.Exit(2)
+	}
+
 	config, err := server.LoadConfig(*configFilePath)
 	if err != nil {
 		panic(err)
 	}
 
+	if flag.Arg(0) == "audit" {
+		auditCommand(config, flag.Arg(1))
+		return
+	}
+
 	logger := logger.New(config.Environment)
 
 	server, err := server.NewServer(config, logger)
@@ -40,3 +60,58 @@ func main() {
 	cancel()
 	server.Stop()
 }
+
+// keygen prints a new token signing key to be added to the keyring.
+// Its public key goes to stderr so it could be handed out to clients.
+func keygen() {
+	key, err := token.GenerateKey()
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(key)
+	fmt.Fprintf(os.Stderr, "public key: %s\n", key.Public())
+}
+
+// auditCommand runs audit log subcommands.
+func auditCommand(config server.Config, subcommand string) {
+	switch subcommand {
+	case "keygen":
+		auditKeygen()
+		return
+	case "verify":
+	default:
+		fmt.Fprintf(os.Stderr, "unknown audit command '%s', supported: verify, keygen\n", subcommand)
+		os.Exit(2)
+	}
+
+	key, err := audit.ParseKey(config.AuditKey)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s, please set the audit key the log was recorded with\n", err)
+		os.Exit(2)
+	}
+
+	pool, err := sql.Open("postgres", config.DSN)
+	if err != nil {
+		panic(err)
+	}
+	defer pool.Close()
+
+	verified, err := audit.Verify(context.Background(), db.New(pool), key)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "audit log is broken after %d valid events: %s\n", verified, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("audit log is intact: %d events verified\n", verified)
+}
+
+// auditKeygen prints a new audit log key.
+func auditKeygen() {
+	key, err := audit.GenerateKey()
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(key)
+}
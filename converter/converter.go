@@ -16,6 +16,7 @@ func DBSecretToPBSecret(secret db.Secret) *pb.Secret {
 		Created:  timestamppb.New(secret.Created),
 		Modified: timestamppb.New(secret.Modified),
 		Deleted:  secret.Deleted,
+		Revision: secret.Revision,
 	}
 }
 
@@ -28,5 +29,57 @@ func PBSecretToDBSecret(secret *pb.Secret) db.Secret {
 		Created: secret.Created.AsTime(),
 		Modified: secret.Modified.AsTime(),
 		Deleted: secret.Deleted,
+		Revision: secret.Revision,
+	}
+}
+
+func DBSessionToPBSession(session db.Session) *pb.Session {
+	return &pb.Session{
+		Id:       session.ID,
+		Device:   session.Device,
+		Version:  session.ClientVersion,
+		Ip:       session.Ip,
+		Created:  timestamppb.New(session.Created),
+		LastSeen: timestamppb.New(session.LastSeen),
+	}
+}
+
+func DBAuditEventToPBAuditEvent(event db.AuditEvent) *pb.AuditEvent {
+	return &pb.AuditEvent{
+		Id:         event.ID,
+		Type:       event.Type,
+		Peer:       event.Peer,
+		SecretKind: event.SecretKind,
+		SecretName: event.SecretName,
+		Created:    timestamppb.New(event.Created),
+	}
+}
+
+func DBSecretVersionToPBSecretVersion(version db.SecretVersion) *pb.SecretVersion {
+	return &pb.SecretVersion{
+		Value:    version.Value,
+		Modified: timestamppb.New(version.Modified),
+		Revision: version.Revision,
+	}
+}
+
+func DBVaultKeyToPBVaultKey(key db.VaultKey) *pb.VaultKey {
+	return &pb.VaultKey{
+		Salt:        key.Salt,
+		Memory:      uint32(key.Memory),
+		Iterations:  uint32(key.Iterations),
+		Parallelism: uint32(key.Parallelism),
+		Check:       key.KeyCheck,
+	}
+}
+
+func PBVaultKeyToDBVaultKey(owner string, key *pb.VaultKey) db.VaultKey {
+	return db.VaultKey{
+		Owner:       owner,
+		Salt:        key.Salt,
+		Memory:      int64(key.Memory),
+		Iterations:  int64(key.Iterations),
+		Parallelism: int32(key.Parallelism),
+		KeyCheck:    key.Check,
 	}
 }
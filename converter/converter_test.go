@@ -63,6 +63,7 @@ func TestDBSecretToPBSecret(t *testing.T) {
 				Created: now,
 				Modified: now,
 				Deleted: false,
+				Revision: 42,
 			}
 
 			pbSecret := DBSecretToPBSecret(testDBSecret)
@@ -71,6 +72,7 @@ func TestDBSecretToPBSecret(t *testing.T) {
 			require.Equal(t, pbSecret.Name, testDBSecret.Name)
 			require.Equal(t, pbSecret.Value, testDBSecret.Value)
 			require.Equal(t, pbSecret.Created.AsTime(), testDBSecret.Created.UTC())
+			require.Equal(t, pbSecret.Revision, testDBSecret.Revision)
 		})
 	}
 }
@@ -88,6 +90,7 @@ func TestPBSecretToBBSecret(t *testing.T) {
 				Created:  timestamppb.New(now),
 				Modified: timestamppb.New(now),
 				Deleted:  tt.deleted,
+				Revision: 42,
 			}
 
 			dbSecret := PBSecretToDBSecret(testPBSecret)
@@ -96,6 +99,83 @@ func TestPBSecretToBBSecret(t *testing.T) {
 			require.Equal(t, dbSecret.Name, testPBSecret.Name)
 			require.Equal(t, dbSecret.Value, testPBSecret.Value)
 			require.Equal(t, dbSecret.Created, testPBSecret.Created.AsTime())
+			require.Equal(t, dbSecret.Revision, testPBSecret.Revision)
 		})
 	}
 }
+
+func TestDBSessionToPBSession(t *testing.T) {
+	now := time.Now()
+
+	testDBSession := db.Session{
+		ID:            random.RandomString(32),
+		Username:      random.RandomOwner(),
+		Device:        random.RandomString(10),
+		ClientVersion: "0.0.1",
+		Ip:            "127.0.0.1:54321",
+		Created:       now,
+		LastSeen:      now,
+	}
+
+	pbSession := DBSessionToPBSession(testDBSession)
+	require.Equal(t, pbSession.Id, testDBSession.ID)
+	require.Equal(t, pbSession.Device, testDBSession.Device)
+	require.Equal(t, pbSession.Version, testDBSession.ClientVersion)
+	require.Equal(t, pbSession.Ip, testDBSession.Ip)
+	require.Equal(t, pbSession.LastSeen.AsTime(), testDBSession.LastSeen.UTC())
+	require.False(t, pbSession.Current)
+}
+
+func TestDBAuditEventToPBAuditEvent(t *testing.T) {
+	testDBAuditEvent := db.AuditEvent{
+		ID:         1,
+		Type:       "secret_created",
+		Username:   random.RandomOwner(),
+		Peer:       "127.0.0.1:54321",
+		SecretKind: 1,
+		SecretName: random.RandomString(10),
+		Created:    time.Now(),
+	}
+
+	pbAuditEvent := DBAuditEventToPBAuditEvent(testDBAuditEvent)
+	require.Equal(t, pbAuditEvent.Id, testDBAuditEvent.ID)
+	require.Equal(t, pbAuditEvent.Type, testDBAuditEvent.Type)
+	require.Equal(t, pbAuditEvent.Peer, testDBAuditEvent.Peer)
+	require.Equal(t, pbAuditEvent.SecretKind, testDBAuditEvent.SecretKind)
+	require.Equal(t, pbAuditEvent.SecretName, testDBAuditEvent.SecretName)
+	require.Equal(t, pbAuditEvent.Created.AsTime(), testDBAuditEvent.Created.UTC())
+}
+
+func TestDBSecretVersionToPBSecretVersion(t *testing.T) {
+	testDBSecretVersion := db.SecretVersion{
+		Owner:    random.RandomOwner(),
+		Kind:     random.RandomSecretKind(),
+		Name:     random.RandomString(10),
+		Value:    []byte(random.RandomString(100)),
+		Modified: time.Now(),
+		Revision: 42,
+	}
+
+	pbSecretVersion := DBSecretVersionToPBSecretVersion(testDBSecretVersion)
+	require.Equal(t, testDBSecretVersion.Value, pbSecretVersion.Value)
+	require.Equal(t, testDBSecretVersion.Modified.UTC(), pbSecretVersion.Modified.AsTime())
+	require.Equal(t, testDBSecretVersion.Revision, pbSecretVersion.Revision)
+}
+
+func TestVaultKeyConversion(t *testing.T) {
+	testDBVaultKey := db.VaultKey{
+		Owner:       random.RandomOwner(),
+		Salt:        []byte(random.RandomString(16)),
+		Memory:      64 * 1024,
+		Iterations:  3,
+		Parallelism: 4,
+		KeyCheck:    []byte(random.RandomString(32)),
+	}
+
+	pbVaultKey := DBVaultKeyToPBVaultKey(testDBVaultKey)
+	require.Equal(t, testDBVaultKey.Salt, pbVaultKey.Salt)
+	require.Equal(t, uint32(64*1024), pbVaultKey.Memory)
+	require.Equal(t, testDBVaultKey.KeyCheck, pbVaultKey.Check)
+
+	require.Equal(t, testDBVaultKey, PBVaultKeyToDBVaultKey(testDBVaultKey.Owner, pbVaultKey))
+}
@@ -0,0 +1,169 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// Stored value starts with the format header. Unencrypted value follows it as it is:
+//
+//	"GK" | 0 | plaintext
+//
+// Sealed value is the header, the random data key wrapped with the key encryption key
+// and the payload encrypted with the data key:
+//
+//	"GK" | version | nonce | wrapped data key | tag | nonce | ciphertext | tag
+//
+// The header is authenticated with both the data key and the payload. The payload is bound
+// to the associated data as well, so the value can't be moved to another secret.
+// Changing the key encryption key only re-wraps the data key.
+const (
+	headerLength     = 3
+	dataKeyLength    = 32
+	wrappedKeyLength = 12 + dataKeyLength + 16
+)
+
+// Stored value format versions.
+const (
+	// VersionPlain values are not encrypted.
+	VersionPlain byte = 0
+	// VersionUnbound values are not bound to their identity. They are encrypted with the key
+	// itself or sealed with a data key before the header was introduced.
+	VersionUnbound byte = 1
+	// VersionBound values have the header and are bound to the associated data.
+	VersionBound byte = 2
+
+	// CurrentVersion is the version of the values made by Seal.
+	CurrentVersion = VersionBound
+)
+
+var headerMagic = []byte("GK")
+
+var (
+	ErrNotSealed = errors.New("value is not sealed with a data key")
+	ErrNotPlain  = errors.New("value is not stored unencrypted")
+)
+
+// header returns the format header of the version.
+func header(version byte) []byte {
+	h := make([]byte, headerLength)
+	copy(h, headerMagic)
+	h[len(headerMagic)] = version
+
+	return h
+}
+
+// FormatVersion returns the format version of the stored value.
+// It reports false for the values without the header.
+func FormatVersion(value []byte) (byte, bool) {
+	if len(value) < headerLength || !bytes.HasPrefix(value, headerMagic) {
+		return 0, false
+	}
+
+	return value[len(headerMagic)], true
+}
+
+// Plain returns the value stored unencrypted.
+func Plain(plaintext []byte) []byte {
+	return append(header(VersionPlain), plaintext...)
+}
+
+// OpenPlain returns the plaintext of the value stored unencrypted.
+func OpenPlain(value []byte) ([]byte, error) {
+	version, ok := FormatVersion(value)
+	if !ok || version != VersionPlain {
+		return nil, ErrNotPlain
+	}
+
+	return value[headerLength:], nil
+}
+
+// Seal encrypts the plaintext with a new data key wrapped with the key encryption key.
+// The value is only opened with the same associated data.
+func Seal(plaintext, kek, associatedData []byte) ([]byte, error) {
+	dataKey := make([]byte, dataKeyLength)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+
+	sealedHeader := header(CurrentVersion)
+
+	wrapped, err := EncryptWithAD(dataKey, kek, sealedHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := EncryptWithAD(plaintext, dataKey, append(sealedHeader, associatedData...))
+	if err != nil {
+		return nil, err
+	}
+
+	sealed := append(sealedHeader, wrapped...)
+
+	return append(sealed, ciphertext...), nil
+}
+
+// Open decrypts the value sealed with the associated data.
+func Open(sealed, kek, associatedData []byte) ([]byte, error) {
+	sealedHeader, dataKey, ciphertext, err := unwrap(sealed, kek)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecryptWithAD(ciphertext, dataKey, append(sealedHeader, associatedData...))
+}
+
+// Rewrap wraps the data key of the sealed value with the new key encryption key.
+// The payload ciphertext stays as it is.
+func Rewrap(sealed, kek, newKEK []byte) ([]byte, error) {
+	sealedHeader, dataKey, ciphertext, err := unwrap(sealed, kek)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped, err := EncryptWithAD(dataKey, newKEK, sealedHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	rewrapped := append(sealedHeader, wrapped...)
+
+	return append(rewrapped, ciphertext...), nil
+}
+
+// OpenUnbound decrypts the value sealed with a data key before the header was introduced.
+func OpenUnbound(sealed, kek []byte) ([]byte, error) {
+	if len(sealed) < wrappedKeyLength {
+		return nil, ErrNotSealed
+	}
+
+	dataKey, err := Decrypt(sealed[:wrappedKeyLength], kek)
+	if err != nil || len(dataKey) != dataKeyLength {
+		return nil, fmt.Errorf("%w: failed to unwrap data key", ErrNotSealed)
+	}
+
+	return Decrypt(sealed[wrappedKeyLength:], dataKey)
+}
+
+func unwrap(sealed, kek []byte) ([]byte, []byte, []byte, error) {
+	version, ok := FormatVersion(sealed)
+	if !ok || len(sealed) < headerLength+wrappedKeyLength {
+		return nil, nil, nil, ErrNotSealed
+	}
+	if version != CurrentVersion {
+		return nil, nil, nil, fmt.Errorf("%w: unknown format version %v", ErrNotSealed, version)
+	}
+
+	sealedHeader := sealed[:headerLength:headerLength]
+
+	wrapped := sealed[headerLength : headerLength+wrappedKeyLength]
+
+	dataKey, err := DecryptWithAD(wrapped, kek, sealedHeader)
+	if err != nil || len(dataKey) != dataKeyLength {
+		return nil, nil, nil, fmt.Errorf("%w: failed to unwrap data key", ErrNotSealed)
+	}
+
+	return sealedHeader, dataKey, sealed[headerLength+wrappedKeyLength:], nil
+}
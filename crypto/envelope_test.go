@@ -0,0 +1,120 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvelope(t *testing.T) {
+	text := []byte("answer to ultimate question of life the universe and everything")
+	kek := []byte("the-key-has-to-be-32-bytes-long!")
+	newKEK := []byte("the-new-key-is-32-bytes-long-too")
+	ad := []byte("question")
+
+	sealed, err := Seal(text, kek, ad)
+	require.NoError(t, err)
+	require.Equal(t, header(CurrentVersion), sealed[:3])
+
+	// Every value gets its own data key
+	other, err := Seal(text, kek, ad)
+	require.NoError(t, err)
+	require.NotEqual(t, sealed[:3+wrappedKeyLength], other[:3+wrappedKeyLength])
+
+	plaintext, err := Open(sealed, kek, ad)
+	require.NoError(t, err)
+	require.Equal(t, text, plaintext)
+
+	_, err = Open(sealed, newKEK, ad)
+	require.ErrorIs(t, err, ErrNotSealed)
+
+	// Value is bound to its associated data
+	_, err = Open(sealed, kek, []byte("another question"))
+	require.Error(t, err)
+
+	// Header can't be changed
+	tampered := append([]byte{}, sealed...)
+	tampered[2] = VersionUnbound
+	_, err = Open(tampered, kek, ad)
+	require.ErrorIs(t, err, ErrNotSealed)
+
+	// Re-wrapping leaves the payload ciphertext as it is
+	rewrapped, err := Rewrap(sealed, kek, newKEK)
+	require.NoError(t, err)
+	require.Equal(t, sealed[3+wrappedKeyLength:], rewrapped[3+wrappedKeyLength:])
+
+	plaintext, err = Open(rewrapped, newKEK, ad)
+	require.NoError(t, err)
+	require.Equal(t, text, plaintext)
+
+	_, err = Open(rewrapped, kek, ad)
+	require.ErrorIs(t, err, ErrNotSealed)
+
+	// Values encrypted with the key directly are not sealed
+	direct, err := Encrypt(text, kek)
+	require.NoError(t, err)
+
+	_, err = Open(direct, kek, ad)
+	require.ErrorIs(t, err, ErrNotSealed)
+
+	_, err = OpenUnbound(direct, kek)
+	require.ErrorIs(t, err, ErrNotSealed)
+}
+
+func TestOpenUnbound(t *testing.T) {
+	text := []byte("answer to ultimate question of life the universe and everything")
+	kek := []byte("the-key-has-to-be-32-bytes-long!")
+	dataKey := []byte("the-data-key-is-32-bytes-long-to")
+
+	// Value sealed before the header was introduced
+	wrapped, err := Encrypt(dataKey, kek)
+	require.NoError(t, err)
+
+	ciphertext, err := Encrypt(text, dataKey)
+	require.NoError(t, err)
+
+	unbound := append(wrapped, ciphertext...)
+
+	plaintext, err := OpenUnbound(unbound, kek)
+	require.NoError(t, err)
+	require.Equal(t, text, plaintext)
+
+	_, err = Open(unbound, kek, nil)
+	require.ErrorIs(t, err, ErrNotSealed)
+}
+
+func TestPlain(t *testing.T) {
+	text := []byte("answer to ultimate question of life the universe and everything")
+	kek := []byte("the-key-has-to-be-32-bytes-long!")
+
+	plain := Plain(text)
+
+	version, ok := FormatVersion(plain)
+	require.True(t, ok)
+	require.Equal(t, VersionPlain, version)
+
+	opened, err := OpenPlain(plain)
+	require.NoError(t, err)
+	require.Equal(t, text, opened)
+
+	// Unencrypted value is never opened as a sealed one and the other way round
+	_, err = Open(plain, kek, nil)
+	require.ErrorIs(t, err, ErrNotSealed)
+
+	sealed, err := Seal(text, kek, nil)
+	require.NoError(t, err)
+
+	version, ok = FormatVersion(sealed)
+	require.True(t, ok)
+	require.Equal(t, CurrentVersion, version)
+
+	_, err = OpenPlain(sealed)
+	require.ErrorIs(t, err, ErrNotPlain)
+
+	// Values without the header have no version
+	_, ok = FormatVersion(text)
+	require.False(t, ok)
+
+	_, err = OpenPlain(text)
+	require.ErrorIs(t, err, ErrNotPlain)
+}
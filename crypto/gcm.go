@@ -9,12 +9,17 @@ import (
 )
 
 func Encrypt(plaintext []byte, key []byte) ([]byte, error) {
-	c, err := aes.NewCipher(key)
-	if err != nil {
-		return nil, err
-	}
+	return EncryptWithAD(plaintext, key, nil)
+}
 
-	gcm, err := cipher.NewGCM(c)
+func Decrypt(ciphertext []byte, key []byte) ([]byte, error) {
+	return DecryptWithAD(ciphertext, key, nil)
+}
+
+// EncryptWithAD encrypts the plaintext authenticating the additional data along with it.
+// The ciphertext is only decrypted with the same additional data.
+func EncryptWithAD(plaintext, key, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
 	if err != nil {
 		return nil, err
 	}
@@ -24,16 +29,12 @@ func Encrypt(plaintext []byte, key []byte) ([]byte, error) {
 		return nil, err
 	}
 
-	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+	return gcm.Seal(nonce, nonce, plaintext, additionalData), nil
 }
 
-func Decrypt(ciphertext []byte, key []byte) ([]byte, error) {
-	c, err := aes.NewCipher(key)
-	if err != nil {
-		return nil, err
-	}
-
-	gcm, err := cipher.NewGCM(c)
+// DecryptWithAD decrypts the ciphertext made by EncryptWithAD with the same additional data.
+func DecryptWithAD(ciphertext, key, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
 	if err != nil {
 		return nil, err
 	}
@@ -44,5 +45,14 @@ func Decrypt(ciphertext []byte, key []byte) ([]byte, error) {
 	}
 
 	nonce, ciphertext := ciphertext[:nonceSize], ciphertext[nonceSize:]
-	return gcm.Open(nil, nonce, ciphertext, nil)
+	return gcm.Open(nil, nonce, ciphertext, additionalData)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	c, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(c)
 }
@@ -17,3 +17,22 @@ func TestGCM(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, plaintext, text)
 }
+
+func TestGCMWithAD(t *testing.T) {
+	text := []byte("answer to ultimate question of life the universe and everything")
+	key := []byte("the-key-has-to-be-32-bytes-long!")
+
+	ciphertext, err := EncryptWithAD(text, key, []byte("question"))
+	require.NoError(t, err)
+
+	plaintext, err := DecryptWithAD(ciphertext, key, []byte("question"))
+	require.NoError(t, err)
+	require.Equal(t, text, plaintext)
+
+	// Ciphertext is bound to its additional data
+	_, err = DecryptWithAD(ciphertext, key, []byte("another question"))
+	require.Error(t, err)
+
+	_, err = Decrypt(ciphertext, key)
+	require.Error(t, err)
+}
@@ -1,21 +1,197 @@
 package crypto
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"strings"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// HashPassword returns the bcrypt hash of the password
-func HashPassword(password string) (string, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+const (
+	argon2SaltLength = 16 // bytes
+	argon2KeyLength  = 32 // bytes
+)
+
+var (
+	ErrMismatchedPassword = errors.New("password does not match the hash")
+	ErrUnknownHash        = errors.New("unknown password hash format")
+)
+
+// PasswordHasher hashes and checks passwords.
+type PasswordHasher interface {
+	// Hash returns the self-describing hash of the password.
+	Hash(password string) (string, error)
+	// Check returns ErrMismatchedPassword if the password doesn't match the hash.
+	Check(password, hash string) error
+	// NeedsRehash reports whether the hash was made with another algorithm or parameters.
+	NeedsRehash(hash string) bool
+}
+
+// Argon2idHasher hashes passwords with Argon2id into PHC strings, e.g.
+// $argon2id$v=19$m=65536,t=3,p=4$<base64 salt>$<base64 key>
+type Argon2idHasher struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+}
+
+// DefaultArgon2idHasher uses the parameters recommended by RFC 9106.
+var DefaultArgon2idHasher = Argon2idHasher{Memory: 64 * 1024, Iterations: 3, Parallelism: 4}
+
+func (h Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.Iterations, h.Memory, h.Parallelism, argon2KeyLength)
+
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		h.Memory,
+		h.Iterations,
+		h.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Check checks the password against the hash with the parameters from the hash itself.
+func (h Argon2idHasher) Check(password, hash string) error {
+	params, salt, key, err := parseArgon2idHash(hash)
+	if err != nil {
+		return err
+	}
+
+	otherKey := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, otherKey) != 1 {
+		return ErrMismatchedPassword
+	}
+
+	return nil
+}
+
+func (h Argon2idHasher) NeedsRehash(hash string) bool {
+	params, _, key, err := parseArgon2idHash(hash)
+	if err != nil {
+		return true
+	}
+
+	return params != h || len(key) != argon2KeyLength
+}
+
+// Key derives the encryption key from the password.
+func (h Argon2idHasher) Key(password, salt []byte) []byte {
+	return argon2.IDKey(password, salt, h.Iterations, h.Memory, h.Parallelism, argon2KeyLength)
+}
+
+func parseArgon2idHash(hash string) (Argon2idHasher, []byte, []byte, error) {
+	// "", "argon2id", "v=19", "m=65536,t=3,p=4", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != "argon2id" {
+		return Argon2idHasher{}, nil, nil, ErrUnknownHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return Argon2idHasher{}, nil, nil, ErrUnknownHash
+	}
+
+	params := Argon2idHasher{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return Argon2idHasher{}, nil, nil, ErrUnknownHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Argon2idHasher{}, nil, nil, ErrUnknownHash
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return Argon2idHasher{}, nil, nil, ErrUnknownHash
+	}
+
+	return params, salt, key, nil
+}
+
+// BcryptHasher hashes passwords with bcrypt.
+// Note bcrypt ignores everything beyond the first 72 bytes of the password.
+type BcryptHasher struct {
+	Cost int
+}
+
+func (h BcryptHasher) Hash(password string) (string, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
 	if err != nil {
 		return "", fmt.Errorf("failed to hash password: %w", err)
 	}
 	return string(hashedPassword), nil
 }
 
+func (h BcryptHasher) Check(password, hash string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrMismatchedPassword
+	}
+	return err
+}
+
+func (h BcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return true
+	}
+
+	return cost != h.Cost
+}
+
+// upgradingHasher hashes passwords with the current hasher
+// but checks hashes made with any of the supported algorithms.
+type upgradingHasher struct {
+	current PasswordHasher
+}
+
+// NewPasswordHasher returns the hasher new hashes are made with. Hashes made with
+// other algorithms are still checked and reported by NeedsRehash to be upgraded.
+func NewPasswordHasher(current PasswordHasher) PasswordHasher {
+	return upgradingHasher{current: current}
+}
+
+func (h upgradingHasher) Hash(password string) (string, error) {
+	return h.current.Hash(password)
+}
+
+func (h upgradingHasher) Check(password, hash string) error {
+	switch {
+	case strings.HasPrefix(hash, "$argon2id$"):
+		return Argon2idHasher{}.Check(password, hash)
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		return BcryptHasher{}.Check(password, hash)
+	}
+
+	return ErrUnknownHash
+}
+
+func (h upgradingHasher) NeedsRehash(hash string) bool {
+	return h.current.NeedsRehash(hash)
+}
+
+// DefaultPasswordHasher hashes passwords with Argon2id.
+var DefaultPasswordHasher = NewPasswordHasher(DefaultArgon2idHasher)
+
+// HashPassword returns the hash of the password made by the default hasher
+func HashPassword(password string) (string, error) {
+	return DefaultPasswordHasher.Hash(password)
+}
+
 // CheckPassword checks if the provided password is correct or not
 func CheckPassword(password string, hashedPassword string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+	return DefaultPasswordHasher.Check(password, hashedPassword)
 }
@@ -1,6 +1,7 @@
 package crypto
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -15,16 +16,65 @@ func TestPassword(t *testing.T) {
 	hashedPassword1, err := HashPassword(password)
 	require.NoError(t, err)
 	require.NotEmpty(t, hashedPassword1)
+	require.True(t, strings.HasPrefix(hashedPassword1, "$argon2id$v=19$m=65536,t=3,p=4$"))
 
 	err = CheckPassword(password, hashedPassword1)
 	require.NoError(t, err)
 
 	wrongPassword := random.RandomString(6)
 	err = CheckPassword(wrongPassword, hashedPassword1)
-	require.EqualError(t, err, bcrypt.ErrMismatchedHashAndPassword.Error())
+	require.ErrorIs(t, err, ErrMismatchedPassword)
 
 	hashedPassword2, err := HashPassword(password)
 	require.NoError(t, err)
 	require.NotEmpty(t, hashedPassword2)
 	require.NotEqual(t, hashedPassword1, hashedPassword2)
 }
+
+func TestLongPassword(t *testing.T) {
+	password := strings.Repeat("a", 72)
+
+	hashedPassword, err := HashPassword(password)
+	require.NoError(t, err)
+
+	// Unlike bcrypt all the password bytes matter
+	err = CheckPassword(password+"b", hashedPassword)
+	require.ErrorIs(t, err, ErrMismatchedPassword)
+}
+
+func TestPasswordRehash(t *testing.T) {
+	password := random.RandomString(6)
+
+	bcryptHash, err := BcryptHasher{Cost: bcrypt.MinCost}.Hash(password)
+	require.NoError(t, err)
+
+	// Old bcrypt hashes are still checked but need rehash
+	hasher := NewPasswordHasher(Argon2idHasher{Memory: 1024, Iterations: 1, Parallelism: 1})
+
+	require.NoError(t, hasher.Check(password, bcryptHash))
+	require.ErrorIs(t, hasher.Check("wrong", bcryptHash), ErrMismatchedPassword)
+	require.True(t, hasher.NeedsRehash(bcryptHash))
+
+	argon2Hash, err := hasher.Hash(password)
+	require.NoError(t, err)
+	require.NoError(t, hasher.Check(password, argon2Hash))
+	require.False(t, hasher.NeedsRehash(argon2Hash))
+
+	// Parameters changed
+	stronger := NewPasswordHasher(Argon2idHasher{Memory: 2048, Iterations: 1, Parallelism: 1})
+	require.NoError(t, stronger.Check(password, argon2Hash))
+	require.True(t, stronger.NeedsRehash(argon2Hash))
+
+	require.ErrorIs(t, hasher.Check(password, "plaintext"), ErrUnknownHash)
+}
+
+func TestKey(t *testing.T) {
+	hasher := Argon2idHasher{Memory: 1024, Iterations: 1, Parallelism: 1}
+	salt := []byte(random.RandomString(16))
+
+	key := hasher.Key([]byte("password"), salt)
+	require.Len(t, key, 32)
+	require.Equal(t, key, hasher.Key([]byte("password"), salt))
+	require.NotEqual(t, key, hasher.Key([]byte("password"), []byte(random.RandomString(16))))
+	require.NotEqual(t, key, hasher.Key([]byte("passworD"), salt))
+}
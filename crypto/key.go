@@ -0,0 +1,29 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+)
+
+// keyCheckLabel is MACed into the key check value, so the value reveals nothing about the key.
+var keyCheckLabel = []byte("gophkeeper key check")
+
+// KeyCheck returns the value telling if the key is right without revealing the key.
+// The format version of the values sealed with the key is MACed into it too,
+// so the version can't be downgraded without the key.
+func KeyCheck(key []byte, version byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(keyCheckLabel)
+
+	// Key checks were made with the label only before the values were bound
+	if version > VersionUnbound {
+		mac.Write([]byte{version})
+	}
+
+	return mac.Sum(nil)
+}
+
+// CheckKey reports whether the key matches the key check value made for the format version.
+func CheckKey(key, check []byte, version byte) bool {
+	return hmac.Equal(KeyCheck(key, version), check)
+}
@@ -0,0 +1,23 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyCheck(t *testing.T) {
+	key := []byte("the-key-has-to-be-32-bytes-long!")
+
+	check := KeyCheck(key, CurrentVersion)
+	require.Len(t, check, 32)
+	require.NotContains(t, string(check), string(key))
+
+	require.True(t, CheckKey(key, check, CurrentVersion))
+	require.False(t, CheckKey([]byte("the-key-has-to-be-32-bytes-long?"), check, CurrentVersion))
+	require.False(t, CheckKey(key, nil, CurrentVersion))
+
+	// Format version is bound to the check
+	require.False(t, CheckKey(key, check, VersionUnbound))
+	require.True(t, CheckKey(key, KeyCheck(key, VersionUnbound), VersionUnbound))
+}
@@ -0,0 +1,209 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.16.0
+// source: audit.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createAuditEvent = `-- name: CreateAuditEvent :one
+INSERT INTO audit_events (
+  type,
+  username,
+  peer,
+  secret_kind,
+  secret_name,
+  created,
+  prev_hash,
+  hash
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+)
+RETURNING id, type, username, peer, secret_kind, secret_name, created, prev_hash, hash
+`
+
+type CreateAuditEventParams struct {
+	Type       string
+	Username   string
+	Peer       string
+	SecretKind int32
+	SecretName string
+	Created    time.Time
+	PrevHash   string
+	Hash       string
+}
+
+func (q *Queries) CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error) {
+	row := q.db.QueryRowContext(ctx, createAuditEvent,
+		arg.Type,
+		arg.Username,
+		arg.Peer,
+		arg.SecretKind,
+		arg.SecretName,
+		arg.Created,
+		arg.PrevHash,
+		arg.Hash,
+	)
+	var i AuditEvent
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
+		&i.Username,
+		&i.Peer,
+		&i.SecretKind,
+		&i.SecretName,
+		&i.Created,
+		&i.PrevHash,
+		&i.Hash,
+	)
+	return i, err
+}
+
+const getAuditEvents = `-- name: GetAuditEvents :many
+SELECT id, type, username, peer, secret_kind, secret_name, created, prev_hash, hash FROM audit_events
+WHERE id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type GetAuditEventsParams struct {
+	ID    int64
+	Limit int32
+}
+
+func (q *Queries) GetAuditEvents(ctx context.Context, arg GetAuditEventsParams) ([]AuditEvent, error) {
+	rows, err := q.db.QueryContext(ctx, getAuditEvents, arg.ID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditEvent
+	for rows.Next() {
+		var i AuditEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.Type,
+			&i.Username,
+			&i.Peer,
+			&i.SecretKind,
+			&i.SecretName,
+			&i.Created,
+			&i.PrevHash,
+			&i.Hash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAuditEventsByUser = `-- name: GetAuditEventsByUser :many
+SELECT id, type, username, peer, secret_kind, secret_name, created, prev_hash, hash FROM audit_events
+WHERE username = $1
+ORDER BY id DESC
+LIMIT $2
+`
+
+type GetAuditEventsByUserParams struct {
+	Username string
+	Limit    int32
+}
+
+func (q *Queries) GetAuditEventsByUser(ctx context.Context, arg GetAuditEventsByUserParams) ([]AuditEvent, error) {
+	rows, err := q.db.QueryContext(ctx, getAuditEventsByUser, arg.Username, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditEvent
+	for rows.Next() {
+		var i AuditEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.Type,
+			&i.Username,
+			&i.Peer,
+			&i.SecretKind,
+			&i.SecretName,
+			&i.Created,
+			&i.PrevHash,
+			&i.Hash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAuditHead = `-- name: GetAuditHead :one
+SELECT id, event_id, hash, mac FROM audit_head
+WHERE id = 1
+`
+
+func (q *Queries) GetAuditHead(ctx context.Context) (AuditHead, error) {
+	row := q.db.QueryRowContext(ctx, getAuditHead)
+	var i AuditHead
+	err := row.Scan(
+		&i.ID,
+		&i.EventID,
+		&i.Hash,
+		&i.Mac,
+	)
+	return i, err
+}
+
+const lockAuditHead = `-- name: LockAuditHead :one
+SELECT id, event_id, hash, mac FROM audit_head
+WHERE id = 1
+FOR UPDATE
+`
+
+// The head row lock serializes the appends of all the server replicas.
+func (q *Queries) LockAuditHead(ctx context.Context) (AuditHead, error) {
+	row := q.db.QueryRowContext(ctx, lockAuditHead)
+	var i AuditHead
+	err := row.Scan(
+		&i.ID,
+		&i.EventID,
+		&i.Hash,
+		&i.Mac,
+	)
+	return i, err
+}
+
+const updateAuditHead = `-- name: UpdateAuditHead :exec
+UPDATE audit_head
+SET event_id = $1,
+  hash = $2,
+  mac = $3
+WHERE id = 1
+`
+
+type UpdateAuditHeadParams struct {
+	EventID int64
+	Hash    string
+	Mac     string
+}
+
+func (q *Queries) UpdateAuditHead(ctx context.Context, arg UpdateAuditHeadParams) error {
+	_, err := q.db.ExecContext(ctx, updateAuditHead, arg.EventID, arg.Hash, arg.Mac)
+	return err
+}
@@ -0,0 +1,171 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.16.0
+// source: conflicts.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const deleteSecretConflict = `-- name: DeleteSecretConflict :exec
+DELETE FROM secret_conflicts
+WHERE owner = $1 AND kind = $2 AND name = $3
+`
+
+type DeleteSecretConflictParams struct {
+	Owner string
+	Kind  int32
+	Name  string
+}
+
+func (q *Queries) DeleteSecretConflict(ctx context.Context, arg DeleteSecretConflictParams) error {
+	_, err := q.db.ExecContext(ctx, deleteSecretConflict, arg.Owner, arg.Kind, arg.Name)
+	return err
+}
+
+const getSecretConflict = `-- name: GetSecretConflict :one
+SELECT owner, kind, name, value, created, modified, deleted, revision, remote_id FROM secret_conflicts
+WHERE owner = $1 AND kind = $2 AND name = $3
+`
+
+type GetSecretConflictParams struct {
+	Owner string
+	Kind  int32
+	Name  string
+}
+
+func (q *Queries) GetSecretConflict(ctx context.Context, arg GetSecretConflictParams) (SecretConflict, error) {
+	row := q.db.QueryRowContext(ctx, getSecretConflict, arg.Owner, arg.Kind, arg.Name)
+	var i SecretConflict
+	err := row.Scan(
+		&i.Owner,
+		&i.Kind,
+		&i.Name,
+		&i.Value,
+		&i.Created,
+		&i.Modified,
+		&i.Deleted,
+		&i.Revision,
+		&i.RemoteID,
+	)
+	return i, err
+}
+
+const getSecretConflicts = `-- name: GetSecretConflicts :many
+SELECT owner, kind, name, value, created, modified, deleted, revision, remote_id FROM secret_conflicts
+WHERE owner = $1
+`
+
+func (q *Queries) GetSecretConflicts(ctx context.Context, owner string) ([]SecretConflict, error) {
+	rows, err := q.db.QueryContext(ctx, getSecretConflicts, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SecretConflict
+	for rows.Next() {
+		var i SecretConflict
+		if err := rows.Scan(
+			&i.Owner,
+			&i.Kind,
+			&i.Name,
+			&i.Value,
+			&i.Created,
+			&i.Modified,
+			&i.Deleted,
+			&i.Revision,
+			&i.RemoteID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rebaseLocalSecret = `-- name: RebaseLocalSecret :exec
+UPDATE secrets
+SET revision = $4,
+  remote_id = $5,
+  dirty = true
+WHERE owner = $1 AND kind = $2 AND name = $3
+`
+
+type RebaseLocalSecretParams struct {
+	Owner    string
+	Kind     int32
+	Name     string
+	Revision int64
+	RemoteID string
+}
+
+// Local changes are pushed on top of the server revision under its server identity.
+func (q *Queries) RebaseLocalSecret(ctx context.Context, arg RebaseLocalSecretParams) error {
+	_, err := q.db.ExecContext(ctx, rebaseLocalSecret,
+		arg.Owner,
+		arg.Kind,
+		arg.Name,
+		arg.Revision,
+		arg.RemoteID,
+	)
+	return err
+}
+
+const saveSecretConflict = `-- name: SaveSecretConflict :exec
+INSERT INTO secret_conflicts (
+  owner,
+  kind,
+  name,
+  value,
+  created,
+  modified,
+  deleted,
+  revision,
+  remote_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+ON CONFLICT (owner, kind, name) DO UPDATE
+SET value = EXCLUDED.value,
+  created = EXCLUDED.created,
+  modified = EXCLUDED.modified,
+  deleted = EXCLUDED.deleted,
+  revision = EXCLUDED.revision,
+  remote_id = EXCLUDED.remote_id
+`
+
+type SaveSecretConflictParams struct {
+	Owner    string
+	Kind     int32
+	Name     string
+	Value    []byte
+	Created  time.Time
+	Modified time.Time
+	Deleted  bool
+	Revision int64
+	RemoteID string
+}
+
+func (q *Queries) SaveSecretConflict(ctx context.Context, arg SaveSecretConflictParams) error {
+	_, err := q.db.ExecContext(ctx, saveSecretConflict,
+		arg.Owner,
+		arg.Kind,
+		arg.Name,
+		arg.Value,
+		arg.Created,
+		arg.Modified,
+		arg.Deleted,
+		arg.Revision,
+		arg.RemoteID,
+	)
+	return err
+}
@@ -5,11 +5,79 @@
 package db
 
 import (
+	"database/sql"
 	"time"
 )
 
-type Secret struct {
+type AuditEvent struct {
+	ID         int64
+	Type       string
+	Username   string
+	Peer       string
+	SecretKind int32
+	SecretName string
+	Created    time.Time
+	PrevHash   string
+	Hash       string
+}
+
+type AuditHead struct {
+	ID      int32
+	EventID int64
+	Hash    string
+	Mac     string
+}
+
+type CachedToken struct {
+	Owner        string
+	Token        string
+	RefreshToken string
+}
+
+type PendingRekey struct {
+	Owner    string
+	KeyCheck []byte
+}
+
+type PurgedRevision struct {
+	Owner    string
+	Revision int64
+}
+
+type RecoveryCode struct {
 	ID       int64
+	Username string
+	Hash     string
+	Used     bool
+}
+
+type RefreshToken struct {
+	ID        int64
+	Hash      string
+	Family    string
+	Username  string
+	ExpiresAt time.Time
+	Used      bool
+	Revoked   bool
+	Created   time.Time
+}
+
+type Secret struct {
+	ID        int64
+	Owner     string
+	Kind      int32
+	Name      string
+	Value     []byte
+	Created   time.Time
+	Modified  time.Time
+	Deleted   bool
+	Revision  int64
+	Dirty     bool
+	DeletedAt sql.NullTime
+	RemoteID  string
+}
+
+type SecretConflict struct {
 	Owner    string
 	Kind     int32
 	Name     string
@@ -17,10 +85,49 @@ type Secret struct {
 	Created  time.Time
 	Modified time.Time
 	Deleted  bool
+	Revision int64
+	RemoteID string
 }
 
-type User struct {
-	ID       int32
+type SecretVersion struct {
+	Owner    string
+	Kind     int32
 	Name     string
-	Passhash string
+	Value    []byte
+	Modified time.Time
+	Revision int64
+}
+
+type Session struct {
+	ID            string
+	Username      string
+	Device        string
+	ClientVersion string
+	Ip            string
+	Created       time.Time
+	LastSeen      time.Time
+	Revoked       bool
+}
+
+type SyncCursor struct {
+	Owner    string
+	Revision int64
+}
+
+type User struct {
+	ID           int32
+	Name         string
+	Passhash     string
+	TotpSecret   string
+	TotpEnabled  bool
+	TotpLastStep int64
+}
+
+type VaultKey struct {
+	Owner       string
+	Salt        []byte
+	Memory      int64
+	Iterations  int64
+	Parallelism int32
+	KeyCheck    []byte
 }
@@ -6,20 +6,138 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 type Querier interface {
-	CleanSecrets(ctx context.Context) ([]Secret, error)
+	// Client side copy of the server version.
+	AddSecretVersion(ctx context.Context, arg AddSecretVersionParams) error
+	CleanRefreshTokens(ctx context.Context) (int64, error)
+	// Client side. Deleted secrets are kept in the trash until the deletion
+	// is pushed and they get old or are purged.
+	CleanSecrets(ctx context.Context, deletedBefore time.Time) ([]Secret, error)
+	CleanSessions(ctx context.Context, lastSeen time.Time) (int64, error)
+	// Values of the deleted secrets are dropped when the vault is rekeyed,
+	// the ones still kept in the client trash are rewrapped afterwards.
+	ClearSecretTombstones(ctx context.Context, owner string) error
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error)
+	// Client local databases only keep the owner of the cached secrets.
+	CreateLocalUser(ctx context.Context, name string) error
+	CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) error
+	CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) (RefreshToken, error)
+	// No row is returned if the secret already exists.
 	CreateSecret(ctx context.Context, arg CreateSecretParams) (Secret, error)
+	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	// The first device to create the vault key wins, the others get the stored one.
+	CreateVaultKey(ctx context.Context, arg CreateVaultKeyParams) (int64, error)
+	// Client side.
+	DeletePendingRekey(ctx context.Context, owner string) error
+	DeleteRecoveryCodes(ctx context.Context, username string) error
 	DeleteSecret(ctx context.Context, arg DeleteSecretParams) error
+	DeleteSecretConflict(ctx context.Context, arg DeleteSecretConflictParams) error
+	DeleteSecretVersions(ctx context.Context, owner string) error
 	DeleteUser(ctx context.Context, name string) error
+	// Single statement so the user and all the user data are removed atomically.
+	DeleteUserWithSecrets(ctx context.Context, name string) error
+	EnableTOTP(ctx context.Context, name string) error
+	// Server side. The secret is removed with its versions and leaves no tombstone.
+	ForgetSecret(ctx context.Context, arg ForgetSecretParams) (int64, error)
+	GetAuditEvents(ctx context.Context, arg GetAuditEventsParams) ([]AuditEvent, error)
+	GetAuditEventsByUser(ctx context.Context, arg GetAuditEventsByUserParams) ([]AuditEvent, error)
+	GetAuditHead(ctx context.Context) (AuditHead, error)
+	// Client side.
+	GetCachedTokens(ctx context.Context, owner string) (CachedToken, error)
+	// Conflicted secrets are not pushed until resolved.
+	GetDirtySecrets(ctx context.Context, owner string) ([]Secret, error)
+	// Client side.
+	GetPendingRekey(ctx context.Context, owner string) (PendingRekey, error)
+	GetPurgedRevision(ctx context.Context, owner string) (int64, error)
+	GetRefreshToken(ctx context.Context, hash string) (RefreshToken, error)
 	GetSecret(ctx context.Context, arg GetSecretParams) (Secret, error)
+	// Client side.
+	GetSecretByRemoteID(ctx context.Context, arg GetSecretByRemoteIDParams) (Secret, error)
+	// Changed and deleted secrets of the user after the revision.
+	GetSecretChanges(ctx context.Context, arg GetSecretChangesParams) ([]Secret, error)
+	GetSecretConflict(ctx context.Context, arg GetSecretConflictParams) (SecretConflict, error)
+	GetSecretConflicts(ctx context.Context, owner string) ([]SecretConflict, error)
+	GetSecretVersion(ctx context.Context, arg GetSecretVersionParams) (SecretVersion, error)
+	GetSecretVersions(ctx context.Context, arg GetSecretVersionsParams) ([]SecretVersion, error)
 	GetSecretsByKind(ctx context.Context, arg GetSecretsByKindParams) ([]Secret, error)
 	GetSecretsByUser(ctx context.Context, owner string) ([]Secret, error)
+	GetSession(ctx context.Context, id string) (Session, error)
+	GetSessionsByUser(ctx context.Context, username string) ([]Session, error)
+	GetSyncCursor(ctx context.Context, owner string) (int64, error)
+	GetTrashedSecrets(ctx context.Context, owner string) ([]Secret, error)
 	GetUser(ctx context.Context, name string) (User, error)
-	MarkSecretDeleted(ctx context.Context, arg MarkSecretDeletedParams) error
+	GetVaultKey(ctx context.Context, owner string) (VaultKey, error)
+	// The head row lock serializes the appends of all the server replicas.
+	LockAuditHead(ctx context.Context) (AuditHead, error)
+	// Serializes user secret changes until the end of the transaction
+	// so the revisions are committed in order.
+	LockUserSecrets(ctx context.Context, owner string) error
+	// Waits for the user secret changes in progress to read committed revisions only.
+	LockUserSecretsShared(ctx context.Context, owner string) error
+	// Client side. Deleted secret is moved to the trash.
+	MarkLocalSecretDeleted(ctx context.Context, arg MarkLocalSecretDeletedParams) error
+	MarkRefreshTokenUsed(ctx context.Context, hash string) (int64, error)
+	// Pushed secret gets its new revision. Secrets changed again while being pushed stay dirty.
+	MarkSecretSynced(ctx context.Context, arg MarkSecretSyncedParams) error
+	MarkSecretsDirty(ctx context.Context, owner string) error
+	// Only the latest versions of every secret are kept.
+	// Versions of the secrets no longer stored are removed too.
+	PruneSecretVersions(ctx context.Context, keep int32) (int64, error)
+	// Client side. Purged secret is removed from the trash right away and its value is dropped.
+	// The deletion is pushed without the value and the secret is removed from the db afterwards.
+	PurgeLocalSecret(ctx context.Context, arg PurgeLocalSecretParams) error
+	// Versions of the purged secret are removed right away.
+	PurgeSecretVersions(ctx context.Context, arg PurgeSecretVersionsParams) error
+	// Server side. Tombstones are kept for the clients to pull the deletion.
+	PurgeSecrets(ctx context.Context, deletedBefore time.Time) ([]Secret, error)
+	// Local changes are pushed on top of the server revision under its server identity.
+	RebaseLocalSecret(ctx context.Context, arg RebaseLocalSecretParams) error
+	// Client side copy of the server secret. The replaced server copy is kept as a version.
+	ReplaceLocalSecret(ctx context.Context, arg ReplaceLocalSecretParams) error
+	// Client side. Restored secret is pushed as an undelete.
+	RestoreLocalSecret(ctx context.Context, arg RestoreLocalSecretParams) error
+	RevokeOtherRefreshTokens(ctx context.Context, arg RevokeOtherRefreshTokensParams) error
+	RevokeOtherSessions(ctx context.Context, arg RevokeOtherSessionsParams) (int64, error)
+	RevokeRefreshTokenFamily(ctx context.Context, family string) error
+	RevokeSession(ctx context.Context, arg RevokeSessionParams) (int64, error)
+	// Client side.
+	RewrapLocalSecret(ctx context.Context, arg RewrapLocalSecretParams) error
+	// Only the data key of the value is changed. The secret gets a new revision
+	// for the other devices to pull it.
+	RewrapSecret(ctx context.Context, arg RewrapSecretParams) (int64, error)
+	// Client side.
+	SaveCachedTokens(ctx context.Context, arg SaveCachedTokensParams) error
+	// Client side. The rekey is marked before the server replaces the vault key
+	// and unmarked once the local database follows it.
+	SavePendingRekey(ctx context.Context, arg SavePendingRekeyParams) error
+	SaveSecretConflict(ctx context.Context, arg SaveSecretConflictParams) error
+	// Client side copy of the server vault key.
+	SaveVaultKey(ctx context.Context, arg SaveVaultKeyParams) error
+	// Client side. The secret synced by its plaintext name is moved to its opaque server identity.
+	SetLocalSecretRemoteID(ctx context.Context, arg SetLocalSecretRemoteIDParams) error
+	SetPurgedRevision(ctx context.Context, arg SetPurgedRevisionParams) error
+	SetSyncCursor(ctx context.Context, arg SetSyncCursorParams) error
+	SetTOTPSecret(ctx context.Context, arg SetTOTPSecretParams) error
+	TouchSession(ctx context.Context, arg TouchSessionParams) error
+	// Client side copy of the server deletion. The local value is kept in the trash.
+	TrashLocalSecret(ctx context.Context, arg TrashLocalSecretParams) error
+	UpdateAuditHead(ctx context.Context, arg UpdateAuditHeadParams) error
+	// The secret is only changed if it is based on the current revision,
+	// otherwise no row is returned. The replaced value is kept as a version.
 	UpdateSecret(ctx context.Context, arg UpdateSecretParams) (Secret, error)
+	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error
+	// The vault key is only replaced if it is still the one the new key replaces.
+	UpdateVaultKey(ctx context.Context, arg UpdateVaultKeyParams) (int64, error)
+	// Client side local change to be pushed. The secret keeps the revision it is based on.
+	// The replaced server copy is kept as a version.
+	UpsertLocalSecret(ctx context.Context, arg UpsertLocalSecretParams) (Secret, error)
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (int64, error)
+	// Every code is accepted only once.
+	UseTOTPStep(ctx context.Context, arg UseTOTPStepParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
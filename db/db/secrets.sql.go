@@ -12,12 +12,15 @@ import (
 
 const cleanSecrets = `-- name: CleanSecrets :many
 DELETE FROM secrets
-WHERE deleted = true
-RETURNING id, owner, kind, name, value, created, modified, deleted
+WHERE deleted = true AND dirty = false
+  AND (deleted_at IS NULL OR deleted_at < $1::timestamptz)
+RETURNING id, owner, kind, name, value, created, modified, deleted, revision, dirty, deleted_at, remote_id
 `
 
-func (q *Queries) CleanSecrets(ctx context.Context) ([]Secret, error) {
-	rows, err := q.db.QueryContext(ctx, cleanSecrets)
+// Client side. Deleted secrets are kept in the trash until the deletion
+// is pushed and they get old or are purged.
+func (q *Queries) CleanSecrets(ctx context.Context, deletedBefore time.Time) ([]Secret, error) {
+	rows, err := q.db.QueryContext(ctx, cleanSecrets, deletedBefore)
 	if err != nil {
 		return nil, err
 	}
@@ -34,6 +37,10 @@ func (q *Queries) CleanSecrets(ctx context.Context) ([]Secret, error) {
 			&i.Created,
 			&i.Modified,
 			&i.Deleted,
+			&i.Revision,
+			&i.Dirty,
+			&i.DeletedAt,
+			&i.RemoteID,
 		); err != nil {
 			return nil, err
 		}
@@ -48,6 +55,19 @@ func (q *Queries) CleanSecrets(ctx context.Context) ([]Secret, error) {
 	return items, nil
 }
 
+const clearSecretTombstones = `-- name: ClearSecretTombstones :exec
+UPDATE secrets
+SET value = ''::bytea
+WHERE owner = $1 AND deleted = true
+`
+
+// Values of the deleted secrets are dropped when the vault is rekeyed,
+// the ones still kept in the client trash are rewrapped afterwards.
+func (q *Queries) ClearSecretTombstones(ctx context.Context, owner string) error {
+	_, err := q.db.ExecContext(ctx, clearSecretTombstones, owner)
+	return err
+}
+
 const createSecret = `-- name: CreateSecret :one
 INSERT INTO secrets (
   owner,
@@ -59,7 +79,8 @@ INSERT INTO secrets (
 ) VALUES (
   $1, $2, $3, $4, $5, $6
 )
-RETURNING id, owner, kind, name, value, created, modified, deleted
+ON CONFLICT (owner, kind, name) DO NOTHING
+RETURNING id, owner, kind, name, value, created, modified, deleted, revision, dirty, deleted_at, remote_id
 `
 
 type CreateSecretParams struct {
@@ -71,6 +92,7 @@ type CreateSecretParams struct {
 	Modified time.Time
 }
 
+// No row is returned if the secret already exists.
 func (q *Queries) CreateSecret(ctx context.Context, arg CreateSecretParams) (Secret, error) {
 	row := q.db.QueryRowContext(ctx, createSecret,
 		arg.Owner,
@@ -90,6 +112,10 @@ func (q *Queries) CreateSecret(ctx context.Context, arg CreateSecretParams) (Sec
 		&i.Created,
 		&i.Modified,
 		&i.Deleted,
+		&i.Revision,
+		&i.Dirty,
+		&i.DeletedAt,
+		&i.RemoteID,
 	)
 	return i, err
 }
@@ -110,10 +136,81 @@ func (q *Queries) DeleteSecret(ctx context.Context, arg DeleteSecretParams) erro
 	return err
 }
 
+const forgetSecret = `-- name: ForgetSecret :execrows
+WITH deleted_versions AS (
+  DELETE FROM secret_versions
+  WHERE secret_versions.owner = $1 AND secret_versions.kind = $2 AND secret_versions.name = $3
+)
+DELETE FROM secrets
+WHERE secrets.owner = $1 AND secrets.kind = $2 AND secrets.name = $3
+`
+
+type ForgetSecretParams struct {
+	Owner string
+	Kind  int32
+	Name  string
+}
+
+// Server side. The secret is removed with its versions and leaves no tombstone.
+func (q *Queries) ForgetSecret(ctx context.Context, arg ForgetSecretParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, forgetSecret, arg.Owner, arg.Kind, arg.Name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getDirtySecrets = `-- name: GetDirtySecrets :many
+SELECT id, owner, kind, name, value, created, modified, deleted, revision, dirty, deleted_at, remote_id FROM secrets
+WHERE secrets.owner = $1 AND secrets.dirty = true
+  AND NOT EXISTS (
+    SELECT 1 FROM secret_conflicts
+    WHERE secret_conflicts.owner = secrets.owner
+      AND secret_conflicts.kind = secrets.kind
+      AND secret_conflicts.name = secrets.name
+  )
+`
+
+// Conflicted secrets are not pushed until resolved.
+func (q *Queries) GetDirtySecrets(ctx context.Context, owner string) ([]Secret, error) {
+	rows, err := q.db.QueryContext(ctx, getDirtySecrets, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Secret
+	for rows.Next() {
+		var i Secret
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Kind,
+			&i.Name,
+			&i.Value,
+			&i.Created,
+			&i.Modified,
+			&i.Deleted,
+			&i.Revision,
+			&i.Dirty,
+			&i.DeletedAt,
+			&i.RemoteID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getSecret = `-- name: GetSecret :one
-SELECT id, owner, kind, name, value, created, modified, deleted FROM secrets
+SELECT id, owner, kind, name, value, created, modified, deleted, revision, dirty, deleted_at, remote_id FROM secrets
 WHERE owner = $1 AND kind = $2 AND name = $3
-LIMIT 1
 `
 
 type GetSecretParams struct {
@@ -134,12 +231,97 @@ func (q *Queries) GetSecret(ctx context.Context, arg GetSecretParams) (Secret, e
 		&i.Created,
 		&i.Modified,
 		&i.Deleted,
+		&i.Revision,
+		&i.Dirty,
+		&i.DeletedAt,
+		&i.RemoteID,
+	)
+	return i, err
+}
+
+const getSecretByRemoteID = `-- name: GetSecretByRemoteID :one
+SELECT id, owner, kind, name, value, created, modified, deleted, revision, dirty, deleted_at, remote_id FROM secrets
+WHERE owner = $1 AND remote_id = $2 AND remote_id <> ''
+`
+
+type GetSecretByRemoteIDParams struct {
+	Owner    string
+	RemoteID string
+}
+
+// Client side.
+func (q *Queries) GetSecretByRemoteID(ctx context.Context, arg GetSecretByRemoteIDParams) (Secret, error) {
+	row := q.db.QueryRowContext(ctx, getSecretByRemoteID, arg.Owner, arg.RemoteID)
+	var i Secret
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Kind,
+		&i.Name,
+		&i.Value,
+		&i.Created,
+		&i.Modified,
+		&i.Deleted,
+		&i.Revision,
+		&i.Dirty,
+		&i.DeletedAt,
+		&i.RemoteID,
 	)
 	return i, err
 }
 
+const getSecretChanges = `-- name: GetSecretChanges :many
+SELECT id, owner, kind, name, value, created, modified, deleted, revision, dirty, deleted_at, remote_id FROM secrets
+WHERE owner = $1 AND revision > $2
+ORDER BY revision
+LIMIT $3
+`
+
+type GetSecretChangesParams struct {
+	Owner    string
+	Revision int64
+	Limit    int32
+}
+
+// Changed and deleted secrets of the user after the revision.
+func (q *Queries) GetSecretChanges(ctx context.Context, arg GetSecretChangesParams) ([]Secret, error) {
+	rows, err := q.db.QueryContext(ctx, getSecretChanges, arg.Owner, arg.Revision, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Secret
+	for rows.Next() {
+		var i Secret
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Kind,
+			&i.Name,
+			&i.Value,
+			&i.Created,
+			&i.Modified,
+			&i.Deleted,
+			&i.Revision,
+			&i.Dirty,
+			&i.DeletedAt,
+			&i.RemoteID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getSecretsByKind = `-- name: GetSecretsByKind :many
-SELECT id, owner, kind, name, value, created, modified, deleted FROM secrets
+SELECT id, owner, kind, name, value, created, modified, deleted, revision, dirty, deleted_at, remote_id FROM secrets
 WHERE owner = $1 AND kind = $2
 ORDER BY modified DESC
 `
@@ -167,6 +349,10 @@ func (q *Queries) GetSecretsByKind(ctx context.Context, arg GetSecretsByKindPara
 			&i.Created,
 			&i.Modified,
 			&i.Deleted,
+			&i.Revision,
+			&i.Dirty,
+			&i.DeletedAt,
+			&i.RemoteID,
 		); err != nil {
 			return nil, err
 		}
@@ -182,7 +368,7 @@ func (q *Queries) GetSecretsByKind(ctx context.Context, arg GetSecretsByKindPara
 }
 
 const getSecretsByUser = `-- name: GetSecretsByUser :many
-SELECT id, owner, kind, name, value, created, modified, deleted FROM secrets
+SELECT id, owner, kind, name, value, created, modified, deleted, revision, dirty, deleted_at, remote_id FROM secrets
 WHERE owner = $1
 ORDER BY modified DESC
 `
@@ -205,6 +391,10 @@ func (q *Queries) GetSecretsByUser(ctx context.Context, owner string) ([]Secret,
 			&i.Created,
 			&i.Modified,
 			&i.Deleted,
+			&i.Revision,
+			&i.Dirty,
+			&i.DeletedAt,
+			&i.RemoteID,
 		); err != nil {
 			return nil, err
 		}
@@ -219,49 +409,495 @@ func (q *Queries) GetSecretsByUser(ctx context.Context, owner string) ([]Secret,
 	return items, nil
 }
 
-const markSecretDeleted = `-- name: MarkSecretDeleted :exec
+const getTrashedSecrets = `-- name: GetTrashedSecrets :many
+SELECT id, owner, kind, name, value, created, modified, deleted, revision, dirty, deleted_at, remote_id FROM secrets
+WHERE owner = $1 AND deleted = true AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+`
+
+func (q *Queries) GetTrashedSecrets(ctx context.Context, owner string) ([]Secret, error) {
+	rows, err := q.db.QueryContext(ctx, getTrashedSecrets, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Secret
+	for rows.Next() {
+		var i Secret
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Kind,
+			&i.Name,
+			&i.Value,
+			&i.Created,
+			&i.Modified,
+			&i.Deleted,
+			&i.Revision,
+			&i.Dirty,
+			&i.DeletedAt,
+			&i.RemoteID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockUserSecrets = `-- name: LockUserSecrets :exec
+SELECT pg_advisory_xact_lock(hashtext($1::varchar))
+`
+
+// Serializes user secret changes until the end of the transaction
+// so the revisions are committed in order.
+func (q *Queries) LockUserSecrets(ctx context.Context, owner string) error {
+	_, err := q.db.ExecContext(ctx, lockUserSecrets, owner)
+	return err
+}
+
+const lockUserSecretsShared = `-- name: LockUserSecretsShared :exec
+SELECT pg_advisory_xact_lock_shared(hashtext($1::varchar))
+`
+
+// Waits for the user secret changes in progress to read committed revisions only.
+func (q *Queries) LockUserSecretsShared(ctx context.Context, owner string) error {
+	_, err := q.db.ExecContext(ctx, lockUserSecretsShared, owner)
+	return err
+}
+
+const markLocalSecretDeleted = `-- name: MarkLocalSecretDeleted :exec
 UPDATE secrets
-SET deleted = true
+SET deleted = true,
+  deleted_at = now(),
+  dirty = true
 WHERE owner = $1 AND kind = $2 AND name = $3
 `
 
-type MarkSecretDeletedParams struct {
+type MarkLocalSecretDeletedParams struct {
 	Owner string
 	Kind  int32
 	Name  string
 }
 
-func (q *Queries) MarkSecretDeleted(ctx context.Context, arg MarkSecretDeletedParams) error {
-	_, err := q.db.ExecContext(ctx, markSecretDeleted, arg.Owner, arg.Kind, arg.Name)
+// Client side. Deleted secret is moved to the trash.
+func (q *Queries) MarkLocalSecretDeleted(ctx context.Context, arg MarkLocalSecretDeletedParams) error {
+	_, err := q.db.ExecContext(ctx, markLocalSecretDeleted, arg.Owner, arg.Kind, arg.Name)
 	return err
 }
 
-const updateSecret = `-- name: UpdateSecret :one
+const markSecretSynced = `-- name: MarkSecretSynced :exec
 UPDATE secrets
-  set value = $4,
-  created = $5,
-  modified = $6
+SET revision = $4,
+  dirty = NOT (modified = $5 AND deleted = $6)
 WHERE owner = $1 AND kind = $2 AND name = $3
-RETURNING id, owner, kind, name, value, created, modified, deleted
 `
 
-type UpdateSecretParams struct {
+type MarkSecretSyncedParams struct {
+	Owner    string
+	Kind     int32
+	Name     string
+	Revision int64
+	Modified time.Time
+	Deleted  bool
+}
+
+// Pushed secret gets its new revision. Secrets changed again while being pushed stay dirty.
+func (q *Queries) MarkSecretSynced(ctx context.Context, arg MarkSecretSyncedParams) error {
+	_, err := q.db.ExecContext(ctx, markSecretSynced,
+		arg.Owner,
+		arg.Kind,
+		arg.Name,
+		arg.Revision,
+		arg.Modified,
+		arg.Deleted,
+	)
+	return err
+}
+
+const markSecretsDirty = `-- name: MarkSecretsDirty :exec
+UPDATE secrets
+SET dirty = true
+WHERE owner = $1
+`
+
+func (q *Queries) MarkSecretsDirty(ctx context.Context, owner string) error {
+	_, err := q.db.ExecContext(ctx, markSecretsDirty, owner)
+	return err
+}
+
+const purgeLocalSecret = `-- name: PurgeLocalSecret :exec
+UPDATE secrets
+SET value = ''::bytea,
+  deleted_at = NULL,
+  dirty = true
+WHERE owner = $1 AND kind = $2 AND name = $3 AND deleted = true
+`
+
+type PurgeLocalSecretParams struct {
+	Owner string
+	Kind  int32
+	Name  string
+}
+
+// Client side. Purged secret is removed from the trash right away and its value is dropped.
+// The deletion is pushed without the value and the secret is removed from the db afterwards.
+func (q *Queries) PurgeLocalSecret(ctx context.Context, arg PurgeLocalSecretParams) error {
+	_, err := q.db.ExecContext(ctx, purgeLocalSecret, arg.Owner, arg.Kind, arg.Name)
+	return err
+}
+
+const purgeSecrets = `-- name: PurgeSecrets :many
+DELETE FROM secrets
+WHERE deleted = true AND deleted_at < $1::timestamptz
+RETURNING id, owner, kind, name, value, created, modified, deleted, revision, dirty, deleted_at, remote_id
+`
+
+// Server side. Tombstones are kept for the clients to pull the deletion.
+func (q *Queries) PurgeSecrets(ctx context.Context, deletedBefore time.Time) ([]Secret, error) {
+	rows, err := q.db.QueryContext(ctx, purgeSecrets, deletedBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Secret
+	for rows.Next() {
+		var i Secret
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Kind,
+			&i.Name,
+			&i.Value,
+			&i.Created,
+			&i.Modified,
+			&i.Deleted,
+			&i.Revision,
+			&i.Dirty,
+			&i.DeletedAt,
+			&i.RemoteID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const replaceLocalSecret = `-- name: ReplaceLocalSecret :exec
+WITH previous AS (
+  INSERT INTO secret_versions (owner, kind, name, value, modified, revision)
+  SELECT secrets.owner, secrets.kind, secrets.name, secrets.value, secrets.modified, secrets.revision
+  FROM secrets
+  WHERE secrets.owner = $1 AND secrets.kind = $2 AND secrets.name = $3
+    AND secrets.revision > 0 AND secrets.deleted = false AND secrets.dirty = false
+  ON CONFLICT (owner, kind, name, revision) DO NOTHING
+)
+INSERT INTO secrets (
+  owner,
+  kind,
+  name,
+  value,
+  created,
+  modified,
+  revision,
+  dirty,
+  remote_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, false, $8
+)
+ON CONFLICT (owner, kind, name) DO UPDATE
+SET value = EXCLUDED.value,
+  created = EXCLUDED.created,
+  modified = EXCLUDED.modified,
+  revision = EXCLUDED.revision,
+  remote_id = EXCLUDED.remote_id,
+  deleted = false,
+  deleted_at = NULL,
+  dirty = false
+`
+
+type ReplaceLocalSecretParams struct {
 	Owner    string
 	Kind     int32
 	Name     string
 	Value    []byte
 	Created  time.Time
 	Modified time.Time
+	Revision int64
+	RemoteID string
+}
+
+// Client side copy of the server secret. The replaced server copy is kept as a version.
+func (q *Queries) ReplaceLocalSecret(ctx context.Context, arg ReplaceLocalSecretParams) error {
+	_, err := q.db.ExecContext(ctx, replaceLocalSecret,
+		arg.Owner,
+		arg.Kind,
+		arg.Name,
+		arg.Value,
+		arg.Created,
+		arg.Modified,
+		arg.Revision,
+		arg.RemoteID,
+	)
+	return err
+}
+
+const restoreLocalSecret = `-- name: RestoreLocalSecret :exec
+UPDATE secrets
+SET deleted = false,
+  deleted_at = NULL,
+  modified = now(),
+  dirty = true
+WHERE owner = $1 AND kind = $2 AND name = $3 AND deleted = true
+`
+
+type RestoreLocalSecretParams struct {
+	Owner string
+	Kind  int32
+	Name  string
+}
+
+// Client side. Restored secret is pushed as an undelete.
+func (q *Queries) RestoreLocalSecret(ctx context.Context, arg RestoreLocalSecretParams) error {
+	_, err := q.db.ExecContext(ctx, restoreLocalSecret, arg.Owner, arg.Kind, arg.Name)
+	return err
+}
+
+const rewrapLocalSecret = `-- name: RewrapLocalSecret :exec
+UPDATE secrets
+SET value = $4
+WHERE owner = $1 AND kind = $2 AND name = $3
+`
+
+type RewrapLocalSecretParams struct {
+	Owner string
+	Kind  int32
+	Name  string
+	Value []byte
+}
+
+// Client side.
+func (q *Queries) RewrapLocalSecret(ctx context.Context, arg RewrapLocalSecretParams) error {
+	_, err := q.db.ExecContext(ctx, rewrapLocalSecret,
+		arg.Owner,
+		arg.Kind,
+		arg.Name,
+		arg.Value,
+	)
+	return err
+}
+
+const rewrapSecret = `-- name: RewrapSecret :execrows
+UPDATE secrets
+SET value = $4,
+  revision = nextval('secrets_revision_seq')
+WHERE owner = $1 AND kind = $2 AND name = $3 AND revision = $5 AND deleted = $6
+`
+
+type RewrapSecretParams struct {
+	Owner        string
+	Kind         int32
+	Name         string
+	Value        []byte
+	BaseRevision int64
+	Deleted      bool
+}
+
+// Only the data key of the value is changed. The secret gets a new revision
+// for the other devices to pull it.
+func (q *Queries) RewrapSecret(ctx context.Context, arg RewrapSecretParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, rewrapSecret,
+		arg.Owner,
+		arg.Kind,
+		arg.Name,
+		arg.Value,
+		arg.BaseRevision,
+		arg.Deleted,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
+const setLocalSecretRemoteID = `-- name: SetLocalSecretRemoteID :exec
+UPDATE secrets
+SET remote_id = $4,
+  revision = $5
+WHERE owner = $1 AND kind = $2 AND name = $3
+`
+
+type SetLocalSecretRemoteIDParams struct {
+	Owner    string
+	Kind     int32
+	Name     string
+	RemoteID string
+	Revision int64
+}
+
+// Client side. The secret synced by its plaintext name is moved to its opaque server identity.
+func (q *Queries) SetLocalSecretRemoteID(ctx context.Context, arg SetLocalSecretRemoteIDParams) error {
+	_, err := q.db.ExecContext(ctx, setLocalSecretRemoteID,
+		arg.Owner,
+		arg.Kind,
+		arg.Name,
+		arg.RemoteID,
+		arg.Revision,
+	)
+	return err
+}
+
+const trashLocalSecret = `-- name: TrashLocalSecret :exec
+UPDATE secrets
+SET deleted = true,
+  deleted_at = COALESCE(deleted_at, now()),
+  revision = $4,
+  dirty = false
+WHERE owner = $1 AND kind = $2 AND name = $3
+`
+
+type TrashLocalSecretParams struct {
+	Owner    string
+	Kind     int32
+	Name     string
+	Revision int64
+}
+
+// Client side copy of the server deletion. The local value is kept in the trash.
+func (q *Queries) TrashLocalSecret(ctx context.Context, arg TrashLocalSecretParams) error {
+	_, err := q.db.ExecContext(ctx, trashLocalSecret,
+		arg.Owner,
+		arg.Kind,
+		arg.Name,
+		arg.Revision,
+	)
+	return err
+}
+
+const updateSecret = `-- name: UpdateSecret :one
+WITH previous AS (
+  INSERT INTO secret_versions (owner, kind, name, value, modified, revision)
+  SELECT secrets.owner, secrets.kind, secrets.name, secrets.value, secrets.modified, secrets.revision
+  FROM secrets
+  WHERE secrets.owner = $1 AND secrets.kind = $2 AND secrets.name = $3
+    AND secrets.revision = $7 AND secrets.deleted = false
+  ON CONFLICT (owner, kind, name, revision) DO NOTHING
+)
+UPDATE secrets
+SET value = $4,
+  modified = $5,
+  deleted = $6,
+  deleted_at = CASE WHEN $6::boolean THEN now() END,
+  revision = nextval('secrets_revision_seq')
+WHERE secrets.owner = $1 AND secrets.kind = $2 AND secrets.name = $3 AND secrets.revision = $7
+RETURNING id, owner, kind, name, value, created, modified, deleted, revision, dirty, deleted_at, remote_id
+`
+
+type UpdateSecretParams struct {
+	Owner        string
+	Kind         int32
+	Name         string
+	Value        []byte
+	Modified     time.Time
+	Deleted      bool
+	BaseRevision int64
+}
+
+// The secret is only changed if it is based on the current revision,
+// otherwise no row is returned. The replaced value is kept as a version.
 func (q *Queries) UpdateSecret(ctx context.Context, arg UpdateSecretParams) (Secret, error) {
 	row := q.db.QueryRowContext(ctx, updateSecret,
+		arg.Owner,
+		arg.Kind,
+		arg.Name,
+		arg.Value,
+		arg.Modified,
+		arg.Deleted,
+		arg.BaseRevision,
+	)
+	var i Secret
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Kind,
+		&i.Name,
+		&i.Value,
+		&i.Created,
+		&i.Modified,
+		&i.Deleted,
+		&i.Revision,
+		&i.Dirty,
+		&i.DeletedAt,
+		&i.RemoteID,
+	)
+	return i, err
+}
+
+const upsertLocalSecret = `-- name: UpsertLocalSecret :one
+WITH previous AS (
+  INSERT INTO secret_versions (owner, kind, name, value, modified, revision)
+  SELECT secrets.owner, secrets.kind, secrets.name, secrets.value, secrets.modified, secrets.revision
+  FROM secrets
+  WHERE secrets.owner = $1 AND secrets.kind = $2 AND secrets.name = $3
+    AND secrets.revision > 0 AND secrets.deleted = false AND secrets.dirty = false
+  ON CONFLICT (owner, kind, name, revision) DO NOTHING
+)
+INSERT INTO secrets (
+  owner,
+  kind,
+  name,
+  value,
+  created,
+  modified,
+  revision,
+  dirty,
+  remote_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6, 0, true, $7
+)
+ON CONFLICT (owner, kind, name) DO UPDATE
+SET value = EXCLUDED.value,
+  modified = EXCLUDED.modified,
+  deleted = false,
+  deleted_at = NULL,
+  dirty = true
+RETURNING id, owner, kind, name, value, created, modified, deleted, revision, dirty, deleted_at, remote_id
+`
+
+type UpsertLocalSecretParams struct {
+	Owner    string
+	Kind     int32
+	Name     string
+	Value    []byte
+	Created  time.Time
+	Modified time.Time
+	RemoteID string
+}
+
+// Client side local change to be pushed. The secret keeps the revision it is based on.
+// The replaced server copy is kept as a version.
+func (q *Queries) UpsertLocalSecret(ctx context.Context, arg UpsertLocalSecretParams) (Secret, error) {
+	row := q.db.QueryRowContext(ctx, upsertLocalSecret,
 		arg.Owner,
 		arg.Kind,
 		arg.Name,
 		arg.Value,
 		arg.Created,
 		arg.Modified,
+		arg.RemoteID,
 	)
 	var i Secret
 	err := row.Scan(
@@ -273,6 +909,10 @@ func (q *Queries) UpdateSecret(ctx context.Context, arg UpdateSecretParams) (Sec
 		&i.Created,
 		&i.Modified,
 		&i.Deleted,
+		&i.Revision,
+		&i.Dirty,
+		&i.DeletedAt,
+		&i.RemoteID,
 	)
 	return i, err
 }
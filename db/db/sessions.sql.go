@@ -0,0 +1,182 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.16.0
+// source: sessions.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const cleanSessions = `-- name: CleanSessions :execrows
+DELETE FROM sessions
+WHERE revoked = true OR last_seen < $1
+`
+
+func (q *Queries) CleanSessions(ctx context.Context, lastSeen time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, cleanSessions, lastSeen)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createSession = `-- name: CreateSession :one
+INSERT INTO sessions (
+  id,
+  username,
+  device,
+  client_version,
+  ip
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING id, username, device, client_version, ip, created, last_seen, revoked
+`
+
+type CreateSessionParams struct {
+	ID            string
+	Username      string
+	Device        string
+	ClientVersion string
+	Ip            string
+}
+
+func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
+	row := q.db.QueryRowContext(ctx, createSession,
+		arg.ID,
+		arg.Username,
+		arg.Device,
+		arg.ClientVersion,
+		arg.Ip,
+	)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Device,
+		&i.ClientVersion,
+		&i.Ip,
+		&i.Created,
+		&i.LastSeen,
+		&i.Revoked,
+	)
+	return i, err
+}
+
+const getSession = `-- name: GetSession :one
+SELECT id, username, device, client_version, ip, created, last_seen, revoked FROM sessions
+WHERE id = $1
+LIMIT 1
+`
+
+func (q *Queries) GetSession(ctx context.Context, id string) (Session, error) {
+	row := q.db.QueryRowContext(ctx, getSession, id)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Device,
+		&i.ClientVersion,
+		&i.Ip,
+		&i.Created,
+		&i.LastSeen,
+		&i.Revoked,
+	)
+	return i, err
+}
+
+const getSessionsByUser = `-- name: GetSessionsByUser :many
+SELECT id, username, device, client_version, ip, created, last_seen, revoked FROM sessions
+WHERE username = $1 AND revoked = false
+ORDER BY last_seen DESC
+`
+
+func (q *Queries) GetSessionsByUser(ctx context.Context, username string) ([]Session, error) {
+	rows, err := q.db.QueryContext(ctx, getSessionsByUser, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Session
+	for rows.Next() {
+		var i Session
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Device,
+			&i.ClientVersion,
+			&i.Ip,
+			&i.Created,
+			&i.LastSeen,
+			&i.Revoked,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeOtherSessions = `-- name: RevokeOtherSessions :execrows
+UPDATE sessions
+SET revoked = true
+WHERE username = $1 AND id <> $2 AND revoked = false
+`
+
+type RevokeOtherSessionsParams struct {
+	Username string
+	ID       string
+}
+
+func (q *Queries) RevokeOtherSessions(ctx context.Context, arg RevokeOtherSessionsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, revokeOtherSessions, arg.Username, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const revokeSession = `-- name: RevokeSession :execrows
+UPDATE sessions
+SET revoked = true
+WHERE id = $1 AND username = $2
+`
+
+type RevokeSessionParams struct {
+	ID       string
+	Username string
+}
+
+func (q *Queries) RevokeSession(ctx context.Context, arg RevokeSessionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, revokeSession, arg.ID, arg.Username)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const touchSession = `-- name: TouchSession :exec
+UPDATE sessions
+SET last_seen = now(),
+  ip = $2
+WHERE id = $1
+`
+
+type TouchSessionParams struct {
+	ID string
+	Ip string
+}
+
+func (q *Queries) TouchSession(ctx context.Context, arg TouchSessionParams) error {
+	_, err := q.db.ExecContext(ctx, touchSession, arg.ID, arg.Ip)
+	return err
+}
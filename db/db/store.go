@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Store provides all the queries and runs them in transactions.
+type Store interface {
+	Querier
+	// WithTx runs fn with the queries of a single transaction.
+	// The transaction is rolled back if fn fails and committed otherwise.
+	WithTx(ctx context.Context, fn func(Querier) error) error
+}
+
+type SQLStore struct {
+	*Queries
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) Store {
+	return &SQLStore{
+		Queries: New(db),
+		db:      db,
+	}
+}
+
+func (s *SQLStore) WithTx(ctx context.Context, fn func(Querier) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	err = fn(s.Queries.WithTx(tx))
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w, rollback failed: %s", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,76 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.16.0
+// source: sync.sql
+
+package db
+
+import (
+	"context"
+)
+
+const getPurgedRevision = `-- name: GetPurgedRevision :one
+SELECT revision FROM purged_revisions
+WHERE owner = $1
+`
+
+func (q *Queries) GetPurgedRevision(ctx context.Context, owner string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getPurgedRevision, owner)
+	var revision int64
+	err := row.Scan(&revision)
+	return revision, err
+}
+
+const getSyncCursor = `-- name: GetSyncCursor :one
+SELECT revision FROM sync_cursors
+WHERE owner = $1
+`
+
+func (q *Queries) GetSyncCursor(ctx context.Context, owner string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getSyncCursor, owner)
+	var revision int64
+	err := row.Scan(&revision)
+	return revision, err
+}
+
+const setPurgedRevision = `-- name: SetPurgedRevision :exec
+INSERT INTO purged_revisions (
+  owner,
+  revision
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (owner) DO UPDATE
+SET revision = GREATEST(purged_revisions.revision, EXCLUDED.revision)
+`
+
+type SetPurgedRevisionParams struct {
+	Owner    string
+	Revision int64
+}
+
+func (q *Queries) SetPurgedRevision(ctx context.Context, arg SetPurgedRevisionParams) error {
+	_, err := q.db.ExecContext(ctx, setPurgedRevision, arg.Owner, arg.Revision)
+	return err
+}
+
+const setSyncCursor = `-- name: SetSyncCursor :exec
+INSERT INTO sync_cursors (
+  owner,
+  revision
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (owner) DO UPDATE
+SET revision = EXCLUDED.revision
+`
+
+type SetSyncCursorParams struct {
+	Owner    string
+	Revision int64
+}
+
+func (q *Queries) SetSyncCursor(ctx context.Context, arg SetSyncCursorParams) error {
+	_, err := q.db.ExecContext(ctx, setSyncCursor, arg.Owner, arg.Revision)
+	return err
+}
@@ -0,0 +1,165 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.16.0
+// source: tokens.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const cleanRefreshTokens = `-- name: CleanRefreshTokens :execrows
+DELETE FROM refresh_tokens
+WHERE expires_at < now()
+`
+
+func (q *Queries) CleanRefreshTokens(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, cleanRefreshTokens)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createRefreshToken = `-- name: CreateRefreshToken :one
+INSERT INTO refresh_tokens (
+  hash,
+  family,
+  username,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING id, hash, family, username, expires_at, used, revoked, created
+`
+
+type CreateRefreshTokenParams struct {
+	Hash      string
+	Family    string
+	Username  string
+	ExpiresAt time.Time
+}
+
+func (q *Queries) CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) (RefreshToken, error) {
+	row := q.db.QueryRowContext(ctx, createRefreshToken,
+		arg.Hash,
+		arg.Family,
+		arg.Username,
+		arg.ExpiresAt,
+	)
+	var i RefreshToken
+	err := row.Scan(
+		&i.ID,
+		&i.Hash,
+		&i.Family,
+		&i.Username,
+		&i.ExpiresAt,
+		&i.Used,
+		&i.Revoked,
+		&i.Created,
+	)
+	return i, err
+}
+
+const getCachedTokens = `-- name: GetCachedTokens :one
+SELECT owner, token, refresh_token FROM cached_tokens
+WHERE owner = $1
+`
+
+// Client side.
+func (q *Queries) GetCachedTokens(ctx context.Context, owner string) (CachedToken, error) {
+	row := q.db.QueryRowContext(ctx, getCachedTokens, owner)
+	var i CachedToken
+	err := row.Scan(&i.Owner, &i.Token, &i.RefreshToken)
+	return i, err
+}
+
+const getRefreshToken = `-- name: GetRefreshToken :one
+SELECT id, hash, family, username, expires_at, used, revoked, created FROM refresh_tokens
+WHERE hash = $1
+LIMIT 1
+`
+
+func (q *Queries) GetRefreshToken(ctx context.Context, hash string) (RefreshToken, error) {
+	row := q.db.QueryRowContext(ctx, getRefreshToken, hash)
+	var i RefreshToken
+	err := row.Scan(
+		&i.ID,
+		&i.Hash,
+		&i.Family,
+		&i.Username,
+		&i.ExpiresAt,
+		&i.Used,
+		&i.Revoked,
+		&i.Created,
+	)
+	return i, err
+}
+
+const markRefreshTokenUsed = `-- name: MarkRefreshTokenUsed :execrows
+UPDATE refresh_tokens
+SET used = true
+WHERE hash = $1 AND used = false
+`
+
+func (q *Queries) MarkRefreshTokenUsed(ctx context.Context, hash string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markRefreshTokenUsed, hash)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const revokeOtherRefreshTokens = `-- name: RevokeOtherRefreshTokens :exec
+UPDATE refresh_tokens
+SET revoked = true
+WHERE username = $1 AND family <> $2
+`
+
+type RevokeOtherRefreshTokensParams struct {
+	Username string
+	Family   string
+}
+
+func (q *Queries) RevokeOtherRefreshTokens(ctx context.Context, arg RevokeOtherRefreshTokensParams) error {
+	_, err := q.db.ExecContext(ctx, revokeOtherRefreshTokens, arg.Username, arg.Family)
+	return err
+}
+
+const revokeRefreshTokenFamily = `-- name: RevokeRefreshTokenFamily :exec
+UPDATE refresh_tokens
+SET revoked = true
+WHERE family = $1
+`
+
+func (q *Queries) RevokeRefreshTokenFamily(ctx context.Context, family string) error {
+	_, err := q.db.ExecContext(ctx, revokeRefreshTokenFamily, family)
+	return err
+}
+
+const saveCachedTokens = `-- name: SaveCachedTokens :exec
+INSERT INTO cached_tokens (
+  owner,
+  token,
+  refresh_token
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (owner) DO UPDATE
+SET token = EXCLUDED.token,
+  refresh_token = EXCLUDED.refresh_token
+`
+
+type SaveCachedTokensParams struct {
+	Owner        string
+	Token        string
+	RefreshToken string
+}
+
+// Client side.
+func (q *Queries) SaveCachedTokens(ctx context.Context, arg SaveCachedTokensParams) error {
+	_, err := q.db.ExecContext(ctx, saveCachedTokens, arg.Owner, arg.Token, arg.RefreshToken)
+	return err
+}
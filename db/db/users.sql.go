@@ -9,6 +9,22 @@ import (
 	"context"
 )
 
+const createLocalUser = `-- name: CreateLocalUser :exec
+INSERT INTO users (
+  name,
+  passhash
+) VALUES (
+  $1, ''
+)
+ON CONFLICT (name) DO NOTHING
+`
+
+// Client local databases only keep the owner of the cached secrets.
+func (q *Queries) CreateLocalUser(ctx context.Context, name string) error {
+	_, err := q.db.ExecContext(ctx, createLocalUser, name)
+	return err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (
   name,
@@ -16,7 +32,7 @@ INSERT INTO users (
 ) VALUES (
   $1, $2
 )
-RETURNING id, name, passhash
+RETURNING id, name, passhash, totp_secret, totp_enabled, totp_last_step
 `
 
 type CreateUserParams struct {
@@ -27,7 +43,14 @@ type CreateUserParams struct {
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
 	row := q.db.QueryRowContext(ctx, createUser, arg.Name, arg.Passhash)
 	var i User
-	err := row.Scan(&i.ID, &i.Name, &i.Passhash)
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Passhash,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.TotpLastStep,
+	)
 	return i, err
 }
 
@@ -41,8 +64,42 @@ func (q *Queries) DeleteUser(ctx context.Context, name string) error {
 	return err
 }
 
+const deleteUserWithSecrets = `-- name: DeleteUserWithSecrets :exec
+WITH deleted_secrets AS (
+  DELETE FROM secrets WHERE owner = $1
+), deleted_sessions AS (
+  DELETE FROM sessions WHERE username = $1
+), deleted_tokens AS (
+  DELETE FROM refresh_tokens WHERE username = $1
+), deleted_recovery_codes AS (
+  DELETE FROM recovery_codes WHERE username = $1
+), deleted_sync_cursors AS (
+  DELETE FROM sync_cursors WHERE owner = $1
+), deleted_secret_conflicts AS (
+  DELETE FROM secret_conflicts WHERE owner = $1
+), deleted_purged_revisions AS (
+  DELETE FROM purged_revisions WHERE owner = $1
+), deleted_secret_versions AS (
+  DELETE FROM secret_versions WHERE owner = $1
+), deleted_cached_tokens AS (
+  DELETE FROM cached_tokens WHERE owner = $1
+), deleted_vault_keys AS (
+  DELETE FROM vault_keys WHERE owner = $1
+), deleted_pending_rekeys AS (
+  DELETE FROM pending_rekeys WHERE owner = $1
+)
+DELETE FROM users
+WHERE users.name = $1
+`
+
+// Single statement so the user and all the user data are removed atomically.
+func (q *Queries) DeleteUserWithSecrets(ctx context.Context, name string) error {
+	_, err := q.db.ExecContext(ctx, deleteUserWithSecrets, name)
+	return err
+}
+
 const getUser = `-- name: GetUser :one
-SELECT id, name, passhash FROM users
+SELECT id, name, passhash, totp_secret, totp_enabled, totp_last_step FROM users
 WHERE name = $1
 LIMIT 1
 `
@@ -50,6 +107,29 @@ LIMIT 1
 func (q *Queries) GetUser(ctx context.Context, name string) (User, error) {
 	row := q.db.QueryRowContext(ctx, getUser, name)
 	var i User
-	err := row.Scan(&i.ID, &i.Name, &i.Passhash)
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Passhash,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.TotpLastStep,
+	)
 	return i, err
 }
+
+const updateUserPassword = `-- name: UpdateUserPassword :exec
+UPDATE users
+SET passhash = $2
+WHERE name = $1
+`
+
+type UpdateUserPasswordParams struct {
+	Name     string
+	Passhash string
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserPassword, arg.Name, arg.Passhash)
+	return err
+}
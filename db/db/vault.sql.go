@@ -0,0 +1,193 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.16.0
+// source: vault.sql
+
+package db
+
+import (
+	"context"
+)
+
+const createVaultKey = `-- name: CreateVaultKey :execrows
+INSERT INTO vault_keys (
+  owner,
+  salt,
+  memory,
+  iterations,
+  parallelism,
+  key_check
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (owner) DO NOTHING
+`
+
+type CreateVaultKeyParams struct {
+	Owner       string
+	Salt        []byte
+	Memory      int64
+	Iterations  int64
+	Parallelism int32
+	KeyCheck    []byte
+}
+
+// The first device to create the vault key wins, the others get the stored one.
+func (q *Queries) CreateVaultKey(ctx context.Context, arg CreateVaultKeyParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createVaultKey,
+		arg.Owner,
+		arg.Salt,
+		arg.Memory,
+		arg.Iterations,
+		arg.Parallelism,
+		arg.KeyCheck,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deletePendingRekey = `-- name: DeletePendingRekey :exec
+DELETE FROM pending_rekeys
+WHERE owner = $1
+`
+
+// Client side.
+func (q *Queries) DeletePendingRekey(ctx context.Context, owner string) error {
+	_, err := q.db.ExecContext(ctx, deletePendingRekey, owner)
+	return err
+}
+
+const getPendingRekey = `-- name: GetPendingRekey :one
+SELECT owner, key_check FROM pending_rekeys
+WHERE owner = $1
+`
+
+// Client side.
+func (q *Queries) GetPendingRekey(ctx context.Context, owner string) (PendingRekey, error) {
+	row := q.db.QueryRowContext(ctx, getPendingRekey, owner)
+	var i PendingRekey
+	err := row.Scan(&i.Owner, &i.KeyCheck)
+	return i, err
+}
+
+const getVaultKey = `-- name: GetVaultKey :one
+SELECT owner, salt, memory, iterations, parallelism, key_check FROM vault_keys
+WHERE owner = $1
+`
+
+func (q *Queries) GetVaultKey(ctx context.Context, owner string) (VaultKey, error) {
+	row := q.db.QueryRowContext(ctx, getVaultKey, owner)
+	var i VaultKey
+	err := row.Scan(
+		&i.Owner,
+		&i.Salt,
+		&i.Memory,
+		&i.Iterations,
+		&i.Parallelism,
+		&i.KeyCheck,
+	)
+	return i, err
+}
+
+const savePendingRekey = `-- name: SavePendingRekey :exec
+INSERT INTO pending_rekeys (
+  owner,
+  key_check
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (owner) DO UPDATE
+SET key_check = EXCLUDED.key_check
+`
+
+type SavePendingRekeyParams struct {
+	Owner    string
+	KeyCheck []byte
+}
+
+// Client side. The rekey is marked before the server replaces the vault key
+// and unmarked once the local database follows it.
+func (q *Queries) SavePendingRekey(ctx context.Context, arg SavePendingRekeyParams) error {
+	_, err := q.db.ExecContext(ctx, savePendingRekey, arg.Owner, arg.KeyCheck)
+	return err
+}
+
+const saveVaultKey = `-- name: SaveVaultKey :exec
+INSERT INTO vault_keys (
+  owner,
+  salt,
+  memory,
+  iterations,
+  parallelism,
+  key_check
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (owner) DO UPDATE
+SET salt = EXCLUDED.salt,
+  memory = EXCLUDED.memory,
+  iterations = EXCLUDED.iterations,
+  parallelism = EXCLUDED.parallelism,
+  key_check = EXCLUDED.key_check
+`
+
+type SaveVaultKeyParams struct {
+	Owner       string
+	Salt        []byte
+	Memory      int64
+	Iterations  int64
+	Parallelism int32
+	KeyCheck    []byte
+}
+
+// Client side copy of the server vault key.
+func (q *Queries) SaveVaultKey(ctx context.Context, arg SaveVaultKeyParams) error {
+	_, err := q.db.ExecContext(ctx, saveVaultKey,
+		arg.Owner,
+		arg.Salt,
+		arg.Memory,
+		arg.Iterations,
+		arg.Parallelism,
+		arg.KeyCheck,
+	)
+	return err
+}
+
+const updateVaultKey = `-- name: UpdateVaultKey :execrows
+UPDATE vault_keys
+SET salt = $2,
+  memory = $3,
+  iterations = $4,
+  parallelism = $5,
+  key_check = $6
+WHERE owner = $1 AND key_check = $7
+`
+
+type UpdateVaultKeyParams struct {
+	Owner       string
+	Salt        []byte
+	Memory      int64
+	Iterations  int64
+	Parallelism int32
+	KeyCheck    []byte
+	OldKeyCheck []byte
+}
+
+// The vault key is only replaced if it is still the one the new key replaces.
+func (q *Queries) UpdateVaultKey(ctx context.Context, arg UpdateVaultKeyParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateVaultKey,
+		arg.Owner,
+		arg.Salt,
+		arg.Memory,
+		arg.Iterations,
+		arg.Parallelism,
+		arg.KeyCheck,
+		arg.OldKeyCheck,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
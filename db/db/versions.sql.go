@@ -0,0 +1,186 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.16.0
+// source: versions.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const addSecretVersion = `-- name: AddSecretVersion :exec
+INSERT INTO secret_versions (
+  owner,
+  kind,
+  name,
+  value,
+  modified,
+  revision
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (owner, kind, name, revision) DO NOTHING
+`
+
+type AddSecretVersionParams struct {
+	Owner    string
+	Kind     int32
+	Name     string
+	Value    []byte
+	Modified time.Time
+	Revision int64
+}
+
+// Client side copy of the server version.
+func (q *Queries) AddSecretVersion(ctx context.Context, arg AddSecretVersionParams) error {
+	_, err := q.db.ExecContext(ctx, addSecretVersion,
+		arg.Owner,
+		arg.Kind,
+		arg.Name,
+		arg.Value,
+		arg.Modified,
+		arg.Revision,
+	)
+	return err
+}
+
+const deleteSecretVersions = `-- name: DeleteSecretVersions :exec
+DELETE FROM secret_versions
+WHERE owner = $1
+`
+
+func (q *Queries) DeleteSecretVersions(ctx context.Context, owner string) error {
+	_, err := q.db.ExecContext(ctx, deleteSecretVersions, owner)
+	return err
+}
+
+const getSecretVersion = `-- name: GetSecretVersion :one
+SELECT owner, kind, name, value, modified, revision FROM secret_versions
+WHERE owner = $1 AND kind = $2 AND name = $3 AND revision = $4
+`
+
+type GetSecretVersionParams struct {
+	Owner    string
+	Kind     int32
+	Name     string
+	Revision int64
+}
+
+func (q *Queries) GetSecretVersion(ctx context.Context, arg GetSecretVersionParams) (SecretVersion, error) {
+	row := q.db.QueryRowContext(ctx, getSecretVersion,
+		arg.Owner,
+		arg.Kind,
+		arg.Name,
+		arg.Revision,
+	)
+	var i SecretVersion
+	err := row.Scan(
+		&i.Owner,
+		&i.Kind,
+		&i.Name,
+		&i.Value,
+		&i.Modified,
+		&i.Revision,
+	)
+	return i, err
+}
+
+const getSecretVersions = `-- name: GetSecretVersions :many
+SELECT owner, kind, name, value, modified, revision FROM secret_versions
+WHERE owner = $1 AND kind = $2 AND name = $3
+ORDER BY revision DESC
+LIMIT $4
+`
+
+type GetSecretVersionsParams struct {
+	Owner string
+	Kind  int32
+	Name  string
+	Limit int32
+}
+
+func (q *Queries) GetSecretVersions(ctx context.Context, arg GetSecretVersionsParams) ([]SecretVersion, error) {
+	rows, err := q.db.QueryContext(ctx, getSecretVersions,
+		arg.Owner,
+		arg.Kind,
+		arg.Name,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SecretVersion
+	for rows.Next() {
+		var i SecretVersion
+		if err := rows.Scan(
+			&i.Owner,
+			&i.Kind,
+			&i.Name,
+			&i.Value,
+			&i.Modified,
+			&i.Revision,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const pruneSecretVersions = `-- name: PruneSecretVersions :execrows
+DELETE FROM secret_versions
+WHERE (owner, kind, name, revision) IN (
+  SELECT ranked.owner, ranked.kind, ranked.name, ranked.revision
+  FROM (
+    SELECT secret_versions.owner, secret_versions.kind, secret_versions.name, secret_versions.revision,
+      row_number() OVER (
+        PARTITION BY secret_versions.owner, secret_versions.kind, secret_versions.name
+        ORDER BY secret_versions.revision DESC
+      ) AS position
+    FROM secret_versions
+  ) AS ranked
+  WHERE ranked.position > $1::int
+)
+OR NOT EXISTS (
+  SELECT 1 FROM secrets
+  WHERE secrets.owner = secret_versions.owner
+    AND secrets.kind = secret_versions.kind
+    AND secrets.name = secret_versions.name
+)
+`
+
+// Only the latest versions of every secret are kept.
+// Versions of the secrets no longer stored are removed too.
+func (q *Queries) PruneSecretVersions(ctx context.Context, keep int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, pruneSecretVersions, keep)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const purgeSecretVersions = `-- name: PurgeSecretVersions :exec
+DELETE FROM secret_versions
+WHERE owner = $1 AND kind = $2 AND name = $3
+`
+
+type PurgeSecretVersionsParams struct {
+	Owner string
+	Kind  int32
+	Name  string
+}
+
+// Versions of the purged secret are removed right away.
+func (q *Queries) PurgeSecretVersions(ctx context.Context, arg PurgeSecretVersionsParams) error {
+	_, err := q.db.ExecContext(ctx, purgeSecretVersions, arg.Owner, arg.Kind, arg.Name)
+	return err
+}
@@ -0,0 +1,12 @@
+CREATE TABLE "refresh_tokens" (
+  "id" BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "hash" varchar UNIQUE NOT NULL,
+  "family" varchar NOT NULL,
+  "username" varchar NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "used" boolean NOT NULL DEFAULT false,
+  "revoked" boolean NOT NULL DEFAULT false,
+  "created" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "refresh_tokens" ("family");
@@ -0,0 +1,12 @@
+CREATE TABLE "sessions" (
+  "id" varchar PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "device" varchar NOT NULL,
+  "client_version" varchar NOT NULL,
+  "ip" varchar NOT NULL,
+  "created" timestamptz NOT NULL DEFAULT (now()),
+  "last_seen" timestamptz NOT NULL DEFAULT (now()),
+  "revoked" boolean NOT NULL DEFAULT false
+);
+
+CREATE INDEX ON "sessions" ("username");
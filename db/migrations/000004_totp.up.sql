@@ -0,0 +1,13 @@
+ALTER TABLE "users"
+  ADD COLUMN "totp_secret" varchar NOT NULL DEFAULT '',
+  ADD COLUMN "totp_enabled" boolean NOT NULL DEFAULT false,
+  ADD COLUMN "totp_last_step" bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE "recovery_codes" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "hash" varchar NOT NULL,
+  "used" boolean NOT NULL DEFAULT false
+);
+
+CREATE INDEX ON "recovery_codes" ("username");
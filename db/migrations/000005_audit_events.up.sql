@@ -0,0 +1,24 @@
+CREATE TABLE "audit_events" (
+  "id" bigserial PRIMARY KEY,
+  "type" varchar NOT NULL,
+  "username" varchar NOT NULL,
+  "peer" varchar NOT NULL,
+  "secret_kind" int NOT NULL DEFAULT 0,
+  "secret_name" varchar NOT NULL DEFAULT '',
+  "created" timestamptz NOT NULL,
+  "prev_hash" varchar NOT NULL,
+  "hash" varchar NOT NULL
+);
+
+CREATE INDEX ON "audit_events" ("username");
+
+-- The head of the audit log chain. It is authenticated with the audit key
+-- once the first event is recorded.
+CREATE TABLE "audit_head" (
+  "id" int PRIMARY KEY DEFAULT 1 CHECK ("id" = 1),
+  "event_id" bigint NOT NULL,
+  "hash" varchar NOT NULL,
+  "mac" varchar NOT NULL
+);
+
+INSERT INTO "audit_head" ("event_id", "hash", "mac") VALUES (0, '', '');
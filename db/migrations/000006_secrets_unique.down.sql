@@ -0,0 +1,2 @@
+ALTER TABLE "secrets" DROP CONSTRAINT IF EXISTS "secrets_owner_fkey";
+DROP INDEX IF EXISTS "secrets_owner_kind_name_key";
@@ -0,0 +1,22 @@
+-- Keep only the latest modified copy of duplicated secrets
+DELETE FROM "secrets" AS "s"
+USING "secrets" AS "d"
+WHERE "s"."owner" = "d"."owner"
+  AND "s"."kind" = "d"."kind"
+  AND "s"."name" = "d"."name"
+  AND ("s"."modified" < "d"."modified" OR ("s"."modified" = "d"."modified" AND "s"."id" < "d"."id"));
+
+-- Client local databases have no users with passwords, keep their secrets with a passwordless owner
+INSERT INTO "users" ("name", "passhash")
+SELECT DISTINCT "owner", '' FROM "secrets"
+WHERE NOT EXISTS (SELECT 1 FROM "users" WHERE "passhash" <> '')
+ON CONFLICT ("name") DO NOTHING;
+
+-- Server secrets left by the deleted users go with them
+DELETE FROM "secrets"
+WHERE "owner" NOT IN (SELECT "name" FROM "users");
+
+CREATE UNIQUE INDEX "secrets_owner_kind_name_key" ON "secrets" ("owner", "kind", "name");
+
+ALTER TABLE "secrets" ADD CONSTRAINT "secrets_owner_fkey"
+  FOREIGN KEY ("owner") REFERENCES "users" ("name") ON DELETE CASCADE;
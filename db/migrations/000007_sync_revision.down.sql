@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS sync_cursors;
+DROP INDEX IF EXISTS secrets_owner_revision_idx;
+ALTER TABLE secrets DROP COLUMN IF EXISTS dirty;
+ALTER TABLE secrets DROP COLUMN IF EXISTS revision;
+DROP SEQUENCE IF EXISTS secrets_revision_seq;
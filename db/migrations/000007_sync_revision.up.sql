@@ -0,0 +1,14 @@
+CREATE SEQUENCE "secrets_revision_seq";
+
+-- Existing secrets get their revisions in no particular order
+ALTER TABLE "secrets" ADD COLUMN "revision" bigint NOT NULL DEFAULT nextval('secrets_revision_seq');
+-- Local changes to be pushed by the client
+ALTER TABLE "secrets" ADD COLUMN "dirty" boolean NOT NULL DEFAULT false;
+
+CREATE INDEX ON "secrets" ("owner", "revision");
+
+-- Last server revision pulled by the client
+CREATE TABLE "sync_cursors" (
+  "owner" varchar PRIMARY KEY,
+  "revision" bigint NOT NULL DEFAULT 0
+);
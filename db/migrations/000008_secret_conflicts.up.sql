@@ -0,0 +1,13 @@
+-- Server versions of the secrets changed both locally and on another device
+-- kept by the client until the user resolves the conflict
+CREATE TABLE "secret_conflicts" (
+  "owner" varchar NOT NULL,
+  "kind" int NOT NULL,
+  "name" varchar NOT NULL,
+  "value" bytea NOT NULL,
+  "created" timestamptz NOT NULL,
+  "modified" timestamptz NOT NULL,
+  "deleted" boolean NOT NULL DEFAULT false,
+  "revision" bigint NOT NULL,
+  PRIMARY KEY ("owner", "kind", "name")
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS purged_revisions;
+ALTER TABLE secrets DROP COLUMN IF EXISTS deleted_at;
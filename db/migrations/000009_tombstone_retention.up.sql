@@ -0,0 +1,10 @@
+-- Deleted secrets are kept as tombstones for the retention period
+ALTER TABLE "secrets" ADD COLUMN "deleted_at" timestamptz;
+UPDATE "secrets" SET "deleted_at" = now() WHERE "deleted" = true;
+
+-- Highest revision of the user tombstones purged by the server.
+-- Clients synced before it have to resync all the secrets.
+CREATE TABLE "purged_revisions" (
+  "owner" varchar PRIMARY KEY,
+  "revision" bigint NOT NULL DEFAULT 0
+);
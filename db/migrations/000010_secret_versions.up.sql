@@ -0,0 +1,10 @@
+-- Previous values of the secrets by the revision they were replaced at
+CREATE TABLE "secret_versions" (
+  "owner" varchar NOT NULL,
+  "kind" int NOT NULL,
+  "name" varchar NOT NULL,
+  "value" bytea NOT NULL,
+  "modified" timestamptz NOT NULL,
+  "revision" bigint NOT NULL,
+  PRIMARY KEY ("owner", "kind", "name", "revision")
+);
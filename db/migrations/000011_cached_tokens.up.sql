@@ -0,0 +1,6 @@
+-- Client side tokens of the last login
+CREATE TABLE "cached_tokens" (
+  "owner" varchar PRIMARY KEY,
+  "token" varchar NOT NULL,
+  "refresh_token" varchar NOT NULL
+);
@@ -0,0 +1,9 @@
+-- Master password key derivation params shared by all the user devices
+CREATE TABLE "vault_keys" (
+  "owner" varchar PRIMARY KEY,
+  "salt" bytea NOT NULL,
+  "memory" bigint NOT NULL,
+  "iterations" bigint NOT NULL,
+  "parallelism" int NOT NULL,
+  "key_check" bytea NOT NULL
+);
@@ -0,0 +1,4 @@
+-- Client side. Opaque server identity of the end-to-end encrypted secrets,
+-- empty for the secrets synced by their plaintext kind and name
+ALTER TABLE "secrets" ADD COLUMN "remote_id" varchar NOT NULL DEFAULT '';
+ALTER TABLE "secret_conflicts" ADD COLUMN "remote_id" varchar NOT NULL DEFAULT '';
@@ -0,0 +1,5 @@
+-- Client side new vault key check of the rekey the local database doesn't follow yet
+CREATE TABLE "pending_rekeys" (
+  "owner" varchar PRIMARY KEY,
+  "key_check" bytea NOT NULL
+);
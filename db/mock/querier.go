@@ -8,6 +8,7 @@ import (
 	context "context"
 	db "gophkeeper/db/db"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -35,19 +36,135 @@ func (m *MockQuerier) EXPECT() *MockQuerierMockRecorder {
 	return m.recorder
 }
 
+// AddSecretVersion mocks base method.
+func (m *MockQuerier) AddSecretVersion(arg0 context.Context, arg1 db.AddSecretVersionParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddSecretVersion", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddSecretVersion indicates an expected call of AddSecretVersion.
+func (mr *MockQuerierMockRecorder) AddSecretVersion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSecretVersion", reflect.TypeOf((*MockQuerier)(nil).AddSecretVersion), arg0, arg1)
+}
+
+// CleanRefreshTokens mocks base method.
+func (m *MockQuerier) CleanRefreshTokens(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CleanRefreshTokens", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CleanRefreshTokens indicates an expected call of CleanRefreshTokens.
+func (mr *MockQuerierMockRecorder) CleanRefreshTokens(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanRefreshTokens", reflect.TypeOf((*MockQuerier)(nil).CleanRefreshTokens), arg0)
+}
+
 // CleanSecrets mocks base method.
-func (m *MockQuerier) CleanSecrets(arg0 context.Context) ([]db.Secret, error) {
+func (m *MockQuerier) CleanSecrets(arg0 context.Context, arg1 time.Time) ([]db.Secret, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CleanSecrets", arg0)
+	ret := m.ctrl.Call(m, "CleanSecrets", arg0, arg1)
 	ret0, _ := ret[0].([]db.Secret)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CleanSecrets indicates an expected call of CleanSecrets.
-func (mr *MockQuerierMockRecorder) CleanSecrets(arg0 interface{}) *gomock.Call {
+func (mr *MockQuerierMockRecorder) CleanSecrets(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanSecrets", reflect.TypeOf((*MockQuerier)(nil).CleanSecrets), arg0, arg1)
+}
+
+// CleanSessions mocks base method.
+func (m *MockQuerier) CleanSessions(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CleanSessions", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CleanSessions indicates an expected call of CleanSessions.
+func (mr *MockQuerierMockRecorder) CleanSessions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanSessions", reflect.TypeOf((*MockQuerier)(nil).CleanSessions), arg0, arg1)
+}
+
+// ClearSecretTombstones mocks base method.
+func (m *MockQuerier) ClearSecretTombstones(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearSecretTombstones", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearSecretTombstones indicates an expected call of ClearSecretTombstones.
+func (mr *MockQuerierMockRecorder) ClearSecretTombstones(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearSecretTombstones", reflect.TypeOf((*MockQuerier)(nil).ClearSecretTombstones), arg0, arg1)
+}
+
+// CreateAuditEvent mocks base method.
+func (m *MockQuerier) CreateAuditEvent(arg0 context.Context, arg1 db.CreateAuditEventParams) (db.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditEvent", arg0, arg1)
+	ret0, _ := ret[0].(db.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAuditEvent indicates an expected call of CreateAuditEvent.
+func (mr *MockQuerierMockRecorder) CreateAuditEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditEvent", reflect.TypeOf((*MockQuerier)(nil).CreateAuditEvent), arg0, arg1)
+}
+
+// CreateLocalUser mocks base method.
+func (m *MockQuerier) CreateLocalUser(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLocalUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateLocalUser indicates an expected call of CreateLocalUser.
+func (mr *MockQuerierMockRecorder) CreateLocalUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLocalUser", reflect.TypeOf((*MockQuerier)(nil).CreateLocalUser), arg0, arg1)
+}
+
+// CreateRecoveryCode mocks base method.
+func (m *MockQuerier) CreateRecoveryCode(arg0 context.Context, arg1 db.CreateRecoveryCodeParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRecoveryCode indicates an expected call of CreateRecoveryCode.
+func (mr *MockQuerierMockRecorder) CreateRecoveryCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRecoveryCode", reflect.TypeOf((*MockQuerier)(nil).CreateRecoveryCode), arg0, arg1)
+}
+
+// CreateRefreshToken mocks base method.
+func (m *MockQuerier) CreateRefreshToken(arg0 context.Context, arg1 db.CreateRefreshTokenParams) (db.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRefreshToken", arg0, arg1)
+	ret0, _ := ret[0].(db.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRefreshToken indicates an expected call of CreateRefreshToken.
+func (mr *MockQuerierMockRecorder) CreateRefreshToken(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanSecrets", reflect.TypeOf((*MockQuerier)(nil).CleanSecrets), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefreshToken", reflect.TypeOf((*MockQuerier)(nil).CreateRefreshToken), arg0, arg1)
 }
 
 // CreateSecret mocks base method.
@@ -65,6 +182,21 @@ func (mr *MockQuerierMockRecorder) CreateSecret(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSecret", reflect.TypeOf((*MockQuerier)(nil).CreateSecret), arg0, arg1)
 }
 
+// CreateSession mocks base method.
+func (m *MockQuerier) CreateSession(arg0 context.Context, arg1 db.CreateSessionParams) (db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSession", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSession indicates an expected call of CreateSession.
+func (mr *MockQuerierMockRecorder) CreateSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockQuerier)(nil).CreateSession), arg0, arg1)
+}
+
 // CreateUser mocks base method.
 func (m *MockQuerier) CreateUser(arg0 context.Context, arg1 db.CreateUserParams) (db.User, error) {
 	m.ctrl.T.Helper()
@@ -80,6 +212,49 @@ func (mr *MockQuerierMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockQuerier)(nil).CreateUser), arg0, arg1)
 }
 
+// CreateVaultKey mocks base method.
+func (m *MockQuerier) CreateVaultKey(arg0 context.Context, arg1 db.CreateVaultKeyParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVaultKey", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateVaultKey indicates an expected call of CreateVaultKey.
+func (mr *MockQuerierMockRecorder) CreateVaultKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVaultKey", reflect.TypeOf((*MockQuerier)(nil).CreateVaultKey), arg0, arg1)
+}
+
+// DeletePendingRekey mocks base method.
+func (m *MockQuerier) DeletePendingRekey(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePendingRekey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePendingRekey indicates an expected call of DeletePendingRekey.
+func (mr *MockQuerierMockRecorder) DeletePendingRekey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePendingRekey", reflect.TypeOf((*MockQuerier)(nil).DeletePendingRekey), arg0, arg1)
+}
+
+// DeleteRecoveryCodes mocks base method.
+func (m *MockQuerier) DeleteRecoveryCodes(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRecoveryCodes", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRecoveryCodes indicates an expected call of DeleteRecoveryCodes.
+func (mr *MockQuerierMockRecorder) DeleteRecoveryCodes(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRecoveryCodes", reflect.TypeOf((*MockQuerier)(nil).DeleteRecoveryCodes), arg0, arg1)
+}
+
 // DeleteSecret mocks base method.
 func (m *MockQuerier) DeleteSecret(arg0 context.Context, arg1 db.DeleteSecretParams) error {
 	m.ctrl.T.Helper()
@@ -94,6 +269,34 @@ func (mr *MockQuerierMockRecorder) DeleteSecret(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSecret", reflect.TypeOf((*MockQuerier)(nil).DeleteSecret), arg0, arg1)
 }
 
+// DeleteSecretConflict mocks base method.
+func (m *MockQuerier) DeleteSecretConflict(arg0 context.Context, arg1 db.DeleteSecretConflictParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSecretConflict", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSecretConflict indicates an expected call of DeleteSecretConflict.
+func (mr *MockQuerierMockRecorder) DeleteSecretConflict(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSecretConflict", reflect.TypeOf((*MockQuerier)(nil).DeleteSecretConflict), arg0, arg1)
+}
+
+// DeleteSecretVersions mocks base method.
+func (m *MockQuerier) DeleteSecretVersions(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSecretVersions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSecretVersions indicates an expected call of DeleteSecretVersions.
+func (mr *MockQuerierMockRecorder) DeleteSecretVersions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSecretVersions", reflect.TypeOf((*MockQuerier)(nil).DeleteSecretVersions), arg0, arg1)
+}
+
 // DeleteUser mocks base method.
 func (m *MockQuerier) DeleteUser(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -108,6 +311,169 @@ func (mr *MockQuerierMockRecorder) DeleteUser(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockQuerier)(nil).DeleteUser), arg0, arg1)
 }
 
+// DeleteUserWithSecrets mocks base method.
+func (m *MockQuerier) DeleteUserWithSecrets(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserWithSecrets", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserWithSecrets indicates an expected call of DeleteUserWithSecrets.
+func (mr *MockQuerierMockRecorder) DeleteUserWithSecrets(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserWithSecrets", reflect.TypeOf((*MockQuerier)(nil).DeleteUserWithSecrets), arg0, arg1)
+}
+
+// EnableTOTP mocks base method.
+func (m *MockQuerier) EnableTOTP(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTOTP", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableTOTP indicates an expected call of EnableTOTP.
+func (mr *MockQuerierMockRecorder) EnableTOTP(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTOTP", reflect.TypeOf((*MockQuerier)(nil).EnableTOTP), arg0, arg1)
+}
+
+// ForgetSecret mocks base method.
+func (m *MockQuerier) ForgetSecret(arg0 context.Context, arg1 db.ForgetSecretParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForgetSecret", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ForgetSecret indicates an expected call of ForgetSecret.
+func (mr *MockQuerierMockRecorder) ForgetSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForgetSecret", reflect.TypeOf((*MockQuerier)(nil).ForgetSecret), arg0, arg1)
+}
+
+// GetAuditEvents mocks base method.
+func (m *MockQuerier) GetAuditEvents(arg0 context.Context, arg1 db.GetAuditEventsParams) ([]db.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditEvents", arg0, arg1)
+	ret0, _ := ret[0].([]db.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditEvents indicates an expected call of GetAuditEvents.
+func (mr *MockQuerierMockRecorder) GetAuditEvents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEvents", reflect.TypeOf((*MockQuerier)(nil).GetAuditEvents), arg0, arg1)
+}
+
+// GetAuditEventsByUser mocks base method.
+func (m *MockQuerier) GetAuditEventsByUser(arg0 context.Context, arg1 db.GetAuditEventsByUserParams) ([]db.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditEventsByUser", arg0, arg1)
+	ret0, _ := ret[0].([]db.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditEventsByUser indicates an expected call of GetAuditEventsByUser.
+func (mr *MockQuerierMockRecorder) GetAuditEventsByUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEventsByUser", reflect.TypeOf((*MockQuerier)(nil).GetAuditEventsByUser), arg0, arg1)
+}
+
+// GetAuditHead mocks base method.
+func (m *MockQuerier) GetAuditHead(arg0 context.Context) (db.AuditHead, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditHead", arg0)
+	ret0, _ := ret[0].(db.AuditHead)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditHead indicates an expected call of GetAuditHead.
+func (mr *MockQuerierMockRecorder) GetAuditHead(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditHead", reflect.TypeOf((*MockQuerier)(nil).GetAuditHead), arg0)
+}
+
+// GetCachedTokens mocks base method.
+func (m *MockQuerier) GetCachedTokens(arg0 context.Context, arg1 string) (db.CachedToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCachedTokens", arg0, arg1)
+	ret0, _ := ret[0].(db.CachedToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCachedTokens indicates an expected call of GetCachedTokens.
+func (mr *MockQuerierMockRecorder) GetCachedTokens(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCachedTokens", reflect.TypeOf((*MockQuerier)(nil).GetCachedTokens), arg0, arg1)
+}
+
+// GetDirtySecrets mocks base method.
+func (m *MockQuerier) GetDirtySecrets(arg0 context.Context, arg1 string) ([]db.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDirtySecrets", arg0, arg1)
+	ret0, _ := ret[0].([]db.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDirtySecrets indicates an expected call of GetDirtySecrets.
+func (mr *MockQuerierMockRecorder) GetDirtySecrets(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDirtySecrets", reflect.TypeOf((*MockQuerier)(nil).GetDirtySecrets), arg0, arg1)
+}
+
+// GetPendingRekey mocks base method.
+func (m *MockQuerier) GetPendingRekey(arg0 context.Context, arg1 string) (db.PendingRekey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingRekey", arg0, arg1)
+	ret0, _ := ret[0].(db.PendingRekey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingRekey indicates an expected call of GetPendingRekey.
+func (mr *MockQuerierMockRecorder) GetPendingRekey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingRekey", reflect.TypeOf((*MockQuerier)(nil).GetPendingRekey), arg0, arg1)
+}
+
+// GetPurgedRevision mocks base method.
+func (m *MockQuerier) GetPurgedRevision(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPurgedRevision", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPurgedRevision indicates an expected call of GetPurgedRevision.
+func (mr *MockQuerierMockRecorder) GetPurgedRevision(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPurgedRevision", reflect.TypeOf((*MockQuerier)(nil).GetPurgedRevision), arg0, arg1)
+}
+
+// GetRefreshToken mocks base method.
+func (m *MockQuerier) GetRefreshToken(arg0 context.Context, arg1 string) (db.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefreshToken", arg0, arg1)
+	ret0, _ := ret[0].(db.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefreshToken indicates an expected call of GetRefreshToken.
+func (mr *MockQuerierMockRecorder) GetRefreshToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshToken", reflect.TypeOf((*MockQuerier)(nil).GetRefreshToken), arg0, arg1)
+}
+
 // GetSecret mocks base method.
 func (m *MockQuerier) GetSecret(arg0 context.Context, arg1 db.GetSecretParams) (db.Secret, error) {
 	m.ctrl.T.Helper()
@@ -123,76 +489,742 @@ func (mr *MockQuerierMockRecorder) GetSecret(arg0, arg1 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecret", reflect.TypeOf((*MockQuerier)(nil).GetSecret), arg0, arg1)
 }
 
-// GetSecretsByKind mocks base method.
-func (m *MockQuerier) GetSecretsByKind(arg0 context.Context, arg1 db.GetSecretsByKindParams) ([]db.Secret, error) {
+// GetSecretByRemoteID mocks base method.
+func (m *MockQuerier) GetSecretByRemoteID(arg0 context.Context, arg1 db.GetSecretByRemoteIDParams) (db.Secret, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSecretsByKind", arg0, arg1)
-	ret0, _ := ret[0].([]db.Secret)
+	ret := m.ctrl.Call(m, "GetSecretByRemoteID", arg0, arg1)
+	ret0, _ := ret[0].(db.Secret)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetSecretsByKind indicates an expected call of GetSecretsByKind.
-func (mr *MockQuerierMockRecorder) GetSecretsByKind(arg0, arg1 interface{}) *gomock.Call {
+// GetSecretByRemoteID indicates an expected call of GetSecretByRemoteID.
+func (mr *MockQuerierMockRecorder) GetSecretByRemoteID(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecretsByKind", reflect.TypeOf((*MockQuerier)(nil).GetSecretsByKind), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecretByRemoteID", reflect.TypeOf((*MockQuerier)(nil).GetSecretByRemoteID), arg0, arg1)
 }
 
-// GetSecretsByUser mocks base method.
-func (m *MockQuerier) GetSecretsByUser(arg0 context.Context, arg1 string) ([]db.Secret, error) {
+// GetSecretChanges mocks base method.
+func (m *MockQuerier) GetSecretChanges(arg0 context.Context, arg1 db.GetSecretChangesParams) ([]db.Secret, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSecretsByUser", arg0, arg1)
+	ret := m.ctrl.Call(m, "GetSecretChanges", arg0, arg1)
 	ret0, _ := ret[0].([]db.Secret)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetSecretsByUser indicates an expected call of GetSecretsByUser.
-func (mr *MockQuerierMockRecorder) GetSecretsByUser(arg0, arg1 interface{}) *gomock.Call {
+// GetSecretChanges indicates an expected call of GetSecretChanges.
+func (mr *MockQuerierMockRecorder) GetSecretChanges(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecretsByUser", reflect.TypeOf((*MockQuerier)(nil).GetSecretsByUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecretChanges", reflect.TypeOf((*MockQuerier)(nil).GetSecretChanges), arg0, arg1)
 }
 
-// GetUser mocks base method.
-func (m *MockQuerier) GetUser(arg0 context.Context, arg1 string) (db.User, error) {
+// GetSecretConflict mocks base method.
+func (m *MockQuerier) GetSecretConflict(arg0 context.Context, arg1 db.GetSecretConflictParams) (db.SecretConflict, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
-	ret0, _ := ret[0].(db.User)
+	ret := m.ctrl.Call(m, "GetSecretConflict", arg0, arg1)
+	ret0, _ := ret[0].(db.SecretConflict)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUser indicates an expected call of GetUser.
-func (mr *MockQuerierMockRecorder) GetUser(arg0, arg1 interface{}) *gomock.Call {
+// GetSecretConflict indicates an expected call of GetSecretConflict.
+func (mr *MockQuerierMockRecorder) GetSecretConflict(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockQuerier)(nil).GetUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecretConflict", reflect.TypeOf((*MockQuerier)(nil).GetSecretConflict), arg0, arg1)
 }
 
-// MarkSecretDeleted mocks base method.
-func (m *MockQuerier) MarkSecretDeleted(arg0 context.Context, arg1 db.MarkSecretDeletedParams) error {
+// GetSecretConflicts mocks base method.
+func (m *MockQuerier) GetSecretConflicts(arg0 context.Context, arg1 string) ([]db.SecretConflict, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "MarkSecretDeleted", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "GetSecretConflicts", arg0, arg1)
+	ret0, _ := ret[0].([]db.SecretConflict)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// MarkSecretDeleted indicates an expected call of MarkSecretDeleted.
-func (mr *MockQuerierMockRecorder) MarkSecretDeleted(arg0, arg1 interface{}) *gomock.Call {
+// GetSecretConflicts indicates an expected call of GetSecretConflicts.
+func (mr *MockQuerierMockRecorder) GetSecretConflicts(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkSecretDeleted", reflect.TypeOf((*MockQuerier)(nil).MarkSecretDeleted), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecretConflicts", reflect.TypeOf((*MockQuerier)(nil).GetSecretConflicts), arg0, arg1)
 }
 
-// UpdateSecret mocks base method.
-func (m *MockQuerier) UpdateSecret(arg0 context.Context, arg1 db.UpdateSecretParams) (db.Secret, error) {
+// GetSecretVersion mocks base method.
+func (m *MockQuerier) GetSecretVersion(arg0 context.Context, arg1 db.GetSecretVersionParams) (db.SecretVersion, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateSecret", arg0, arg1)
-	ret0, _ := ret[0].(db.Secret)
+	ret := m.ctrl.Call(m, "GetSecretVersion", arg0, arg1)
+	ret0, _ := ret[0].(db.SecretVersion)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UpdateSecret indicates an expected call of UpdateSecret.
-func (mr *MockQuerierMockRecorder) UpdateSecret(arg0, arg1 interface{}) *gomock.Call {
+// GetSecretVersion indicates an expected call of GetSecretVersion.
+func (mr *MockQuerierMockRecorder) GetSecretVersion(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSecret", reflect.TypeOf((*MockQuerier)(nil).UpdateSecret), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecretVersion", reflect.TypeOf((*MockQuerier)(nil).GetSecretVersion), arg0, arg1)
+}
+
+// GetSecretVersions mocks base method.
+func (m *MockQuerier) GetSecretVersions(arg0 context.Context, arg1 db.GetSecretVersionsParams) ([]db.SecretVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSecretVersions", arg0, arg1)
+	ret0, _ := ret[0].([]db.SecretVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSecretVersions indicates an expected call of GetSecretVersions.
+func (mr *MockQuerierMockRecorder) GetSecretVersions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecretVersions", reflect.TypeOf((*MockQuerier)(nil).GetSecretVersions), arg0, arg1)
+}
+
+// GetSecretsByKind mocks base method.
+func (m *MockQuerier) GetSecretsByKind(arg0 context.Context, arg1 db.GetSecretsByKindParams) ([]db.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSecretsByKind", arg0, arg1)
+	ret0, _ := ret[0].([]db.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSecretsByKind indicates an expected call of GetSecretsByKind.
+func (mr *MockQuerierMockRecorder) GetSecretsByKind(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecretsByKind", reflect.TypeOf((*MockQuerier)(nil).GetSecretsByKind), arg0, arg1)
+}
+
+// GetSecretsByUser mocks base method.
+func (m *MockQuerier) GetSecretsByUser(arg0 context.Context, arg1 string) ([]db.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSecretsByUser", arg0, arg1)
+	ret0, _ := ret[0].([]db.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSecretsByUser indicates an expected call of GetSecretsByUser.
+func (mr *MockQuerierMockRecorder) GetSecretsByUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecretsByUser", reflect.TypeOf((*MockQuerier)(nil).GetSecretsByUser), arg0, arg1)
+}
+
+// GetSession mocks base method.
+func (m *MockQuerier) GetSession(arg0 context.Context, arg1 string) (db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockQuerierMockRecorder) GetSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockQuerier)(nil).GetSession), arg0, arg1)
+}
+
+// GetSessionsByUser mocks base method.
+func (m *MockQuerier) GetSessionsByUser(arg0 context.Context, arg1 string) ([]db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessionsByUser", arg0, arg1)
+	ret0, _ := ret[0].([]db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessionsByUser indicates an expected call of GetSessionsByUser.
+func (mr *MockQuerierMockRecorder) GetSessionsByUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionsByUser", reflect.TypeOf((*MockQuerier)(nil).GetSessionsByUser), arg0, arg1)
+}
+
+// GetSyncCursor mocks base method.
+func (m *MockQuerier) GetSyncCursor(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSyncCursor", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSyncCursor indicates an expected call of GetSyncCursor.
+func (mr *MockQuerierMockRecorder) GetSyncCursor(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSyncCursor", reflect.TypeOf((*MockQuerier)(nil).GetSyncCursor), arg0, arg1)
+}
+
+// GetTrashedSecrets mocks base method.
+func (m *MockQuerier) GetTrashedSecrets(arg0 context.Context, arg1 string) ([]db.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrashedSecrets", arg0, arg1)
+	ret0, _ := ret[0].([]db.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrashedSecrets indicates an expected call of GetTrashedSecrets.
+func (mr *MockQuerierMockRecorder) GetTrashedSecrets(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrashedSecrets", reflect.TypeOf((*MockQuerier)(nil).GetTrashedSecrets), arg0, arg1)
+}
+
+// GetUser mocks base method.
+func (m *MockQuerier) GetUser(arg0 context.Context, arg1 string) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockQuerierMockRecorder) GetUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockQuerier)(nil).GetUser), arg0, arg1)
+}
+
+// GetVaultKey mocks base method.
+func (m *MockQuerier) GetVaultKey(arg0 context.Context, arg1 string) (db.VaultKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVaultKey", arg0, arg1)
+	ret0, _ := ret[0].(db.VaultKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVaultKey indicates an expected call of GetVaultKey.
+func (mr *MockQuerierMockRecorder) GetVaultKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVaultKey", reflect.TypeOf((*MockQuerier)(nil).GetVaultKey), arg0, arg1)
+}
+
+// LockAuditHead mocks base method.
+func (m *MockQuerier) LockAuditHead(arg0 context.Context) (db.AuditHead, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockAuditHead", arg0)
+	ret0, _ := ret[0].(db.AuditHead)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockAuditHead indicates an expected call of LockAuditHead.
+func (mr *MockQuerierMockRecorder) LockAuditHead(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockAuditHead", reflect.TypeOf((*MockQuerier)(nil).LockAuditHead), arg0)
+}
+
+// LockUserSecrets mocks base method.
+func (m *MockQuerier) LockUserSecrets(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockUserSecrets", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockUserSecrets indicates an expected call of LockUserSecrets.
+func (mr *MockQuerierMockRecorder) LockUserSecrets(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockUserSecrets", reflect.TypeOf((*MockQuerier)(nil).LockUserSecrets), arg0, arg1)
+}
+
+// LockUserSecretsShared mocks base method.
+func (m *MockQuerier) LockUserSecretsShared(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockUserSecretsShared", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockUserSecretsShared indicates an expected call of LockUserSecretsShared.
+func (mr *MockQuerierMockRecorder) LockUserSecretsShared(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockUserSecretsShared", reflect.TypeOf((*MockQuerier)(nil).LockUserSecretsShared), arg0, arg1)
+}
+
+// MarkLocalSecretDeleted mocks base method.
+func (m *MockQuerier) MarkLocalSecretDeleted(arg0 context.Context, arg1 db.MarkLocalSecretDeletedParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkLocalSecretDeleted", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkLocalSecretDeleted indicates an expected call of MarkLocalSecretDeleted.
+func (mr *MockQuerierMockRecorder) MarkLocalSecretDeleted(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkLocalSecretDeleted", reflect.TypeOf((*MockQuerier)(nil).MarkLocalSecretDeleted), arg0, arg1)
+}
+
+// MarkRefreshTokenUsed mocks base method.
+func (m *MockQuerier) MarkRefreshTokenUsed(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRefreshTokenUsed", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkRefreshTokenUsed indicates an expected call of MarkRefreshTokenUsed.
+func (mr *MockQuerierMockRecorder) MarkRefreshTokenUsed(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRefreshTokenUsed", reflect.TypeOf((*MockQuerier)(nil).MarkRefreshTokenUsed), arg0, arg1)
+}
+
+// MarkSecretSynced mocks base method.
+func (m *MockQuerier) MarkSecretSynced(arg0 context.Context, arg1 db.MarkSecretSyncedParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkSecretSynced", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkSecretSynced indicates an expected call of MarkSecretSynced.
+func (mr *MockQuerierMockRecorder) MarkSecretSynced(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkSecretSynced", reflect.TypeOf((*MockQuerier)(nil).MarkSecretSynced), arg0, arg1)
+}
+
+// MarkSecretsDirty mocks base method.
+func (m *MockQuerier) MarkSecretsDirty(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkSecretsDirty", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkSecretsDirty indicates an expected call of MarkSecretsDirty.
+func (mr *MockQuerierMockRecorder) MarkSecretsDirty(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkSecretsDirty", reflect.TypeOf((*MockQuerier)(nil).MarkSecretsDirty), arg0, arg1)
+}
+
+// PruneSecretVersions mocks base method.
+func (m *MockQuerier) PruneSecretVersions(arg0 context.Context, arg1 int32) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PruneSecretVersions", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PruneSecretVersions indicates an expected call of PruneSecretVersions.
+func (mr *MockQuerierMockRecorder) PruneSecretVersions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PruneSecretVersions", reflect.TypeOf((*MockQuerier)(nil).PruneSecretVersions), arg0, arg1)
+}
+
+// PurgeLocalSecret mocks base method.
+func (m *MockQuerier) PurgeLocalSecret(arg0 context.Context, arg1 db.PurgeLocalSecretParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeLocalSecret", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeLocalSecret indicates an expected call of PurgeLocalSecret.
+func (mr *MockQuerierMockRecorder) PurgeLocalSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeLocalSecret", reflect.TypeOf((*MockQuerier)(nil).PurgeLocalSecret), arg0, arg1)
+}
+
+// PurgeSecretVersions mocks base method.
+func (m *MockQuerier) PurgeSecretVersions(arg0 context.Context, arg1 db.PurgeSecretVersionsParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeSecretVersions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeSecretVersions indicates an expected call of PurgeSecretVersions.
+func (mr *MockQuerierMockRecorder) PurgeSecretVersions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeSecretVersions", reflect.TypeOf((*MockQuerier)(nil).PurgeSecretVersions), arg0, arg1)
+}
+
+// PurgeSecrets mocks base method.
+func (m *MockQuerier) PurgeSecrets(arg0 context.Context, arg1 time.Time) ([]db.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeSecrets", arg0, arg1)
+	ret0, _ := ret[0].([]db.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeSecrets indicates an expected call of PurgeSecrets.
+func (mr *MockQuerierMockRecorder) PurgeSecrets(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeSecrets", reflect.TypeOf((*MockQuerier)(nil).PurgeSecrets), arg0, arg1)
+}
+
+// RebaseLocalSecret mocks base method.
+func (m *MockQuerier) RebaseLocalSecret(arg0 context.Context, arg1 db.RebaseLocalSecretParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RebaseLocalSecret", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RebaseLocalSecret indicates an expected call of RebaseLocalSecret.
+func (mr *MockQuerierMockRecorder) RebaseLocalSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RebaseLocalSecret", reflect.TypeOf((*MockQuerier)(nil).RebaseLocalSecret), arg0, arg1)
+}
+
+// ReplaceLocalSecret mocks base method.
+func (m *MockQuerier) ReplaceLocalSecret(arg0 context.Context, arg1 db.ReplaceLocalSecretParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceLocalSecret", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceLocalSecret indicates an expected call of ReplaceLocalSecret.
+func (mr *MockQuerierMockRecorder) ReplaceLocalSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceLocalSecret", reflect.TypeOf((*MockQuerier)(nil).ReplaceLocalSecret), arg0, arg1)
+}
+
+// RestoreLocalSecret mocks base method.
+func (m *MockQuerier) RestoreLocalSecret(arg0 context.Context, arg1 db.RestoreLocalSecretParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreLocalSecret", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreLocalSecret indicates an expected call of RestoreLocalSecret.
+func (mr *MockQuerierMockRecorder) RestoreLocalSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreLocalSecret", reflect.TypeOf((*MockQuerier)(nil).RestoreLocalSecret), arg0, arg1)
+}
+
+// RevokeOtherRefreshTokens mocks base method.
+func (m *MockQuerier) RevokeOtherRefreshTokens(arg0 context.Context, arg1 db.RevokeOtherRefreshTokensParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeOtherRefreshTokens", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeOtherRefreshTokens indicates an expected call of RevokeOtherRefreshTokens.
+func (mr *MockQuerierMockRecorder) RevokeOtherRefreshTokens(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeOtherRefreshTokens", reflect.TypeOf((*MockQuerier)(nil).RevokeOtherRefreshTokens), arg0, arg1)
+}
+
+// RevokeOtherSessions mocks base method.
+func (m *MockQuerier) RevokeOtherSessions(arg0 context.Context, arg1 db.RevokeOtherSessionsParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeOtherSessions", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeOtherSessions indicates an expected call of RevokeOtherSessions.
+func (mr *MockQuerierMockRecorder) RevokeOtherSessions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeOtherSessions", reflect.TypeOf((*MockQuerier)(nil).RevokeOtherSessions), arg0, arg1)
+}
+
+// RevokeRefreshTokenFamily mocks base method.
+func (m *MockQuerier) RevokeRefreshTokenFamily(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefreshTokenFamily", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRefreshTokenFamily indicates an expected call of RevokeRefreshTokenFamily.
+func (mr *MockQuerierMockRecorder) RevokeRefreshTokenFamily(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshTokenFamily", reflect.TypeOf((*MockQuerier)(nil).RevokeRefreshTokenFamily), arg0, arg1)
+}
+
+// RevokeSession mocks base method.
+func (m *MockQuerier) RevokeSession(arg0 context.Context, arg1 db.RevokeSessionParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockQuerierMockRecorder) RevokeSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockQuerier)(nil).RevokeSession), arg0, arg1)
+}
+
+// RewrapLocalSecret mocks base method.
+func (m *MockQuerier) RewrapLocalSecret(arg0 context.Context, arg1 db.RewrapLocalSecretParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RewrapLocalSecret", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RewrapLocalSecret indicates an expected call of RewrapLocalSecret.
+func (mr *MockQuerierMockRecorder) RewrapLocalSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RewrapLocalSecret", reflect.TypeOf((*MockQuerier)(nil).RewrapLocalSecret), arg0, arg1)
+}
+
+// RewrapSecret mocks base method.
+func (m *MockQuerier) RewrapSecret(arg0 context.Context, arg1 db.RewrapSecretParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RewrapSecret", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RewrapSecret indicates an expected call of RewrapSecret.
+func (mr *MockQuerierMockRecorder) RewrapSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RewrapSecret", reflect.TypeOf((*MockQuerier)(nil).RewrapSecret), arg0, arg1)
+}
+
+// SaveCachedTokens mocks base method.
+func (m *MockQuerier) SaveCachedTokens(arg0 context.Context, arg1 db.SaveCachedTokensParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveCachedTokens", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveCachedTokens indicates an expected call of SaveCachedTokens.
+func (mr *MockQuerierMockRecorder) SaveCachedTokens(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCachedTokens", reflect.TypeOf((*MockQuerier)(nil).SaveCachedTokens), arg0, arg1)
+}
+
+// SavePendingRekey mocks base method.
+func (m *MockQuerier) SavePendingRekey(arg0 context.Context, arg1 db.SavePendingRekeyParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePendingRekey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePendingRekey indicates an expected call of SavePendingRekey.
+func (mr *MockQuerierMockRecorder) SavePendingRekey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePendingRekey", reflect.TypeOf((*MockQuerier)(nil).SavePendingRekey), arg0, arg1)
+}
+
+// SaveSecretConflict mocks base method.
+func (m *MockQuerier) SaveSecretConflict(arg0 context.Context, arg1 db.SaveSecretConflictParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSecretConflict", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSecretConflict indicates an expected call of SaveSecretConflict.
+func (mr *MockQuerierMockRecorder) SaveSecretConflict(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSecretConflict", reflect.TypeOf((*MockQuerier)(nil).SaveSecretConflict), arg0, arg1)
+}
+
+// SaveVaultKey mocks base method.
+func (m *MockQuerier) SaveVaultKey(arg0 context.Context, arg1 db.SaveVaultKeyParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveVaultKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveVaultKey indicates an expected call of SaveVaultKey.
+func (mr *MockQuerierMockRecorder) SaveVaultKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveVaultKey", reflect.TypeOf((*MockQuerier)(nil).SaveVaultKey), arg0, arg1)
+}
+
+// SetLocalSecretRemoteID mocks base method.
+func (m *MockQuerier) SetLocalSecretRemoteID(arg0 context.Context, arg1 db.SetLocalSecretRemoteIDParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLocalSecretRemoteID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLocalSecretRemoteID indicates an expected call of SetLocalSecretRemoteID.
+func (mr *MockQuerierMockRecorder) SetLocalSecretRemoteID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLocalSecretRemoteID", reflect.TypeOf((*MockQuerier)(nil).SetLocalSecretRemoteID), arg0, arg1)
+}
+
+// SetPurgedRevision mocks base method.
+func (m *MockQuerier) SetPurgedRevision(arg0 context.Context, arg1 db.SetPurgedRevisionParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPurgedRevision", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPurgedRevision indicates an expected call of SetPurgedRevision.
+func (mr *MockQuerierMockRecorder) SetPurgedRevision(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPurgedRevision", reflect.TypeOf((*MockQuerier)(nil).SetPurgedRevision), arg0, arg1)
+}
+
+// SetSyncCursor mocks base method.
+func (m *MockQuerier) SetSyncCursor(arg0 context.Context, arg1 db.SetSyncCursorParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSyncCursor", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSyncCursor indicates an expected call of SetSyncCursor.
+func (mr *MockQuerierMockRecorder) SetSyncCursor(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSyncCursor", reflect.TypeOf((*MockQuerier)(nil).SetSyncCursor), arg0, arg1)
+}
+
+// SetTOTPSecret mocks base method.
+func (m *MockQuerier) SetTOTPSecret(arg0 context.Context, arg1 db.SetTOTPSecretParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTOTPSecret", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTOTPSecret indicates an expected call of SetTOTPSecret.
+func (mr *MockQuerierMockRecorder) SetTOTPSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTOTPSecret", reflect.TypeOf((*MockQuerier)(nil).SetTOTPSecret), arg0, arg1)
+}
+
+// TouchSession mocks base method.
+func (m *MockQuerier) TouchSession(arg0 context.Context, arg1 db.TouchSessionParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchSession", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchSession indicates an expected call of TouchSession.
+func (mr *MockQuerierMockRecorder) TouchSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchSession", reflect.TypeOf((*MockQuerier)(nil).TouchSession), arg0, arg1)
+}
+
+// TrashLocalSecret mocks base method.
+func (m *MockQuerier) TrashLocalSecret(arg0 context.Context, arg1 db.TrashLocalSecretParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TrashLocalSecret", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TrashLocalSecret indicates an expected call of TrashLocalSecret.
+func (mr *MockQuerierMockRecorder) TrashLocalSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TrashLocalSecret", reflect.TypeOf((*MockQuerier)(nil).TrashLocalSecret), arg0, arg1)
+}
+
+// UpdateAuditHead mocks base method.
+func (m *MockQuerier) UpdateAuditHead(arg0 context.Context, arg1 db.UpdateAuditHeadParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAuditHead", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAuditHead indicates an expected call of UpdateAuditHead.
+func (mr *MockQuerierMockRecorder) UpdateAuditHead(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAuditHead", reflect.TypeOf((*MockQuerier)(nil).UpdateAuditHead), arg0, arg1)
+}
+
+// UpdateSecret mocks base method.
+func (m *MockQuerier) UpdateSecret(arg0 context.Context, arg1 db.UpdateSecretParams) (db.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSecret", arg0, arg1)
+	ret0, _ := ret[0].(db.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSecret indicates an expected call of UpdateSecret.
+func (mr *MockQuerierMockRecorder) UpdateSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSecret", reflect.TypeOf((*MockQuerier)(nil).UpdateSecret), arg0, arg1)
+}
+
+// UpdateUserPassword mocks base method.
+func (m *MockQuerier) UpdateUserPassword(arg0 context.Context, arg1 db.UpdateUserPasswordParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserPassword", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserPassword indicates an expected call of UpdateUserPassword.
+func (mr *MockQuerierMockRecorder) UpdateUserPassword(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPassword", reflect.TypeOf((*MockQuerier)(nil).UpdateUserPassword), arg0, arg1)
+}
+
+// UpdateVaultKey mocks base method.
+func (m *MockQuerier) UpdateVaultKey(arg0 context.Context, arg1 db.UpdateVaultKeyParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateVaultKey", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateVaultKey indicates an expected call of UpdateVaultKey.
+func (mr *MockQuerierMockRecorder) UpdateVaultKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateVaultKey", reflect.TypeOf((*MockQuerier)(nil).UpdateVaultKey), arg0, arg1)
+}
+
+// UpsertLocalSecret mocks base method.
+func (m *MockQuerier) UpsertLocalSecret(arg0 context.Context, arg1 db.UpsertLocalSecretParams) (db.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertLocalSecret", arg0, arg1)
+	ret0, _ := ret[0].(db.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertLocalSecret indicates an expected call of UpsertLocalSecret.
+func (mr *MockQuerierMockRecorder) UpsertLocalSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertLocalSecret", reflect.TypeOf((*MockQuerier)(nil).UpsertLocalSecret), arg0, arg1)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockQuerier) UseRecoveryCode(arg0 context.Context, arg1 db.UseRecoveryCodeParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockQuerierMockRecorder) UseRecoveryCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockQuerier)(nil).UseRecoveryCode), arg0, arg1)
+}
+
+// UseTOTPStep mocks base method.
+func (m *MockQuerier) UseTOTPStep(arg0 context.Context, arg1 db.UseTOTPStepParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPStep", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseTOTPStep indicates an expected call of UseTOTPStep.
+func (mr *MockQuerierMockRecorder) UseTOTPStep(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPStep", reflect.TypeOf((*MockQuerier)(nil).UseTOTPStep), arg0, arg1)
 }
@@ -0,0 +1,1244 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: gophkeeper/db/db (interfaces: Store)
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	db "gophkeeper/db/db"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockStore is a mock of Store interface.
+type MockStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockStoreMockRecorder
+}
+
+// MockStoreMockRecorder is the mock recorder for MockStore.
+type MockStoreMockRecorder struct {
+	mock *MockStore
+}
+
+// NewMockStore creates a new mock instance.
+func NewMockStore(ctrl *gomock.Controller) *MockStore {
+	mock := &MockStore{ctrl: ctrl}
+	mock.recorder = &MockStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStore) EXPECT() *MockStoreMockRecorder {
+	return m.recorder
+}
+
+// AddSecretVersion mocks base method.
+func (m *MockStore) AddSecretVersion(arg0 context.Context, arg1 db.AddSecretVersionParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddSecretVersion", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddSecretVersion indicates an expected call of AddSecretVersion.
+func (mr *MockStoreMockRecorder) AddSecretVersion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSecretVersion", reflect.TypeOf((*MockStore)(nil).AddSecretVersion), arg0, arg1)
+}
+
+// CleanRefreshTokens mocks base method.
+func (m *MockStore) CleanRefreshTokens(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CleanRefreshTokens", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CleanRefreshTokens indicates an expected call of CleanRefreshTokens.
+func (mr *MockStoreMockRecorder) CleanRefreshTokens(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanRefreshTokens", reflect.TypeOf((*MockStore)(nil).CleanRefreshTokens), arg0)
+}
+
+// CleanSecrets mocks base method.
+func (m *MockStore) CleanSecrets(arg0 context.Context, arg1 time.Time) ([]db.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CleanSecrets", arg0, arg1)
+	ret0, _ := ret[0].([]db.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CleanSecrets indicates an expected call of CleanSecrets.
+func (mr *MockStoreMockRecorder) CleanSecrets(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanSecrets", reflect.TypeOf((*MockStore)(nil).CleanSecrets), arg0, arg1)
+}
+
+// CleanSessions mocks base method.
+func (m *MockStore) CleanSessions(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CleanSessions", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CleanSessions indicates an expected call of CleanSessions.
+func (mr *MockStoreMockRecorder) CleanSessions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanSessions", reflect.TypeOf((*MockStore)(nil).CleanSessions), arg0, arg1)
+}
+
+// ClearSecretTombstones mocks base method.
+func (m *MockStore) ClearSecretTombstones(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearSecretTombstones", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearSecretTombstones indicates an expected call of ClearSecretTombstones.
+func (mr *MockStoreMockRecorder) ClearSecretTombstones(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearSecretTombstones", reflect.TypeOf((*MockStore)(nil).ClearSecretTombstones), arg0, arg1)
+}
+
+// CreateAuditEvent mocks base method.
+func (m *MockStore) CreateAuditEvent(arg0 context.Context, arg1 db.CreateAuditEventParams) (db.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditEvent", arg0, arg1)
+	ret0, _ := ret[0].(db.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAuditEvent indicates an expected call of CreateAuditEvent.
+func (mr *MockStoreMockRecorder) CreateAuditEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditEvent", reflect.TypeOf((*MockStore)(nil).CreateAuditEvent), arg0, arg1)
+}
+
+// CreateLocalUser mocks base method.
+func (m *MockStore) CreateLocalUser(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLocalUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateLocalUser indicates an expected call of CreateLocalUser.
+func (mr *MockStoreMockRecorder) CreateLocalUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLocalUser", reflect.TypeOf((*MockStore)(nil).CreateLocalUser), arg0, arg1)
+}
+
+// CreateRecoveryCode mocks base method.
+func (m *MockStore) CreateRecoveryCode(arg0 context.Context, arg1 db.CreateRecoveryCodeParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRecoveryCode indicates an expected call of CreateRecoveryCode.
+func (mr *MockStoreMockRecorder) CreateRecoveryCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRecoveryCode", reflect.TypeOf((*MockStore)(nil).CreateRecoveryCode), arg0, arg1)
+}
+
+// CreateRefreshToken mocks base method.
+func (m *MockStore) CreateRefreshToken(arg0 context.Context, arg1 db.CreateRefreshTokenParams) (db.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRefreshToken", arg0, arg1)
+	ret0, _ := ret[0].(db.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRefreshToken indicates an expected call of CreateRefreshToken.
+func (mr *MockStoreMockRecorder) CreateRefreshToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefreshToken", reflect.TypeOf((*MockStore)(nil).CreateRefreshToken), arg0, arg1)
+}
+
+// CreateSecret mocks base method.
+func (m *MockStore) CreateSecret(arg0 context.Context, arg1 db.CreateSecretParams) (db.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSecret", arg0, arg1)
+	ret0, _ := ret[0].(db.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSecret indicates an expected call of CreateSecret.
+func (mr *MockStoreMockRecorder) CreateSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSecret", reflect.TypeOf((*MockStore)(nil).CreateSecret), arg0, arg1)
+}
+
+// CreateSession mocks base method.
+func (m *MockStore) CreateSession(arg0 context.Context, arg1 db.CreateSessionParams) (db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSession", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSession indicates an expected call of CreateSession.
+func (mr *MockStoreMockRecorder) CreateSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockStore)(nil).CreateSession), arg0, arg1)
+}
+
+// CreateUser mocks base method.
+func (m *MockStore) CreateUser(arg0 context.Context, arg1 db.CreateUserParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockStoreMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), arg0, arg1)
+}
+
+// CreateVaultKey mocks base method.
+func (m *MockStore) CreateVaultKey(arg0 context.Context, arg1 db.CreateVaultKeyParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVaultKey", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateVaultKey indicates an expected call of CreateVaultKey.
+func (mr *MockStoreMockRecorder) CreateVaultKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVaultKey", reflect.TypeOf((*MockStore)(nil).CreateVaultKey), arg0, arg1)
+}
+
+// DeletePendingRekey mocks base method.
+func (m *MockStore) DeletePendingRekey(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePendingRekey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePendingRekey indicates an expected call of DeletePendingRekey.
+func (mr *MockStoreMockRecorder) DeletePendingRekey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePendingRekey", reflect.TypeOf((*MockStore)(nil).DeletePendingRekey), arg0, arg1)
+}
+
+// DeleteRecoveryCodes mocks base method.
+func (m *MockStore) DeleteRecoveryCodes(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRecoveryCodes", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRecoveryCodes indicates an expected call of DeleteRecoveryCodes.
+func (mr *MockStoreMockRecorder) DeleteRecoveryCodes(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRecoveryCodes", reflect.TypeOf((*MockStore)(nil).DeleteRecoveryCodes), arg0, arg1)
+}
+
+// DeleteSecret mocks base method.
+func (m *MockStore) DeleteSecret(arg0 context.Context, arg1 db.DeleteSecretParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSecret", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSecret indicates an expected call of DeleteSecret.
+func (mr *MockStoreMockRecorder) DeleteSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSecret", reflect.TypeOf((*MockStore)(nil).DeleteSecret), arg0, arg1)
+}
+
+// DeleteSecretConflict mocks base method.
+func (m *MockStore) DeleteSecretConflict(arg0 context.Context, arg1 db.DeleteSecretConflictParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSecretConflict", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSecretConflict indicates an expected call of DeleteSecretConflict.
+func (mr *MockStoreMockRecorder) DeleteSecretConflict(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSecretConflict", reflect.TypeOf((*MockStore)(nil).DeleteSecretConflict), arg0, arg1)
+}
+
+// DeleteSecretVersions mocks base method.
+func (m *MockStore) DeleteSecretVersions(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSecretVersions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSecretVersions indicates an expected call of DeleteSecretVersions.
+func (mr *MockStoreMockRecorder) DeleteSecretVersions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSecretVersions", reflect.TypeOf((*MockStore)(nil).DeleteSecretVersions), arg0, arg1)
+}
+
+// DeleteUser mocks base method.
+func (m *MockStore) DeleteUser(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockStoreMockRecorder) DeleteUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockStore)(nil).DeleteUser), arg0, arg1)
+}
+
+// DeleteUserWithSecrets mocks base method.
+func (m *MockStore) DeleteUserWithSecrets(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserWithSecrets", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserWithSecrets indicates an expected call of DeleteUserWithSecrets.
+func (mr *MockStoreMockRecorder) DeleteUserWithSecrets(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserWithSecrets", reflect.TypeOf((*MockStore)(nil).DeleteUserWithSecrets), arg0, arg1)
+}
+
+// EnableTOTP mocks base method.
+func (m *MockStore) EnableTOTP(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTOTP", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableTOTP indicates an expected call of EnableTOTP.
+func (mr *MockStoreMockRecorder) EnableTOTP(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTOTP", reflect.TypeOf((*MockStore)(nil).EnableTOTP), arg0, arg1)
+}
+
+// ForgetSecret mocks base method.
+func (m *MockStore) ForgetSecret(arg0 context.Context, arg1 db.ForgetSecretParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForgetSecret", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ForgetSecret indicates an expected call of ForgetSecret.
+func (mr *MockStoreMockRecorder) ForgetSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForgetSecret", reflect.TypeOf((*MockStore)(nil).ForgetSecret), arg0, arg1)
+}
+
+// GetAuditEvents mocks base method.
+func (m *MockStore) GetAuditEvents(arg0 context.Context, arg1 db.GetAuditEventsParams) ([]db.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditEvents", arg0, arg1)
+	ret0, _ := ret[0].([]db.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditEvents indicates an expected call of GetAuditEvents.
+func (mr *MockStoreMockRecorder) GetAuditEvents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEvents", reflect.TypeOf((*MockStore)(nil).GetAuditEvents), arg0, arg1)
+}
+
+// GetAuditEventsByUser mocks base method.
+func (m *MockStore) GetAuditEventsByUser(arg0 context.Context, arg1 db.GetAuditEventsByUserParams) ([]db.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditEventsByUser", arg0, arg1)
+	ret0, _ := ret[0].([]db.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditEventsByUser indicates an expected call of GetAuditEventsByUser.
+func (mr *MockStoreMockRecorder) GetAuditEventsByUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEventsByUser", reflect.TypeOf((*MockStore)(nil).GetAuditEventsByUser), arg0, arg1)
+}
+
+// GetAuditHead mocks base method.
+func (m *MockStore) GetAuditHead(arg0 context.Context) (db.AuditHead, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditHead", arg0)
+	ret0, _ := ret[0].(db.AuditHead)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditHead indicates an expected call of GetAuditHead.
+func (mr *MockStoreMockRecorder) GetAuditHead(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditHead", reflect.TypeOf((*MockStore)(nil).GetAuditHead), arg0)
+}
+
+// GetCachedTokens mocks base method.
+func (m *MockStore) GetCachedTokens(arg0 context.Context, arg1 string) (db.CachedToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCachedTokens", arg0, arg1)
+	ret0, _ := ret[0].(db.CachedToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCachedTokens indicates an expected call of GetCachedTokens.
+func (mr *MockStoreMockRecorder) GetCachedTokens(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCachedTokens", reflect.TypeOf((*MockStore)(nil).GetCachedTokens), arg0, arg1)
+}
+
+// GetDirtySecrets mocks base method.
+func (m *MockStore) GetDirtySecrets(arg0 context.Context, arg1 string) ([]db.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDirtySecrets", arg0, arg1)
+	ret0, _ := ret[0].([]db.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDirtySecrets indicates an expected call of GetDirtySecrets.
+func (mr *MockStoreMockRecorder) GetDirtySecrets(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDirtySecrets", reflect.TypeOf((*MockStore)(nil).GetDirtySecrets), arg0, arg1)
+}
+
+// GetPendingRekey mocks base method.
+func (m *MockStore) GetPendingRekey(arg0 context.Context, arg1 string) (db.PendingRekey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingRekey", arg0, arg1)
+	ret0, _ := ret[0].(db.PendingRekey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingRekey indicates an expected call of GetPendingRekey.
+func (mr *MockStoreMockRecorder) GetPendingRekey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingRekey", reflect.TypeOf((*MockStore)(nil).GetPendingRekey), arg0, arg1)
+}
+
+// GetPurgedRevision mocks base method.
+func (m *MockStore) GetPurgedRevision(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPurgedRevision", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPurgedRevision indicates an expected call of GetPurgedRevision.
+func (mr *MockStoreMockRecorder) GetPurgedRevision(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPurgedRevision", reflect.TypeOf((*MockStore)(nil).GetPurgedRevision), arg0, arg1)
+}
+
+// GetRefreshToken mocks base method.
+func (m *MockStore) GetRefreshToken(arg0 context.Context, arg1 string) (db.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefreshToken", arg0, arg1)
+	ret0, _ := ret[0].(db.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefreshToken indicates an expected call of GetRefreshToken.
+func (mr *MockStoreMockRecorder) GetRefreshToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshToken", reflect.TypeOf((*MockStore)(nil).GetRefreshToken), arg0, arg1)
+}
+
+// GetSecret mocks base method.
+func (m *MockStore) GetSecret(arg0 context.Context, arg1 db.GetSecretParams) (db.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSecret", arg0, arg1)
+	ret0, _ := ret[0].(db.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSecret indicates an expected call of GetSecret.
+func (mr *MockStoreMockRecorder) GetSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecret", reflect.TypeOf((*MockStore)(nil).GetSecret), arg0, arg1)
+}
+
+// GetSecretByRemoteID mocks base method.
+func (m *MockStore) GetSecretByRemoteID(arg0 context.Context, arg1 db.GetSecretByRemoteIDParams) (db.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSecretByRemoteID", arg0, arg1)
+	ret0, _ := ret[0].(db.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSecretByRemoteID indicates an expected call of GetSecretByRemoteID.
+func (mr *MockStoreMockRecorder) GetSecretByRemoteID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecretByRemoteID", reflect.TypeOf((*MockStore)(nil).GetSecretByRemoteID), arg0, arg1)
+}
+
+// GetSecretChanges mocks base method.
+func (m *MockStore) GetSecretChanges(arg0 context.Context, arg1 db.GetSecretChangesParams) ([]db.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSecretChanges", arg0, arg1)
+	ret0, _ := ret[0].([]db.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSecretChanges indicates an expected call of GetSecretChanges.
+func (mr *MockStoreMockRecorder) GetSecretChanges(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecretChanges", reflect.TypeOf((*MockStore)(nil).GetSecretChanges), arg0, arg1)
+}
+
+// GetSecretConflict mocks base method.
+func (m *MockStore) GetSecretConflict(arg0 context.Context, arg1 db.GetSecretConflictParams) (db.SecretConflict, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSecretConflict", arg0, arg1)
+	ret0, _ := ret[0].(db.SecretConflict)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSecretConflict indicates an expected call of GetSecretConflict.
+func (mr *MockStoreMockRecorder) GetSecretConflict(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecretConflict", reflect.TypeOf((*MockStore)(nil).GetSecretConflict), arg0, arg1)
+}
+
+// GetSecretConflicts mocks base method.
+func (m *MockStore) GetSecretConflicts(arg0 context.Context, arg1 string) ([]db.SecretConflict, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSecretConflicts", arg0, arg1)
+	ret0, _ := ret[0].([]db.SecretConflict)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSecretConflicts indicates an expected call of GetSecretConflicts.
+func (mr *MockStoreMockRecorder) GetSecretConflicts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecretConflicts", reflect.TypeOf((*MockStore)(nil).GetSecretConflicts), arg0, arg1)
+}
+
+// GetSecretVersion mocks base method.
+func (m *MockStore) GetSecretVersion(arg0 context.Context, arg1 db.GetSecretVersionParams) (db.SecretVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSecretVersion", arg0, arg1)
+	ret0, _ := ret[0].(db.SecretVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSecretVersion indicates an expected call of GetSecretVersion.
+func (mr *MockStoreMockRecorder) GetSecretVersion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecretVersion", reflect.TypeOf((*MockStore)(nil).GetSecretVersion), arg0, arg1)
+}
+
+// GetSecretVersions mocks base method.
+func (m *MockStore) GetSecretVersions(arg0 context.Context, arg1 db.GetSecretVersionsParams) ([]db.SecretVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSecretVersions", arg0, arg1)
+	ret0, _ := ret[0].([]db.SecretVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSecretVersions indicates an expected call of GetSecretVersions.
+func (mr *MockStoreMockRecorder) GetSecretVersions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecretVersions", reflect.TypeOf((*MockStore)(nil).GetSecretVersions), arg0, arg1)
+}
+
+// GetSecretsByKind mocks base method.
+func (m *MockStore) GetSecretsByKind(arg0 context.Context, arg1 db.GetSecretsByKindParams) ([]db.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSecretsByKind", arg0, arg1)
+	ret0, _ := ret[0].([]db.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSecretsByKind indicates an expected call of GetSecretsByKind.
+func (mr *MockStoreMockRecorder) GetSecretsByKind(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecretsByKind", reflect.TypeOf((*MockStore)(nil).GetSecretsByKind), arg0, arg1)
+}
+
+// GetSecretsByUser mocks base method.
+func (m *MockStore) GetSecretsByUser(arg0 context.Context, arg1 string) ([]db.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSecretsByUser", arg0, arg1)
+	ret0, _ := ret[0].([]db.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSecretsByUser indicates an expected call of GetSecretsByUser.
+func (mr *MockStoreMockRecorder) GetSecretsByUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecretsByUser", reflect.TypeOf((*MockStore)(nil).GetSecretsByUser), arg0, arg1)
+}
+
+// GetSession mocks base method.
+func (m *MockStore) GetSession(arg0 context.Context, arg1 string) (db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockStoreMockRecorder) GetSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockStore)(nil).GetSession), arg0, arg1)
+}
+
+// GetSessionsByUser mocks base method.
+func (m *MockStore) GetSessionsByUser(arg0 context.Context, arg1 string) ([]db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessionsByUser", arg0, arg1)
+	ret0, _ := ret[0].([]db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessionsByUser indicates an expected call of GetSessionsByUser.
+func (mr *MockStoreMockRecorder) GetSessionsByUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionsByUser", reflect.TypeOf((*MockStore)(nil).GetSessionsByUser), arg0, arg1)
+}
+
+// GetSyncCursor mocks base method.
+func (m *MockStore) GetSyncCursor(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSyncCursor", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSyncCursor indicates an expected call of GetSyncCursor.
+func (mr *MockStoreMockRecorder) GetSyncCursor(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSyncCursor", reflect.TypeOf((*MockStore)(nil).GetSyncCursor), arg0, arg1)
+}
+
+// GetTrashedSecrets mocks base method.
+func (m *MockStore) GetTrashedSecrets(arg0 context.Context, arg1 string) ([]db.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrashedSecrets", arg0, arg1)
+	ret0, _ := ret[0].([]db.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrashedSecrets indicates an expected call of GetTrashedSecrets.
+func (mr *MockStoreMockRecorder) GetTrashedSecrets(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrashedSecrets", reflect.TypeOf((*MockStore)(nil).GetTrashedSecrets), arg0, arg1)
+}
+
+// GetUser mocks base method.
+func (m *MockStore) GetUser(arg0 context.Context, arg1 string) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockStoreMockRecorder) GetUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockStore)(nil).GetUser), arg0, arg1)
+}
+
+// GetVaultKey mocks base method.
+func (m *MockStore) GetVaultKey(arg0 context.Context, arg1 string) (db.VaultKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVaultKey", arg0, arg1)
+	ret0, _ := ret[0].(db.VaultKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVaultKey indicates an expected call of GetVaultKey.
+func (mr *MockStoreMockRecorder) GetVaultKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVaultKey", reflect.TypeOf((*MockStore)(nil).GetVaultKey), arg0, arg1)
+}
+
+// LockAuditHead mocks base method.
+func (m *MockStore) LockAuditHead(arg0 context.Context) (db.AuditHead, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockAuditHead", arg0)
+	ret0, _ := ret[0].(db.AuditHead)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockAuditHead indicates an expected call of LockAuditHead.
+func (mr *MockStoreMockRecorder) LockAuditHead(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockAuditHead", reflect.TypeOf((*MockStore)(nil).LockAuditHead), arg0)
+}
+
+// LockUserSecrets mocks base method.
+func (m *MockStore) LockUserSecrets(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockUserSecrets", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockUserSecrets indicates an expected call of LockUserSecrets.
+func (mr *MockStoreMockRecorder) LockUserSecrets(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockUserSecrets", reflect.TypeOf((*MockStore)(nil).LockUserSecrets), arg0, arg1)
+}
+
+// LockUserSecretsShared mocks base method.
+func (m *MockStore) LockUserSecretsShared(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockUserSecretsShared", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockUserSecretsShared indicates an expected call of LockUserSecretsShared.
+func (mr *MockStoreMockRecorder) LockUserSecretsShared(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockUserSecretsShared", reflect.TypeOf((*MockStore)(nil).LockUserSecretsShared), arg0, arg1)
+}
+
+// MarkLocalSecretDeleted mocks base method.
+func (m *MockStore) MarkLocalSecretDeleted(arg0 context.Context, arg1 db.MarkLocalSecretDeletedParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkLocalSecretDeleted", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkLocalSecretDeleted indicates an expected call of MarkLocalSecretDeleted.
+func (mr *MockStoreMockRecorder) MarkLocalSecretDeleted(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkLocalSecretDeleted", reflect.TypeOf((*MockStore)(nil).MarkLocalSecretDeleted), arg0, arg1)
+}
+
+// MarkRefreshTokenUsed mocks base method.
+func (m *MockStore) MarkRefreshTokenUsed(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRefreshTokenUsed", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkRefreshTokenUsed indicates an expected call of MarkRefreshTokenUsed.
+func (mr *MockStoreMockRecorder) MarkRefreshTokenUsed(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRefreshTokenUsed", reflect.TypeOf((*MockStore)(nil).MarkRefreshTokenUsed), arg0, arg1)
+}
+
+// MarkSecretSynced mocks base method.
+func (m *MockStore) MarkSecretSynced(arg0 context.Context, arg1 db.MarkSecretSyncedParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkSecretSynced", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkSecretSynced indicates an expected call of MarkSecretSynced.
+func (mr *MockStoreMockRecorder) MarkSecretSynced(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkSecretSynced", reflect.TypeOf((*MockStore)(nil).MarkSecretSynced), arg0, arg1)
+}
+
+// MarkSecretsDirty mocks base method.
+func (m *MockStore) MarkSecretsDirty(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkSecretsDirty", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkSecretsDirty indicates an expected call of MarkSecretsDirty.
+func (mr *MockStoreMockRecorder) MarkSecretsDirty(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkSecretsDirty", reflect.TypeOf((*MockStore)(nil).MarkSecretsDirty), arg0, arg1)
+}
+
+// PruneSecretVersions mocks base method.
+func (m *MockStore) PruneSecretVersions(arg0 context.Context, arg1 int32) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PruneSecretVersions", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PruneSecretVersions indicates an expected call of PruneSecretVersions.
+func (mr *MockStoreMockRecorder) PruneSecretVersions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PruneSecretVersions", reflect.TypeOf((*MockStore)(nil).PruneSecretVersions), arg0, arg1)
+}
+
+// PurgeLocalSecret mocks base method.
+func (m *MockStore) PurgeLocalSecret(arg0 context.Context, arg1 db.PurgeLocalSecretParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeLocalSecret", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeLocalSecret indicates an expected call of PurgeLocalSecret.
+func (mr *MockStoreMockRecorder) PurgeLocalSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeLocalSecret", reflect.TypeOf((*MockStore)(nil).PurgeLocalSecret), arg0, arg1)
+}
+
+// PurgeSecretVersions mocks base method.
+func (m *MockStore) PurgeSecretVersions(arg0 context.Context, arg1 db.PurgeSecretVersionsParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeSecretVersions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeSecretVersions indicates an expected call of PurgeSecretVersions.
+func (mr *MockStoreMockRecorder) PurgeSecretVersions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeSecretVersions", reflect.TypeOf((*MockStore)(nil).PurgeSecretVersions), arg0, arg1)
+}
+
+// PurgeSecrets mocks base method.
+func (m *MockStore) PurgeSecrets(arg0 context.Context, arg1 time.Time) ([]db.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeSecrets", arg0, arg1)
+	ret0, _ := ret[0].([]db.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeSecrets indicates an expected call of PurgeSecrets.
+func (mr *MockStoreMockRecorder) PurgeSecrets(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeSecrets", reflect.TypeOf((*MockStore)(nil).PurgeSecrets), arg0, arg1)
+}
+
+// RebaseLocalSecret mocks base method.
+func (m *MockStore) RebaseLocalSecret(arg0 context.Context, arg1 db.RebaseLocalSecretParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RebaseLocalSecret", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RebaseLocalSecret indicates an expected call of RebaseLocalSecret.
+func (mr *MockStoreMockRecorder) RebaseLocalSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RebaseLocalSecret", reflect.TypeOf((*MockStore)(nil).RebaseLocalSecret), arg0, arg1)
+}
+
+// ReplaceLocalSecret mocks base method.
+func (m *MockStore) ReplaceLocalSecret(arg0 context.Context, arg1 db.ReplaceLocalSecretParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceLocalSecret", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceLocalSecret indicates an expected call of ReplaceLocalSecret.
+func (mr *MockStoreMockRecorder) ReplaceLocalSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceLocalSecret", reflect.TypeOf((*MockStore)(nil).ReplaceLocalSecret), arg0, arg1)
+}
+
+// RestoreLocalSecret mocks base method.
+func (m *MockStore) RestoreLocalSecret(arg0 context.Context, arg1 db.RestoreLocalSecretParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreLocalSecret", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreLocalSecret indicates an expected call of RestoreLocalSecret.
+func (mr *MockStoreMockRecorder) RestoreLocalSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreLocalSecret", reflect.TypeOf((*MockStore)(nil).RestoreLocalSecret), arg0, arg1)
+}
+
+// RevokeOtherRefreshTokens mocks base method.
+func (m *MockStore) RevokeOtherRefreshTokens(arg0 context.Context, arg1 db.RevokeOtherRefreshTokensParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeOtherRefreshTokens", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeOtherRefreshTokens indicates an expected call of RevokeOtherRefreshTokens.
+func (mr *MockStoreMockRecorder) RevokeOtherRefreshTokens(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeOtherRefreshTokens", reflect.TypeOf((*MockStore)(nil).RevokeOtherRefreshTokens), arg0, arg1)
+}
+
+// RevokeOtherSessions mocks base method.
+func (m *MockStore) RevokeOtherSessions(arg0 context.Context, arg1 db.RevokeOtherSessionsParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeOtherSessions", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeOtherSessions indicates an expected call of RevokeOtherSessions.
+func (mr *MockStoreMockRecorder) RevokeOtherSessions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeOtherSessions", reflect.TypeOf((*MockStore)(nil).RevokeOtherSessions), arg0, arg1)
+}
+
+// RevokeRefreshTokenFamily mocks base method.
+func (m *MockStore) RevokeRefreshTokenFamily(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefreshTokenFamily", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRefreshTokenFamily indicates an expected call of RevokeRefreshTokenFamily.
+func (mr *MockStoreMockRecorder) RevokeRefreshTokenFamily(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshTokenFamily", reflect.TypeOf((*MockStore)(nil).RevokeRefreshTokenFamily), arg0, arg1)
+}
+
+// RevokeSession mocks base method.
+func (m *MockStore) RevokeSession(arg0 context.Context, arg1 db.RevokeSessionParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockStoreMockRecorder) RevokeSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockStore)(nil).RevokeSession), arg0, arg1)
+}
+
+// RewrapLocalSecret mocks base method.
+func (m *MockStore) RewrapLocalSecret(arg0 context.Context, arg1 db.RewrapLocalSecretParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RewrapLocalSecret", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RewrapLocalSecret indicates an expected call of RewrapLocalSecret.
+func (mr *MockStoreMockRecorder) RewrapLocalSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RewrapLocalSecret", reflect.TypeOf((*MockStore)(nil).RewrapLocalSecret), arg0, arg1)
+}
+
+// RewrapSecret mocks base method.
+func (m *MockStore) RewrapSecret(arg0 context.Context, arg1 db.RewrapSecretParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RewrapSecret", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RewrapSecret indicates an expected call of RewrapSecret.
+func (mr *MockStoreMockRecorder) RewrapSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RewrapSecret", reflect.TypeOf((*MockStore)(nil).RewrapSecret), arg0, arg1)
+}
+
+// SaveCachedTokens mocks base method.
+func (m *MockStore) SaveCachedTokens(arg0 context.Context, arg1 db.SaveCachedTokensParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveCachedTokens", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveCachedTokens indicates an expected call of SaveCachedTokens.
+func (mr *MockStoreMockRecorder) SaveCachedTokens(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCachedTokens", reflect.TypeOf((*MockStore)(nil).SaveCachedTokens), arg0, arg1)
+}
+
+// SavePendingRekey mocks base method.
+func (m *MockStore) SavePendingRekey(arg0 context.Context, arg1 db.SavePendingRekeyParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePendingRekey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePendingRekey indicates an expected call of SavePendingRekey.
+func (mr *MockStoreMockRecorder) SavePendingRekey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePendingRekey", reflect.TypeOf((*MockStore)(nil).SavePendingRekey), arg0, arg1)
+}
+
+// SaveSecretConflict mocks base method.
+func (m *MockStore) SaveSecretConflict(arg0 context.Context, arg1 db.SaveSecretConflictParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSecretConflict", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSecretConflict indicates an expected call of SaveSecretConflict.
+func (mr *MockStoreMockRecorder) SaveSecretConflict(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSecretConflict", reflect.TypeOf((*MockStore)(nil).SaveSecretConflict), arg0, arg1)
+}
+
+// SaveVaultKey mocks base method.
+func (m *MockStore) SaveVaultKey(arg0 context.Context, arg1 db.SaveVaultKeyParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveVaultKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveVaultKey indicates an expected call of SaveVaultKey.
+func (mr *MockStoreMockRecorder) SaveVaultKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveVaultKey", reflect.TypeOf((*MockStore)(nil).SaveVaultKey), arg0, arg1)
+}
+
+// SetLocalSecretRemoteID mocks base method.
+func (m *MockStore) SetLocalSecretRemoteID(arg0 context.Context, arg1 db.SetLocalSecretRemoteIDParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLocalSecretRemoteID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLocalSecretRemoteID indicates an expected call of SetLocalSecretRemoteID.
+func (mr *MockStoreMockRecorder) SetLocalSecretRemoteID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLocalSecretRemoteID", reflect.TypeOf((*MockStore)(nil).SetLocalSecretRemoteID), arg0, arg1)
+}
+
+// SetPurgedRevision mocks base method.
+func (m *MockStore) SetPurgedRevision(arg0 context.Context, arg1 db.SetPurgedRevisionParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPurgedRevision", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPurgedRevision indicates an expected call of SetPurgedRevision.
+func (mr *MockStoreMockRecorder) SetPurgedRevision(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPurgedRevision", reflect.TypeOf((*MockStore)(nil).SetPurgedRevision), arg0, arg1)
+}
+
+// SetSyncCursor mocks base method.
+func (m *MockStore) SetSyncCursor(arg0 context.Context, arg1 db.SetSyncCursorParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSyncCursor", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSyncCursor indicates an expected call of SetSyncCursor.
+func (mr *MockStoreMockRecorder) SetSyncCursor(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSyncCursor", reflect.TypeOf((*MockStore)(nil).SetSyncCursor), arg0, arg1)
+}
+
+// SetTOTPSecret mocks base method.
+func (m *MockStore) SetTOTPSecret(arg0 context.Context, arg1 db.SetTOTPSecretParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTOTPSecret", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTOTPSecret indicates an expected call of SetTOTPSecret.
+func (mr *MockStoreMockRecorder) SetTOTPSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTOTPSecret", reflect.TypeOf((*MockStore)(nil).SetTOTPSecret), arg0, arg1)
+}
+
+// TouchSession mocks base method.
+func (m *MockStore) TouchSession(arg0 context.Context, arg1 db.TouchSessionParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchSession", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchSession indicates an expected call of TouchSession.
+func (mr *MockStoreMockRecorder) TouchSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchSession", reflect.TypeOf((*MockStore)(nil).TouchSession), arg0, arg1)
+}
+
+// TrashLocalSecret mocks base method.
+func (m *MockStore) TrashLocalSecret(arg0 context.Context, arg1 db.TrashLocalSecretParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TrashLocalSecret", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TrashLocalSecret indicates an expected call of TrashLocalSecret.
+func (mr *MockStoreMockRecorder) TrashLocalSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TrashLocalSecret", reflect.TypeOf((*MockStore)(nil).TrashLocalSecret), arg0, arg1)
+}
+
+// UpdateAuditHead mocks base method.
+func (m *MockStore) UpdateAuditHead(arg0 context.Context, arg1 db.UpdateAuditHeadParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAuditHead", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAuditHead indicates an expected call of UpdateAuditHead.
+func (mr *MockStoreMockRecorder) UpdateAuditHead(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAuditHead", reflect.TypeOf((*MockStore)(nil).UpdateAuditHead), arg0, arg1)
+}
+
+// UpdateSecret mocks base method.
+func (m *MockStore) UpdateSecret(arg0 context.Context, arg1 db.UpdateSecretParams) (db.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSecret", arg0, arg1)
+	ret0, _ := ret[0].(db.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSecret indicates an expected call of UpdateSecret.
+func (mr *MockStoreMockRecorder) UpdateSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSecret", reflect.TypeOf((*MockStore)(nil).UpdateSecret), arg0, arg1)
+}
+
+// UpdateUserPassword mocks base method.
+func (m *MockStore) UpdateUserPassword(arg0 context.Context, arg1 db.UpdateUserPasswordParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserPassword", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserPassword indicates an expected call of UpdateUserPassword.
+func (mr *MockStoreMockRecorder) UpdateUserPassword(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPassword", reflect.TypeOf((*MockStore)(nil).UpdateUserPassword), arg0, arg1)
+}
+
+// UpdateVaultKey mocks base method.
+func (m *MockStore) UpdateVaultKey(arg0 context.Context, arg1 db.UpdateVaultKeyParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateVaultKey", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateVaultKey indicates an expected call of UpdateVaultKey.
+func (mr *MockStoreMockRecorder) UpdateVaultKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateVaultKey", reflect.TypeOf((*MockStore)(nil).UpdateVaultKey), arg0, arg1)
+}
+
+// UpsertLocalSecret mocks base method.
+func (m *MockStore) UpsertLocalSecret(arg0 context.Context, arg1 db.UpsertLocalSecretParams) (db.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertLocalSecret", arg0, arg1)
+	ret0, _ := ret[0].(db.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertLocalSecret indicates an expected call of UpsertLocalSecret.
+func (mr *MockStoreMockRecorder) UpsertLocalSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertLocalSecret", reflect.TypeOf((*MockStore)(nil).UpsertLocalSecret), arg0, arg1)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockStore) UseRecoveryCode(arg0 context.Context, arg1 db.UseRecoveryCodeParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockStoreMockRecorder) UseRecoveryCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockStore)(nil).UseRecoveryCode), arg0, arg1)
+}
+
+// UseTOTPStep mocks base method.
+func (m *MockStore) UseTOTPStep(arg0 context.Context, arg1 db.UseTOTPStepParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPStep", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseTOTPStep indicates an expected call of UseTOTPStep.
+func (mr *MockStoreMockRecorder) UseTOTPStep(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPStep", reflect.TypeOf((*MockStore)(nil).UseTOTPStep), arg0, arg1)
+}
+
+// WithTx mocks base method.
+func (m *MockStore) WithTx(arg0 context.Context, arg1 func(db.Querier) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockStoreMockRecorder) WithTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockStore)(nil).WithTx), arg0, arg1)
+}
@@ -0,0 +1,43 @@
+-- name: CreateAuditEvent :one
+INSERT INTO audit_events (
+  type,
+  username,
+  peer,
+  secret_kind,
+  secret_name,
+  created,
+  prev_hash,
+  hash
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+)
+RETURNING *;
+
+-- name: LockAuditHead :one
+-- The head row lock serializes the appends of all the server replicas.
+SELECT * FROM audit_head
+WHERE id = 1
+FOR UPDATE;
+
+-- name: GetAuditHead :one
+SELECT * FROM audit_head
+WHERE id = 1;
+
+-- name: UpdateAuditHead :exec
+UPDATE audit_head
+SET event_id = $1,
+  hash = $2,
+  mac = $3
+WHERE id = 1;
+
+-- name: GetAuditEventsByUser :many
+SELECT * FROM audit_events
+WHERE username = $1
+ORDER BY id DESC
+LIMIT $2;
+
+-- name: GetAuditEvents :many
+SELECT * FROM audit_events
+WHERE id > $1
+ORDER BY id
+LIMIT $2;
@@ -0,0 +1,41 @@
+-- name: SaveSecretConflict :exec
+INSERT INTO secret_conflicts (
+  owner,
+  kind,
+  name,
+  value,
+  created,
+  modified,
+  deleted,
+  revision,
+  remote_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+ON CONFLICT (owner, kind, name) DO UPDATE
+SET value = EXCLUDED.value,
+  created = EXCLUDED.created,
+  modified = EXCLUDED.modified,
+  deleted = EXCLUDED.deleted,
+  revision = EXCLUDED.revision,
+  remote_id = EXCLUDED.remote_id;
+
+-- name: GetSecretConflict :one
+SELECT * FROM secret_conflicts
+WHERE owner = $1 AND kind = $2 AND name = $3;
+
+-- name: GetSecretConflicts :many
+SELECT * FROM secret_conflicts
+WHERE owner = $1;
+
+-- name: DeleteSecretConflict :exec
+DELETE FROM secret_conflicts
+WHERE owner = $1 AND kind = $2 AND name = $3;
+
+-- name: RebaseLocalSecret :exec
+-- Local changes are pushed on top of the server revision under its server identity.
+UPDATE secrets
+SET revision = $4,
+  remote_id = sqlc.arg(remote_id),
+  dirty = true
+WHERE owner = $1 AND kind = $2 AND name = $3;
@@ -1,4 +1,5 @@
 -- name: CreateSecret :one
+-- No row is returned if the secret already exists.
 INSERT INTO secrets (
   owner,
   kind,
@@ -9,12 +10,130 @@ INSERT INTO secrets (
 ) VALUES (
   $1, $2, $3, $4, $5, $6
 )
+ON CONFLICT (owner, kind, name) DO NOTHING
 RETURNING *;
 
+-- name: UpdateSecret :one
+-- The secret is only changed if it is based on the current revision,
+-- otherwise no row is returned. The replaced value is kept as a version.
+WITH previous AS (
+  INSERT INTO secret_versions (owner, kind, name, value, modified, revision)
+  SELECT secrets.owner, secrets.kind, secrets.name, secrets.value, secrets.modified, secrets.revision
+  FROM secrets
+  WHERE secrets.owner = $1 AND secrets.kind = $2 AND secrets.name = $3
+    AND secrets.revision = sqlc.arg(base_revision) AND secrets.deleted = false
+  ON CONFLICT (owner, kind, name, revision) DO NOTHING
+)
+UPDATE secrets
+SET value = $4,
+  modified = $5,
+  deleted = sqlc.arg(deleted),
+  deleted_at = CASE WHEN sqlc.arg(deleted)::boolean THEN now() END,
+  revision = nextval('secrets_revision_seq')
+WHERE secrets.owner = $1 AND secrets.kind = $2 AND secrets.name = $3 AND secrets.revision = sqlc.arg(base_revision)
+RETURNING *;
+
+-- name: RewrapSecret :execrows
+-- Only the data key of the value is changed. The secret gets a new revision
+-- for the other devices to pull it.
+UPDATE secrets
+SET value = $4,
+  revision = nextval('secrets_revision_seq')
+WHERE owner = $1 AND kind = $2 AND name = $3 AND revision = sqlc.arg(base_revision) AND deleted = sqlc.arg(deleted);
+
+-- name: ClearSecretTombstones :exec
+-- Values of the deleted secrets are dropped when the vault is rekeyed,
+-- the ones still kept in the client trash are rewrapped afterwards.
+UPDATE secrets
+SET value = ''::bytea
+WHERE owner = $1 AND deleted = true;
+
+-- name: RewrapLocalSecret :exec
+-- Client side.
+UPDATE secrets
+SET value = $4
+WHERE owner = $1 AND kind = $2 AND name = $3;
+
+-- name: UpsertLocalSecret :one
+-- Client side local change to be pushed. The secret keeps the revision it is based on.
+-- The replaced server copy is kept as a version.
+WITH previous AS (
+  INSERT INTO secret_versions (owner, kind, name, value, modified, revision)
+  SELECT secrets.owner, secrets.kind, secrets.name, secrets.value, secrets.modified, secrets.revision
+  FROM secrets
+  WHERE secrets.owner = $1 AND secrets.kind = $2 AND secrets.name = $3
+    AND secrets.revision > 0 AND secrets.deleted = false AND secrets.dirty = false
+  ON CONFLICT (owner, kind, name, revision) DO NOTHING
+)
+INSERT INTO secrets (
+  owner,
+  kind,
+  name,
+  value,
+  created,
+  modified,
+  revision,
+  dirty,
+  remote_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6, 0, true, $7
+)
+ON CONFLICT (owner, kind, name) DO UPDATE
+SET value = EXCLUDED.value,
+  modified = EXCLUDED.modified,
+  deleted = false,
+  deleted_at = NULL,
+  dirty = true
+RETURNING *;
+
+-- name: ReplaceLocalSecret :exec
+-- Client side copy of the server secret. The replaced server copy is kept as a version.
+WITH previous AS (
+  INSERT INTO secret_versions (owner, kind, name, value, modified, revision)
+  SELECT secrets.owner, secrets.kind, secrets.name, secrets.value, secrets.modified, secrets.revision
+  FROM secrets
+  WHERE secrets.owner = $1 AND secrets.kind = $2 AND secrets.name = $3
+    AND secrets.revision > 0 AND secrets.deleted = false AND secrets.dirty = false
+  ON CONFLICT (owner, kind, name, revision) DO NOTHING
+)
+INSERT INTO secrets (
+  owner,
+  kind,
+  name,
+  value,
+  created,
+  modified,
+  revision,
+  dirty,
+  remote_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, false, $8
+)
+ON CONFLICT (owner, kind, name) DO UPDATE
+SET value = EXCLUDED.value,
+  created = EXCLUDED.created,
+  modified = EXCLUDED.modified,
+  revision = EXCLUDED.revision,
+  remote_id = EXCLUDED.remote_id,
+  deleted = false,
+  deleted_at = NULL,
+  dirty = false;
+
 -- name: GetSecret :one
 SELECT * FROM secrets
-WHERE owner = $1 AND kind = $2 AND name = $3
-LIMIT 1;
+WHERE owner = $1 AND kind = $2 AND name = $3;
+
+-- name: GetSecretByRemoteID :one
+-- Client side.
+SELECT * FROM secrets
+WHERE owner = $1 AND remote_id = sqlc.arg(remote_id) AND remote_id <> '';
+
+-- name: SetLocalSecretRemoteID :exec
+-- Client side. The secret synced by its plaintext name is moved to its opaque server identity.
+UPDATE secrets
+SET remote_id = sqlc.arg(remote_id),
+  revision = sqlc.arg(revision)
+WHERE owner = $1 AND kind = $2 AND name = $3;
 
 -- name: GetSecretsByUser :many
 SELECT * FROM secrets
@@ -26,24 +145,108 @@ SELECT * FROM secrets
 WHERE owner = $1 AND kind = $2
 ORDER BY modified DESC;
 
--- name: UpdateSecret :one
+-- name: MarkLocalSecretDeleted :exec
+-- Client side. Deleted secret is moved to the trash.
 UPDATE secrets
-  set value = $4,
-  created = $5,
-  modified = $6
-WHERE owner = $1 AND kind = $2 AND name = $3
-RETURNING *;
+SET deleted = true,
+  deleted_at = now(),
+  dirty = true
+WHERE owner = $1 AND kind = $2 AND name = $3;
+
+-- name: TrashLocalSecret :exec
+-- Client side copy of the server deletion. The local value is kept in the trash.
+UPDATE secrets
+SET deleted = true,
+  deleted_at = COALESCE(deleted_at, now()),
+  revision = $4,
+  dirty = false
+WHERE owner = $1 AND kind = $2 AND name = $3;
+
+-- name: RestoreLocalSecret :exec
+-- Client side. Restored secret is pushed as an undelete.
+UPDATE secrets
+SET deleted = false,
+  deleted_at = NULL,
+  modified = now(),
+  dirty = true
+WHERE owner = $1 AND kind = $2 AND name = $3 AND deleted = true;
+
+-- name: PurgeLocalSecret :exec
+-- Client side. Purged secret is removed from the trash right away and its value is dropped.
+-- The deletion is pushed without the value and the secret is removed from the db afterwards.
+UPDATE secrets
+SET value = ''::bytea,
+  deleted_at = NULL,
+  dirty = true
+WHERE owner = $1 AND kind = $2 AND name = $3 AND deleted = true;
 
--- name: MarkSecretDeleted :exec
+-- name: GetTrashedSecrets :many
+SELECT * FROM secrets
+WHERE owner = $1 AND deleted = true AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC;
+
+-- name: GetSecretChanges :many
+-- Changed and deleted secrets of the user after the revision.
+SELECT * FROM secrets
+WHERE owner = $1 AND revision > $2
+ORDER BY revision
+LIMIT $3;
+
+-- name: LockUserSecrets :exec
+-- Serializes user secret changes until the end of the transaction
+-- so the revisions are committed in order.
+SELECT pg_advisory_xact_lock(hashtext(sqlc.arg(owner)::varchar));
+
+-- name: LockUserSecretsShared :exec
+-- Waits for the user secret changes in progress to read committed revisions only.
+SELECT pg_advisory_xact_lock_shared(hashtext(sqlc.arg(owner)::varchar));
+
+-- name: GetDirtySecrets :many
+-- Conflicted secrets are not pushed until resolved.
+SELECT * FROM secrets
+WHERE secrets.owner = $1 AND secrets.dirty = true
+  AND NOT EXISTS (
+    SELECT 1 FROM secret_conflicts
+    WHERE secret_conflicts.owner = secrets.owner
+      AND secret_conflicts.kind = secrets.kind
+      AND secret_conflicts.name = secrets.name
+  );
+
+-- name: MarkSecretsDirty :exec
 UPDATE secrets
-SET deleted = true
+SET dirty = true
+WHERE owner = $1;
+
+-- name: MarkSecretSynced :exec
+-- Pushed secret gets its new revision. Secrets changed again while being pushed stay dirty.
+UPDATE secrets
+SET revision = sqlc.arg(revision),
+  dirty = NOT (modified = sqlc.arg(modified) AND deleted = sqlc.arg(deleted))
 WHERE owner = $1 AND kind = $2 AND name = $3;
 
 -- name: DeleteSecret :exec
 DELETE FROM secrets
 WHERE owner = $1 AND kind = $2 AND name = $3;
 
+-- name: ForgetSecret :execrows
+-- Server side. The secret is removed with its versions and leaves no tombstone.
+WITH deleted_versions AS (
+  DELETE FROM secret_versions
+  WHERE secret_versions.owner = $1 AND secret_versions.kind = $2 AND secret_versions.name = $3
+)
+DELETE FROM secrets
+WHERE secrets.owner = $1 AND secrets.kind = $2 AND secrets.name = $3;
+
 -- name: CleanSecrets :many
+-- Client side. Deleted secrets are kept in the trash until the deletion
+-- is pushed and they get old or are purged.
+DELETE FROM secrets
+WHERE deleted = true AND dirty = false
+  AND (deleted_at IS NULL OR deleted_at < sqlc.arg(deleted_before)::timestamptz)
+RETURNING *;
+
+-- name: PurgeSecrets :many
+-- Server side. Tombstones are kept for the clients to pull the deletion.
 DELETE FROM secrets
-WHERE deleted = true
+WHERE deleted = true AND deleted_at < sqlc.arg(deleted_before)::timestamptz
 RETURNING *;
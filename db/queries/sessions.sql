@@ -0,0 +1,41 @@
+-- name: CreateSession :one
+INSERT INTO sessions (
+  id,
+  username,
+  device,
+  client_version,
+  ip
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: GetSession :one
+SELECT * FROM sessions
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetSessionsByUser :many
+SELECT * FROM sessions
+WHERE username = $1 AND revoked = false
+ORDER BY last_seen DESC;
+
+-- name: TouchSession :exec
+UPDATE sessions
+SET last_seen = now(),
+  ip = $2
+WHERE id = $1;
+
+-- name: RevokeSession :execrows
+UPDATE sessions
+SET revoked = true
+WHERE id = $1 AND username = $2;
+
+-- name: RevokeOtherSessions :execrows
+UPDATE sessions
+SET revoked = true
+WHERE username = $1 AND id <> $2 AND revoked = false;
+
+-- name: CleanSessions :execrows
+DELETE FROM sessions
+WHERE revoked = true OR last_seen < $1;
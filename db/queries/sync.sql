@@ -0,0 +1,27 @@
+-- name: GetSyncCursor :one
+SELECT revision FROM sync_cursors
+WHERE owner = $1;
+
+-- name: SetSyncCursor :exec
+INSERT INTO sync_cursors (
+  owner,
+  revision
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (owner) DO UPDATE
+SET revision = EXCLUDED.revision;
+
+-- name: GetPurgedRevision :one
+SELECT revision FROM purged_revisions
+WHERE owner = $1;
+
+-- name: SetPurgedRevision :exec
+INSERT INTO purged_revisions (
+  owner,
+  revision
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (owner) DO UPDATE
+SET revision = GREATEST(purged_revisions.revision, EXCLUDED.revision);
@@ -0,0 +1,52 @@
+-- name: CreateRefreshToken :one
+INSERT INTO refresh_tokens (
+  hash,
+  family,
+  username,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetRefreshToken :one
+SELECT * FROM refresh_tokens
+WHERE hash = $1
+LIMIT 1;
+
+-- name: MarkRefreshTokenUsed :execrows
+UPDATE refresh_tokens
+SET used = true
+WHERE hash = $1 AND used = false;
+
+-- name: RevokeRefreshTokenFamily :exec
+UPDATE refresh_tokens
+SET revoked = true
+WHERE family = $1;
+
+-- name: CleanRefreshTokens :execrows
+DELETE FROM refresh_tokens
+WHERE expires_at < now();
+
+-- name: RevokeOtherRefreshTokens :exec
+UPDATE refresh_tokens
+SET revoked = true
+WHERE username = $1 AND family <> $2;
+
+-- name: GetCachedTokens :one
+-- Client side.
+SELECT * FROM cached_tokens
+WHERE owner = $1;
+
+-- name: SaveCachedTokens :exec
+-- Client side.
+INSERT INTO cached_tokens (
+  owner,
+  token,
+  refresh_token
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (owner) DO UPDATE
+SET token = EXCLUDED.token,
+  refresh_token = EXCLUDED.refresh_token;
@@ -0,0 +1,32 @@
+-- name: SetTOTPSecret :exec
+UPDATE users
+SET totp_secret = $2, totp_enabled = false, totp_last_step = 0
+WHERE name = $1;
+
+-- name: EnableTOTP :exec
+UPDATE users
+SET totp_enabled = true
+WHERE name = $1;
+
+-- name: UseTOTPStep :execrows
+-- Every code is accepted only once.
+UPDATE users
+SET totp_last_step = $2
+WHERE name = $1 AND totp_last_step < $2;
+
+-- name: CreateRecoveryCode :exec
+INSERT INTO recovery_codes (
+  username,
+  hash
+) VALUES (
+  $1, $2
+);
+
+-- name: UseRecoveryCode :execrows
+UPDATE recovery_codes
+SET used = true
+WHERE username = $1 AND hash = $2 AND used = false;
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes
+WHERE username = $1;
@@ -15,3 +15,46 @@ LIMIT 1;
 -- name: DeleteUser :exec
 DELETE FROM users
 WHERE name = $1;
+
+-- name: UpdateUserPassword :exec
+UPDATE users
+SET passhash = $2
+WHERE name = $1;
+
+-- name: DeleteUserWithSecrets :exec
+-- Single statement so the user and all the user data are removed atomically.
+WITH deleted_secrets AS (
+  DELETE FROM secrets WHERE owner = $1
+), deleted_sessions AS (
+  DELETE FROM sessions WHERE username = $1
+), deleted_tokens AS (
+  DELETE FROM refresh_tokens WHERE username = $1
+), deleted_recovery_codes AS (
+  DELETE FROM recovery_codes WHERE username = $1
+), deleted_sync_cursors AS (
+  DELETE FROM sync_cursors WHERE owner = $1
+), deleted_secret_conflicts AS (
+  DELETE FROM secret_conflicts WHERE owner = $1
+), deleted_purged_revisions AS (
+  DELETE FROM purged_revisions WHERE owner = $1
+), deleted_secret_versions AS (
+  DELETE FROM secret_versions WHERE owner = $1
+), deleted_cached_tokens AS (
+  DELETE FROM cached_tokens WHERE owner = $1
+), deleted_vault_keys AS (
+  DELETE FROM vault_keys WHERE owner = $1
+), deleted_pending_rekeys AS (
+  DELETE FROM pending_rekeys WHERE owner = $1
+)
+DELETE FROM users
+WHERE users.name = $1;
+
+-- name: CreateLocalUser :exec
+-- Client local databases only keep the owner of the cached secrets.
+INSERT INTO users (
+  name,
+  passhash
+) VALUES (
+  $1, ''
+)
+ON CONFLICT (name) DO NOTHING;
@@ -0,0 +1,68 @@
+-- name: GetVaultKey :one
+SELECT * FROM vault_keys
+WHERE owner = $1;
+
+-- name: CreateVaultKey :execrows
+-- The first device to create the vault key wins, the others get the stored one.
+INSERT INTO vault_keys (
+  owner,
+  salt,
+  memory,
+  iterations,
+  parallelism,
+  key_check
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (owner) DO NOTHING;
+
+-- name: SaveVaultKey :exec
+-- Client side copy of the server vault key.
+INSERT INTO vault_keys (
+  owner,
+  salt,
+  memory,
+  iterations,
+  parallelism,
+  key_check
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (owner) DO UPDATE
+SET salt = EXCLUDED.salt,
+  memory = EXCLUDED.memory,
+  iterations = EXCLUDED.iterations,
+  parallelism = EXCLUDED.parallelism,
+  key_check = EXCLUDED.key_check;
+
+-- name: UpdateVaultKey :execrows
+-- The vault key is only replaced if it is still the one the new key replaces.
+UPDATE vault_keys
+SET salt = $2,
+  memory = $3,
+  iterations = $4,
+  parallelism = $5,
+  key_check = $6
+WHERE owner = $1 AND key_check = sqlc.arg(old_key_check);
+
+-- name: GetPendingRekey :one
+-- Client side.
+SELECT * FROM pending_rekeys
+WHERE owner = $1;
+
+-- name: SavePendingRekey :exec
+-- Client side. The rekey is marked before the server replaces the vault key
+-- and unmarked once the local database follows it.
+INSERT INTO pending_rekeys (
+  owner,
+  key_check
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (owner) DO UPDATE
+SET key_check = EXCLUDED.key_check;
+
+-- name: DeletePendingRekey :exec
+-- Client side.
+DELETE FROM pending_rekeys
+WHERE owner = $1;
@@ -0,0 +1,55 @@
+-- name: AddSecretVersion :exec
+-- Client side copy of the server version.
+INSERT INTO secret_versions (
+  owner,
+  kind,
+  name,
+  value,
+  modified,
+  revision
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (owner, kind, name, revision) DO NOTHING;
+
+-- name: GetSecretVersion :one
+SELECT * FROM secret_versions
+WHERE owner = $1 AND kind = $2 AND name = $3 AND revision = $4;
+
+-- name: GetSecretVersions :many
+SELECT * FROM secret_versions
+WHERE owner = $1 AND kind = $2 AND name = $3
+ORDER BY revision DESC
+LIMIT $4;
+
+-- name: PruneSecretVersions :execrows
+-- Only the latest versions of every secret are kept.
+-- Versions of the secrets no longer stored are removed too.
+DELETE FROM secret_versions
+WHERE (owner, kind, name, revision) IN (
+  SELECT ranked.owner, ranked.kind, ranked.name, ranked.revision
+  FROM (
+    SELECT secret_versions.owner, secret_versions.kind, secret_versions.name, secret_versions.revision,
+      row_number() OVER (
+        PARTITION BY secret_versions.owner, secret_versions.kind, secret_versions.name
+        ORDER BY secret_versions.revision DESC
+      ) AS position
+    FROM secret_versions
+  ) AS ranked
+  WHERE ranked.position > sqlc.arg(keep)::int
+)
+OR NOT EXISTS (
+  SELECT 1 FROM secrets
+  WHERE secrets.owner = secret_versions.owner
+    AND secrets.kind = secret_versions.kind
+    AND secrets.name = secret_versions.name
+);
+
+-- name: PurgeSecretVersions :exec
+-- Versions of the purged secret are removed right away.
+DELETE FROM secret_versions
+WHERE owner = $1 AND kind = $2 AND name = $3;
+
+-- name: DeleteSecretVersions :exec
+DELETE FROM secret_versions
+WHERE owner = $1;
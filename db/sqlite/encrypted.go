@@ -0,0 +1,460 @@
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"gophkeeper/crypto"
+)
+
+// Encrypted database file is
+//
+//	magic | fileHeader | AES-256-GCM sealed gob encoded snapshot
+//
+// sealed with the key derived from the master key with the header params.
+var magic = []byte("GKDB\x01")
+
+// plainMagic starts every plain SQLite database file.
+var plainMagic = []byte("SQLite format 3\x00")
+
+// kdf derives the keys of the new encrypted files.
+var kdf = crypto.DefaultArgon2idHasher
+
+var (
+	ErrEncrypted    = errors.New("local database is encrypted, set the key to unlock it")
+	ErrWrongKey     = errors.New("wrong key or corrupted local database")
+	ErrNotEncrypted = errors.New("local database is not encrypted")
+)
+
+type format int
+
+const (
+	formatNone format = iota
+	formatPlain
+	formatEncrypted
+)
+
+// fileHeader keeps the key derivation params.
+type fileHeader struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	Salt        [16]byte
+}
+
+func (h fileHeader) deriveKey(masterKey []byte) []byte {
+	hasher := crypto.Argon2idHasher{Memory: h.Memory, Iterations: h.Iterations, Parallelism: h.Parallelism}
+	return hasher.Key(masterKey, h.Salt[:])
+}
+
+// snapshot is the whole database: the schema to create it and the table rows.
+type snapshot struct {
+	Schema []schemaObject
+	Tables []table
+}
+
+type schemaObject struct {
+	Type string
+	SQL  string
+}
+
+type table struct {
+	Name    string
+	Columns []string
+	Rows    [][]interface{}
+}
+
+func init() {
+	// Timestamps are scanned as time.Time
+	gob.Register(time.Time{})
+}
+
+// fileFormat tells the database file format by its first bytes.
+func fileFormat(path string) (format, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return formatNone, nil
+	}
+	if err != nil {
+		return formatNone, err
+	}
+	defer file.Close()
+
+	start := make([]byte, len(plainMagic))
+	n, err := io.ReadFull(file, start)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return formatNone, err
+	}
+	start = start[:n]
+
+	switch {
+	case n == 0:
+		return formatNone, nil
+	case bytes.HasPrefix(start, magic):
+		return formatEncrypted, nil
+	case bytes.Equal(start, plainMagic):
+		return formatPlain, nil
+	default:
+		return formatNone, fmt.Errorf("unknown local database format: %s", path)
+	}
+}
+
+// openEncrypted loads the encrypted database into memory.
+// A plain database is loaded and replaced with the encrypted one.
+func openEncrypted(path string, masterKey []byte, format format) (*DB, error) {
+	d := &DB{path: path}
+
+	var data *snapshot
+	var err error
+
+	switch format {
+	case formatEncrypted:
+		data, err = d.load(masterKey)
+	case formatPlain:
+		data, err = dumpFile(path)
+		if err == nil {
+			d.header, d.key, err = newHeader(masterKey)
+		}
+	default:
+		d.header, d.key, err = newHeader(masterKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	d.DB, err = openConn("file::memory:?")
+	if err != nil {
+		return nil, err
+	}
+
+	if data != nil {
+		err = restore(d.DB, data)
+		if err != nil {
+			d.DB.Close()
+			return nil, fmt.Errorf("failed to restore local database: %w", err)
+		}
+	}
+
+	err = runMigration(d.DB)
+	if err == nil {
+		err = d.save()
+	}
+	if err != nil {
+		d.DB.Close()
+		return nil, err
+	}
+
+	if format == formatPlain {
+		// Plain database file has been replaced, its leftovers are removed
+		for _, suffix := range []string{"-journal", "-wal", "-shm"} {
+			os.Remove(path + suffix)
+		}
+	}
+
+	d.changes = make(chan struct{}, 1)
+	d.saved = make(chan struct{})
+
+	go func() {
+		defer close(d.saved)
+
+		for range d.changes {
+			d.saveErr = d.save()
+		}
+	}()
+
+	return d, nil
+}
+
+// newHeader makes the header with a random salt and derives the file key with it.
+func newHeader(masterKey []byte) (fileHeader, []byte, error) {
+	header := fileHeader{Memory: kdf.Memory, Iterations: kdf.Iterations, Parallelism: kdf.Parallelism}
+
+	_, err := rand.Read(header.Salt[:])
+	if err != nil {
+		return fileHeader{}, nil, err
+	}
+
+	return header, header.deriveKey(masterKey), nil
+}
+
+// Rekey encrypts the database file with the key derived from the new master key.
+func (d *DB) Rekey(masterKey []byte) error {
+	if d.key == nil {
+		return ErrNotEncrypted
+	}
+
+	header, key, err := newHeader(masterKey)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	d.header, d.key = header, key
+	d.mu.Unlock()
+
+	return d.save()
+}
+
+// load reads the encrypted database file.
+func (d *DB) load(masterKey []byte) (*snapshot, error) {
+	content, err := os.ReadFile(d.path)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := bytes.NewReader(content[len(magic):])
+
+	err = binary.Read(reader, binary.BigEndian, &d.header)
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+
+	d.key = d.header.deriveKey(masterKey)
+
+	sealed, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := crypto.Decrypt(sealed, d.key)
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+
+	data := &snapshot{}
+
+	err = gob.NewDecoder(bytes.NewReader(plaintext)).Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode local database: %w", err)
+	}
+
+	return data, nil
+}
+
+// save replaces the database file with the current encrypted snapshot.
+func (d *DB) save() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	data, err := dump(d.DB)
+	if err != nil {
+		return fmt.Errorf("failed to dump local database: %w", err)
+	}
+
+	var plaintext bytes.Buffer
+
+	err = gob.NewEncoder(&plaintext).Encode(data)
+	if err != nil {
+		return err
+	}
+
+	sealed, err := crypto.Encrypt(plaintext.Bytes(), d.key)
+	if err != nil {
+		return err
+	}
+
+	var content bytes.Buffer
+	content.Write(magic)
+
+	err = binary.Write(&content, binary.BigEndian, d.header)
+	if err != nil {
+		return err
+	}
+
+	content.Write(sealed)
+
+	// The file is replaced atomically so it is never left half written
+	tmp := d.path + ".tmp"
+
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(content.Bytes())
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return os.Rename(tmp, d.path)
+}
+
+// dumpFile reads the plain database file.
+func dumpFile(path string) (*snapshot, error) {
+	conn, err := openFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return dump(conn)
+}
+
+// dump reads the whole database in a single transaction.
+func dump(conn *sql.DB) (*snapshot, error) {
+	ctx := context.Background()
+
+	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(
+		ctx,
+		"SELECT type, name, sql FROM sqlite_master WHERE sql IS NOT NULL AND name NOT LIKE 'sqlite_%' ORDER BY rowid",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := &snapshot{}
+	var names []string
+
+	for rows.Next() {
+		var object schemaObject
+		var name string
+
+		err = rows.Scan(&object.Type, &name, &object.SQL)
+		if err != nil {
+			return nil, err
+		}
+
+		data.Schema = append(data.Schema, object)
+		if object.Type == "table" {
+			names = append(names, name)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		table, err := dumpTable(ctx, tx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		data.Tables = append(data.Tables, table)
+	}
+
+	return data, nil
+}
+
+func dumpTable(ctx context.Context, tx *sql.Tx, name string) (table, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT * FROM "+quote(name))
+	if err != nil {
+		return table{}, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return table{}, err
+	}
+
+	dumped := table{Name: name, Columns: columns}
+
+	for rows.Next() {
+		row := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range row {
+			pointers[i] = &row[i]
+		}
+
+		err = rows.Scan(pointers...)
+		if err != nil {
+			return table{}, err
+		}
+
+		dumped.Rows = append(dumped.Rows, row)
+	}
+
+	return dumped, rows.Err()
+}
+
+// restore creates the snapshot schema and inserts the rows into the empty database.
+func restore(conn *sql.DB, data *snapshot) error {
+	ctx := context.Background()
+
+	// Rows are inserted table by table regardless of their references
+	_, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF")
+	if err != nil {
+		return err
+	}
+	defer conn.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Indexes and triggers are created once the tables are filled
+	for _, object := range data.Schema {
+		if object.Type == "table" {
+			if _, err = tx.ExecContext(ctx, object.SQL); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, table := range data.Tables {
+		columns := make([]string, len(table.Columns))
+		for i, column := range table.Columns {
+			columns[i] = quote(column)
+		}
+
+		insert := fmt.Sprintf(
+			"INSERT INTO %s (%s) VALUES (%s)",
+			quote(table.Name),
+			strings.Join(columns, ", "),
+			strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", "),
+		)
+
+		for _, row := range table.Rows {
+			for i, value := range row {
+				// Empty blobs are scanned as nil slices, the driver binds them as NULL
+				if blob, ok := value.([]byte); ok && blob == nil {
+					row[i] = []byte{}
+				}
+			}
+
+			if _, err = tx.ExecContext(ctx, insert, row...); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, object := range data.Schema {
+		if object.Type != "table" {
+			if _, err = tx.ExecContext(ctx, object.SQL); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+func quote(identifier string) string {
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
@@ -0,0 +1,228 @@
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/crypto"
+	"gophkeeper/db/db"
+	"gophkeeper/random"
+)
+
+func init() {
+	// Cheap key derivation for the tests
+	kdf = crypto.Argon2idHasher{Memory: 1024, Iterations: 1, Parallelism: 1}
+}
+
+func TestEncrypted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gophkeeper.db")
+	masterKey := []byte(random.RandomString(32))
+	owner := random.RandomOwner()
+	name := random.RandomString(10)
+	ctx := context.Background()
+
+	conn, err := Open(path, masterKey)
+	require.NoError(t, err)
+
+	queries := NewQuerier(conn)
+
+	err = queries.CreateLocalUser(ctx, owner)
+	require.NoError(t, err)
+
+	// Empty value of a tombstone has to survive the snapshot too
+	for value, revision := range map[string]int64{"": 1, "value": 2} {
+		err = queries.ReplaceLocalSecret(ctx, db.ReplaceLocalSecretParams{
+			Owner:    owner,
+			Kind:     1,
+			Name:     name + value,
+			Value:    []byte(value),
+			Created:  time.Now(),
+			Modified: time.Now(),
+			Revision: revision,
+		})
+		require.NoError(t, err)
+	}
+
+	err = queries.SaveCachedTokens(ctx, db.SaveCachedTokensParams{Owner: owner, Token: "token", RefreshToken: "refresh"})
+	require.NoError(t, err)
+
+	require.NoError(t, conn.Close())
+
+	// Nothing is stored in plaintext
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(content, magic))
+	for _, plaintext := range []string{owner, name, "refresh", "secrets"} {
+		require.NotContains(t, string(content), plaintext)
+	}
+
+	_, err = Open(path, nil)
+	require.ErrorIs(t, err, ErrEncrypted)
+
+	_, err = Open(path, []byte(random.RandomString(32)))
+	require.ErrorIs(t, err, ErrWrongKey)
+
+	conn, err = Open(path, masterKey)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	queries = NewQuerier(conn)
+
+	secrets, err := queries.GetSecretsByUser(ctx, owner)
+	require.NoError(t, err)
+	require.Len(t, secrets, 2)
+
+	secret, err := queries.GetSecret(ctx, db.GetSecretParams{Owner: owner, Kind: 1, Name: name + "value"})
+	require.NoError(t, err)
+	require.Equal(t, []byte("value"), secret.Value)
+	require.Equal(t, int64(2), secret.Revision)
+
+	tokens, err := queries.GetCachedTokens(ctx, owner)
+	require.NoError(t, err)
+	require.Equal(t, "token", tokens.Token)
+
+	// Foreign keys and the versions trigger are restored
+	err = queries.ReplaceLocalSecret(ctx, db.ReplaceLocalSecretParams{
+		Owner:    owner,
+		Kind:     1,
+		Name:     name + "value",
+		Value:    []byte("new value"),
+		Created:  time.Now(),
+		Modified: time.Now(),
+		Revision: 3,
+	})
+	require.NoError(t, err)
+
+	versions, err := queries.GetSecretVersions(ctx, db.GetSecretVersionsParams{Owner: owner, Kind: 1, Name: name + "value", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+
+	err = queries.DeleteUserWithSecrets(ctx, owner)
+	require.NoError(t, err)
+
+	secrets, err = queries.GetSecretsByUser(ctx, owner)
+	require.NoError(t, err)
+	require.Empty(t, secrets)
+}
+
+func TestEncryptPlain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gophkeeper.db")
+	masterKey := []byte(random.RandomString(32))
+	owner := random.RandomOwner()
+
+	conn, err := Open(path, nil)
+	require.NoError(t, err)
+
+	err = NewQuerier(conn).CreateLocalUser(context.Background(), owner)
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	format, err := fileFormat(path)
+	require.NoError(t, err)
+	require.Equal(t, formatPlain, format)
+
+	// Plain database is encrypted on the first open with the key
+	conn, err = Open(path, masterKey)
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	format, err = fileFormat(path)
+	require.NoError(t, err)
+	require.Equal(t, formatEncrypted, format)
+
+	conn, err = Open(path, masterKey)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = NewQuerier(conn).GetCachedTokens(context.Background(), owner)
+	require.Error(t, err)
+
+	err = NewQuerier(conn).CreateLocalUser(context.Background(), owner)
+	require.NoError(t, err)
+}
+
+func TestRekey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gophkeeper.db")
+	masterKey := []byte(random.RandomString(32))
+	newMasterKey := []byte(random.RandomString(32))
+	owner := random.RandomOwner()
+
+	conn, err := Open(path, masterKey)
+	require.NoError(t, err)
+
+	err = NewQuerier(conn).CreateLocalUser(context.Background(), owner)
+	require.NoError(t, err)
+
+	err = conn.Rekey(newMasterKey)
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	_, err = Open(path, masterKey)
+	require.ErrorIs(t, err, ErrWrongKey)
+
+	conn, err = Open(path, newMasterKey)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	err = NewQuerier(conn).SaveCachedTokens(
+		context.Background(),
+		db.SaveCachedTokensParams{Owner: owner, Token: "token", RefreshToken: "refresh"},
+	)
+	require.NoError(t, err)
+
+	// Plain database has no key to replace
+	plain, err := Open(filepath.Join(t.TempDir(), "plain.db"), nil)
+	require.NoError(t, err)
+	defer plain.Close()
+
+	require.ErrorIs(t, plain.Rekey(newMasterKey), ErrNotEncrypted)
+}
+
+func TestSync(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gophkeeper.db")
+	masterKey := []byte(random.RandomString(32))
+	owner := random.RandomOwner()
+	ctx := context.Background()
+
+	conn, err := Open(path, masterKey)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	queries := NewQuerier(conn)
+
+	err = queries.CreateLocalUser(ctx, owner)
+	require.NoError(t, err)
+
+	err = queries.SavePendingRekey(ctx, db.SavePendingRekeyParams{Owner: owner, KeyCheck: []byte("check")})
+	require.NoError(t, err)
+
+	// The change is in the file before the database is closed
+	require.NoError(t, conn.Sync())
+
+	saved, err := Open(path, masterKey)
+	require.NoError(t, err)
+	defer saved.Close()
+
+	pending, err := NewQuerier(saved).GetPendingRekey(ctx, owner)
+	require.NoError(t, err)
+	require.Equal(t, []byte("check"), pending.KeyCheck)
+
+	// Plain database has nothing to save
+	plain, err := Open(filepath.Join(t.TempDir(), "plain.db"), nil)
+	require.NoError(t, err)
+	defer plain.Close()
+
+	require.NoError(t, plain.Sync())
+}
+
+func TestReadOnly(t *testing.T) {
+	require.True(t, readOnly("-- name: GetSecret :one\nSELECT * FROM secrets"))
+	require.False(t, readOnly("-- name: CleanSecrets :many\n-- Client side.\nDELETE FROM secrets"))
+	require.False(t, readOnly("-- name: UpdateSecret :one\nWITH previous AS (SELECT 1)\nUPDATE secrets"))
+}
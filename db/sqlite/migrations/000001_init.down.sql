@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS secrets_keep_version;
+DROP TABLE IF EXISTS secret_versions;
+DROP TABLE IF EXISTS secret_conflicts;
+DROP TABLE IF EXISTS sync_cursors;
+DROP TABLE IF EXISTS secrets;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,62 @@
+-- Client local cache. Mirrors the client part of the PostgreSQL schema,
+-- the columns are in the same order for the generated queries to scan them.
+CREATE TABLE users (
+  id INTEGER PRIMARY KEY,
+  name TEXT NOT NULL UNIQUE,
+  passhash TEXT NOT NULL,
+  totp_secret TEXT NOT NULL DEFAULT '',
+  totp_enabled BOOLEAN NOT NULL DEFAULT false,
+  totp_last_step INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE secrets (
+  id INTEGER PRIMARY KEY,
+  owner TEXT NOT NULL REFERENCES users (name) ON DELETE CASCADE,
+  kind INTEGER NOT NULL,
+  name TEXT NOT NULL,
+  value BLOB NOT NULL,
+  created TIMESTAMP NOT NULL DEFAULT (now()),
+  modified TIMESTAMP NOT NULL DEFAULT (now()),
+  deleted BOOLEAN NOT NULL DEFAULT false,
+  revision INTEGER NOT NULL DEFAULT 0,
+  dirty BOOLEAN NOT NULL DEFAULT false,
+  deleted_at TIMESTAMP,
+  UNIQUE (owner, kind, name)
+);
+
+CREATE INDEX secrets_owner_revision_idx ON secrets (owner, revision);
+
+CREATE TABLE sync_cursors (
+  owner TEXT PRIMARY KEY REFERENCES users (name) ON DELETE CASCADE,
+  revision INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE secret_conflicts (
+  owner TEXT NOT NULL REFERENCES users (name) ON DELETE CASCADE,
+  kind INTEGER NOT NULL,
+  name TEXT NOT NULL,
+  value BLOB NOT NULL,
+  created TIMESTAMP NOT NULL,
+  modified TIMESTAMP NOT NULL,
+  deleted BOOLEAN NOT NULL DEFAULT false,
+  revision INTEGER NOT NULL,
+  PRIMARY KEY (owner, kind, name)
+);
+
+CREATE TABLE secret_versions (
+  owner TEXT NOT NULL REFERENCES users (name) ON DELETE CASCADE,
+  kind INTEGER NOT NULL,
+  name TEXT NOT NULL,
+  value BLOB NOT NULL,
+  modified TIMESTAMP NOT NULL,
+  revision INTEGER NOT NULL,
+  PRIMARY KEY (owner, kind, name, revision)
+);
+
+-- Replaced server copies are kept as versions (PostgreSQL queries do it with a CTE)
+CREATE TRIGGER secrets_keep_version BEFORE UPDATE OF value ON secrets
+WHEN OLD.revision > 0 AND OLD.deleted = false AND OLD.dirty = false AND NEW.value IS NOT OLD.value
+BEGIN
+  INSERT OR IGNORE INTO secret_versions (owner, kind, name, value, modified, revision)
+  VALUES (OLD.owner, OLD.kind, OLD.name, OLD.value, OLD.modified, OLD.revision);
+END;
@@ -0,0 +1,5 @@
+CREATE TABLE cached_tokens (
+  owner TEXT PRIMARY KEY REFERENCES users (name) ON DELETE CASCADE,
+  token TEXT NOT NULL,
+  refresh_token TEXT NOT NULL
+);
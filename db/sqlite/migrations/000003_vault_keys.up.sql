@@ -0,0 +1,8 @@
+CREATE TABLE vault_keys (
+  owner TEXT PRIMARY KEY REFERENCES users (name) ON DELETE CASCADE,
+  salt BLOB NOT NULL,
+  memory INTEGER NOT NULL,
+  iterations INTEGER NOT NULL,
+  parallelism INTEGER NOT NULL,
+  key_check BLOB NOT NULL
+);
@@ -0,0 +1,2 @@
+ALTER TABLE secret_conflicts DROP COLUMN remote_id;
+ALTER TABLE secrets DROP COLUMN remote_id;
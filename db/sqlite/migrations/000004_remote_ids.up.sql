@@ -0,0 +1,2 @@
+ALTER TABLE secrets ADD COLUMN remote_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE secret_conflicts ADD COLUMN remote_id TEXT NOT NULL DEFAULT '';
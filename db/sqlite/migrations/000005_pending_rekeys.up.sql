@@ -0,0 +1,4 @@
+CREATE TABLE pending_rekeys (
+  owner TEXT PRIMARY KEY REFERENCES users (name) ON DELETE CASCADE,
+  key_check BLOB NOT NULL
+);
@@ -0,0 +1,87 @@
+-- SQLite versions of the client queries which can't be run as they are written
+-- for PostgreSQL. All the other queries are run unchanged.
+
+-- name: UpsertLocalSecret :one
+-- The replaced server copy is kept as a version by the trigger.
+INSERT INTO secrets (
+  owner,
+  kind,
+  name,
+  value,
+  created,
+  modified,
+  revision,
+  dirty,
+  remote_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6, 0, true, $7
+)
+ON CONFLICT (owner, kind, name) DO UPDATE
+SET value = excluded.value,
+  modified = excluded.modified,
+  deleted = false,
+  deleted_at = NULL,
+  dirty = true
+RETURNING *;
+
+-- name: ReplaceLocalSecret :exec
+-- The replaced server copy is kept as a version by the trigger.
+INSERT INTO secrets (
+  owner,
+  kind,
+  name,
+  value,
+  created,
+  modified,
+  revision,
+  dirty,
+  remote_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, false, $8
+)
+ON CONFLICT (owner, kind, name) DO UPDATE
+SET value = excluded.value,
+  created = excluded.created,
+  modified = excluded.modified,
+  revision = excluded.revision,
+  remote_id = excluded.remote_id,
+  deleted = false,
+  deleted_at = NULL,
+  dirty = false;
+
+-- name: PurgeLocalSecret :exec
+UPDATE secrets
+SET value = X'',
+  deleted_at = NULL,
+  dirty = true
+WHERE owner = $1 AND kind = $2 AND name = $3 AND deleted = true;
+
+-- name: CleanSecrets :many
+-- Times are stored as text, julianday compares them regardless of the time zone.
+DELETE FROM secrets
+WHERE deleted = true AND dirty = false
+  AND (deleted_at IS NULL OR julianday(deleted_at) < julianday($1))
+RETURNING *;
+
+-- name: PruneSecretVersions :execrows
+DELETE FROM secret_versions
+WHERE (owner, kind, name, revision) IN (
+  SELECT owner, kind, name, revision
+  FROM (
+    SELECT owner, kind, name, revision,
+      row_number() OVER (PARTITION BY owner, kind, name ORDER BY revision DESC) AS position
+    FROM secret_versions
+  )
+  WHERE position > $1
+)
+OR NOT EXISTS (
+  SELECT 1 FROM secrets
+  WHERE secrets.owner = secret_versions.owner
+    AND secrets.kind = secret_versions.kind
+    AND secrets.name = secret_versions.name
+);
+
+-- name: DeleteUserWithSecrets :exec
+-- All the user data is removed by the foreign keys.
+DELETE FROM users
+WHERE name = $1;
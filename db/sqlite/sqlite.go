@@ -0,0 +1,296 @@
+// Package sqlite is the embedded SQLite storage of the client local cache.
+// It runs the same generated queries as PostgreSQL so it provides the same db.Querier.
+package sqlite
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	migratesqlite "github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	sqlitedriver "modernc.org/sqlite"
+
+	"gophkeeper/db/db"
+)
+
+// timeFormat is the format times are stored in. The driver writes query params in it too.
+const timeFormat = "2006-01-02 15:04:05.999999999-07:00"
+
+// Migrations are embedded so the client works from any directory.
+//
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+//go:embed queries.sql
+var queriesSQL string
+
+// sqliteQueries are the SQLite versions of the queries by name.
+var sqliteQueries = parseQueries(queriesSQL)
+
+func init() {
+	// PostgreSQL queries set the current time with now()
+	sqlitedriver.MustRegisterScalarFunction(
+		"now",
+		0,
+		func(*sqlitedriver.FunctionContext, []driver.Value) (driver.Value, error) {
+			return time.Now().Format(timeFormat), nil
+		},
+	)
+}
+
+// DB is the local database.
+//
+// Encrypted database is kept in memory and is saved to its file encrypted as a whole
+// in the background after every change, so nothing but its size is stored in plaintext.
+type DB struct {
+	*sql.DB
+
+	// path, header and key of the encrypted database file, key is nil for the plain one.
+	// The key is replaced on rekey while the database is saved in the background.
+	mu     sync.Mutex
+	path   string
+	header fileHeader
+	key    []byte
+
+	changes chan struct{}
+	saved   chan struct{}
+	saveErr error
+}
+
+// Open opens the local database file creating its directory if needed and runs the migrations.
+//
+// The database is encrypted with the key derived from the master key. A plain database
+// is encrypted on the first open with the master key, an encrypted one is never opened without it.
+func Open(path string, masterKey []byte) (*DB, error) {
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create db directory: %w", err)
+	}
+
+	format, err := fileFormat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if masterKey == nil {
+		if format == formatEncrypted {
+			return nil, ErrEncrypted
+		}
+
+		conn, err := openFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		return &DB{DB: conn}, nil
+	}
+
+	return openEncrypted(path, masterKey, format)
+}
+
+// openFile opens the plain database file.
+func openFile(path string) (*sql.DB, error) {
+	conn, err := openConn("file:" + path + "?_pragma=busy_timeout(5000)&")
+	if err != nil {
+		return nil, err
+	}
+
+	err = runMigration(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// openConn opens the database with the DSN extended with the common params.
+func openConn(dsn string) (*sql.DB, error) {
+	conn, err := sql.Open("sqlite", dsn+"_pragma=foreign_keys(1)&_time_format=sqlite")
+	if err != nil {
+		return nil, err
+	}
+
+	// Background jobs and the TUI write concurrently, SQLite allows a single writer.
+	// In memory database lives as long as its only connection.
+	conn.SetMaxOpenConns(1)
+	conn.SetConnMaxLifetime(0)
+	conn.SetConnMaxIdleTime(0)
+
+	return conn, nil
+}
+
+func runMigration(conn *sql.DB) error {
+	source, err := iofs.New(migrations, "migrations")
+	if err != nil {
+		return fmt.Errorf("cannot load migrations: %w", err)
+	}
+
+	driver, err := migratesqlite.WithInstance(conn, &migratesqlite.Config{})
+	if err != nil {
+		return fmt.Errorf("cannot create new migrate instance: %w", err)
+	}
+
+	migration, err := migrate.NewWithInstance("iofs", source, "sqlite", driver)
+	if err != nil {
+		return fmt.Errorf("cannot create new migrate instance: %w", err)
+	}
+
+	if err = migration.Up(); err != nil && err != migrate.ErrNoChange {
+		return fmt.Errorf("failed to run migrate up: %w", err)
+	}
+
+	return nil
+}
+
+// Close saves the encrypted database and closes it. It must be called after all the queries are done.
+func (d *DB) Close() error {
+	if d.changes != nil {
+		close(d.changes)
+		<-d.saved
+	}
+
+	err := d.DB.Close()
+	if d.saveErr != nil {
+		return d.saveErr
+	}
+
+	return err
+}
+
+// Sync saves the encrypted database right away. The plain one is always up to date.
+func (d *DB) Sync() error {
+	if d.changes == nil {
+		return nil
+	}
+
+	return d.save()
+}
+
+// changed schedules the encrypted database to be saved.
+func (d *DB) changed() {
+	if d.changes == nil {
+		return
+	}
+
+	select {
+	case d.changes <- struct{}{}:
+	default:
+		// The save is already pending
+	}
+}
+
+// NewQuerier returns the queries run on the local database.
+func NewQuerier(conn *DB) db.Querier {
+	return db.New(dbtx{conn.DB, conn.changed})
+}
+
+// dbtx replaces the queries written for PostgreSQL only with their SQLite versions
+// and reports the changes.
+type dbtx struct {
+	db.DBTX
+	changed func()
+}
+
+func (d dbtx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	defer d.change(query)
+	return d.DBTX.ExecContext(ctx, sqliteQuery(query), args...)
+}
+
+func (d dbtx) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return d.DBTX.PrepareContext(ctx, sqliteQuery(query))
+}
+
+func (d dbtx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	defer d.change(query)
+	return d.DBTX.QueryContext(ctx, sqliteQuery(query), args...)
+}
+
+func (d dbtx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	defer d.change(query)
+	return d.DBTX.QueryRowContext(ctx, sqliteQuery(query), args...)
+}
+
+// change reports the query unless it only reads.
+func (d dbtx) change(query string) {
+	if !readOnly(query) {
+		d.changed()
+	}
+}
+
+// readOnly reports whether the query is a plain SELECT.
+func readOnly(query string) bool {
+	for _, line := range strings.Split(query, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "--") {
+			continue
+		}
+
+		return strings.HasPrefix(strings.ToUpper(line), "SELECT")
+	}
+
+	return true
+}
+
+// sqliteQuery returns the SQLite version of the generated query if there is one.
+func sqliteQuery(query string) string {
+	if sqliteQuery, ok := sqliteQueries[queryName(query)]; ok {
+		return sqliteQuery
+	}
+
+	return query
+}
+
+// queryName returns the name of the query from its "-- name: <name> :<kind>" header.
+func queryName(query string) string {
+	header, _, _ := strings.Cut(query, "\n")
+
+	fields := strings.Fields(header)
+	if len(fields) < 3 || fields[0] != "--" || fields[1] != "name:" {
+		return ""
+	}
+
+	return fields[2]
+}
+
+// parseQueries splits the queries file into the named queries.
+func parseQueries(queries string) map[string]string {
+	parsed := map[string]string{}
+
+	var name string
+	var query strings.Builder
+
+	save := func() {
+		if name != "" {
+			parsed[name] = strings.TrimSpace(query.String())
+		}
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(queries))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if header := queryName(line); header != "" {
+			save()
+			name = header
+			query.Reset()
+		}
+
+		if name != "" {
+			query.WriteString(line + "\n")
+		}
+	}
+	save()
+
+	return parsed
+}
@@ -0,0 +1,254 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/db/db"
+	"gophkeeper/random"
+)
+
+func newTestQuerier(t *testing.T) (db.Querier, string) {
+	conn, err := Open(filepath.Join(t.TempDir(), "cache", "gophkeeper.db"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	owner := random.RandomOwner()
+	queries := NewQuerier(conn)
+
+	err = queries.CreateLocalUser(context.Background(), owner)
+	require.NoError(t, err)
+
+	return queries, owner
+}
+
+func TestOpenTwice(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gophkeeper.db")
+
+	conn, err := Open(path, nil)
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	conn, err = Open(path, nil)
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+}
+
+func TestQueryName(t *testing.T) {
+	require.Equal(t, "GetSecret", queryName("-- name: GetSecret :one\nSELECT 1"))
+	require.Equal(t, "", queryName("SELECT 1"))
+
+	for _, name := range []string{
+		"UpsertLocalSecret",
+		"ReplaceLocalSecret",
+		"CleanSecrets",
+		"PruneSecretVersions",
+		"DeleteUserWithSecrets",
+	} {
+		require.Contains(t, sqliteQueries, name)
+	}
+}
+
+func TestSecretSync(t *testing.T) {
+	queries, owner := newTestQuerier(t)
+	ctx := context.Background()
+	key := db.GetSecretParams{Owner: owner, Kind: 1, Name: random.RandomString(10)}
+	now := time.Now().Truncate(time.Microsecond)
+
+	secret, err := queries.UpsertLocalSecret(ctx, db.UpsertLocalSecretParams{
+		Owner:    owner,
+		Kind:     key.Kind,
+		Name:     key.Name,
+		Value:    []byte("first"),
+		Created:  now,
+		Modified: now,
+	})
+	require.NoError(t, err)
+	require.True(t, secret.Dirty)
+	require.WithinDuration(t, now, secret.Created, time.Microsecond)
+
+	dirty, err := queries.GetDirtySecrets(ctx, owner)
+	require.NoError(t, err)
+	require.Len(t, dirty, 1)
+
+	err = queries.MarkSecretSynced(ctx, db.MarkSecretSyncedParams{
+		Owner:    owner,
+		Kind:     key.Kind,
+		Name:     key.Name,
+		Revision: 1,
+		Modified: secret.Modified,
+	})
+	require.NoError(t, err)
+
+	err = queries.SetSyncCursor(ctx, db.SetSyncCursorParams{Owner: owner, Revision: 1})
+	require.NoError(t, err)
+
+	cursor, err := queries.GetSyncCursor(ctx, owner)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), cursor)
+
+	// Synced value becomes a version once replaced
+	err = queries.ReplaceLocalSecret(ctx, db.ReplaceLocalSecretParams{
+		Owner:    owner,
+		Kind:     key.Kind,
+		Name:     key.Name,
+		Value:    []byte("second"),
+		Created:  now,
+		Modified: now.Add(time.Second),
+		Revision: 2,
+	})
+	require.NoError(t, err)
+
+	secret, err = queries.GetSecret(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, []byte("second"), secret.Value)
+	require.Equal(t, int64(2), secret.Revision)
+	require.False(t, secret.Dirty)
+
+	versions, err := queries.GetSecretVersions(ctx, db.GetSecretVersionsParams{
+		Owner: owner,
+		Kind:  key.Kind,
+		Name:  key.Name,
+		Limit: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	require.Equal(t, []byte("first"), versions[0].Value)
+	require.Equal(t, int64(1), versions[0].Revision)
+
+	_, err = queries.UpsertLocalSecret(ctx, db.UpsertLocalSecretParams{
+		Owner:    owner,
+		Kind:     key.Kind,
+		Name:     key.Name,
+		Value:    []byte("third"),
+		Created:  now,
+		Modified: now.Add(2 * time.Second),
+	})
+	require.NoError(t, err)
+
+	pruned, err := queries.PruneSecretVersions(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), pruned)
+
+	versions, err = queries.GetSecretVersions(ctx, db.GetSecretVersionsParams{
+		Owner: owner,
+		Kind:  key.Kind,
+		Name:  key.Name,
+		Limit: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	require.Equal(t, []byte("second"), versions[0].Value)
+}
+
+func TestSecretTrash(t *testing.T) {
+	queries, owner := newTestQuerier(t)
+	ctx := context.Background()
+	key := db.GetSecretParams{Owner: owner, Kind: 1, Name: random.RandomString(10)}
+
+	err := queries.ReplaceLocalSecret(ctx, db.ReplaceLocalSecretParams{
+		Owner:    owner,
+		Kind:     key.Kind,
+		Name:     key.Name,
+		Value:    []byte(random.RandomString(10)),
+		Created:  time.Now(),
+		Modified: time.Now(),
+		Revision: 1,
+	})
+	require.NoError(t, err)
+
+	err = queries.MarkLocalSecretDeleted(ctx, db.MarkLocalSecretDeletedParams{
+		Owner: owner,
+		Kind:  key.Kind,
+		Name:  key.Name,
+	})
+	require.NoError(t, err)
+
+	trash, err := queries.GetTrashedSecrets(ctx, owner)
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	require.True(t, trash[0].Dirty)
+	require.True(t, trash[0].DeletedAt.Valid)
+
+	// Deletion to push is never cleaned
+	cleaned, err := queries.CleanSecrets(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, cleaned)
+
+	err = queries.MarkSecretSynced(ctx, db.MarkSecretSyncedParams{
+		Owner:    owner,
+		Kind:     key.Kind,
+		Name:     key.Name,
+		Revision: 2,
+		Modified: trash[0].Modified,
+		Deleted:  true,
+	})
+	require.NoError(t, err)
+
+	cleaned, err = queries.CleanSecrets(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, cleaned)
+
+	cleaned, err = queries.CleanSecrets(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, cleaned, 1)
+
+	_, err = queries.GetSecret(ctx, key)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestSecretConflict(t *testing.T) {
+	queries, owner := newTestQuerier(t)
+	ctx := context.Background()
+	name := random.RandomString(10)
+
+	err := queries.SaveSecretConflict(ctx, db.SaveSecretConflictParams{
+		Owner:    owner,
+		Kind:     1,
+		Name:     name,
+		Value:    []byte(random.RandomString(10)),
+		Created:  time.Now(),
+		Modified: time.Now(),
+		Revision: 3,
+	})
+	require.NoError(t, err)
+
+	conflicts, err := queries.GetSecretConflicts(ctx, owner)
+	require.NoError(t, err)
+	require.Len(t, conflicts, 1)
+	require.Equal(t, name, conflicts[0].Name)
+	require.Equal(t, int64(3), conflicts[0].Revision)
+}
+
+func TestDeleteUserWithSecrets(t *testing.T) {
+	queries, owner := newTestQuerier(t)
+	ctx := context.Background()
+
+	_, err := queries.UpsertLocalSecret(ctx, db.UpsertLocalSecretParams{
+		Owner:    owner,
+		Kind:     1,
+		Name:     random.RandomString(10),
+		Value:    []byte(random.RandomString(10)),
+		Created:  time.Now(),
+		Modified: time.Now(),
+	})
+	require.NoError(t, err)
+
+	err = queries.SetSyncCursor(ctx, db.SetSyncCursorParams{Owner: owner, Revision: 1})
+	require.NoError(t, err)
+
+	err = queries.DeleteUserWithSecrets(ctx, owner)
+	require.NoError(t, err)
+
+	secrets, err := queries.GetSecretsByUser(ctx, owner)
+	require.NoError(t, err)
+	require.Empty(t, secrets)
+
+	_, err = queries.GetSyncCursor(ctx, owner)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -26,8 +26,25 @@ type GophKeeperClient interface {
 	Ping(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error)
 	Register(ctx context.Context, in *User, opts ...grpc.CallOption) (*Token, error)
 	Login(ctx context.Context, in *User, opts ...grpc.CallOption) (*Token, error)
-	SetSecrets(ctx context.Context, in *Secrets, opts ...grpc.CallOption) (*empty.Empty, error)
+	RefreshToken(ctx context.Context, in *RefreshRequest, opts ...grpc.CallOption) (*Token, error)
+	GetTokenKeys(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*TokenKeys, error)
+	ChangePassword(ctx context.Context, in *ChangePasswordRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	DeleteAccount(ctx context.Context, in *DeleteAccountRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	EnrollTOTP(ctx context.Context, in *TOTPEnrollRequest, opts ...grpc.CallOption) (*TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, in *TOTPCode, opts ...grpc.CallOption) (*empty.Empty, error)
+	GetVaultKey(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*VaultKey, error)
+	CreateVaultKey(ctx context.Context, in *VaultKey, opts ...grpc.CallOption) (*VaultKey, error)
+	Rekey(ctx context.Context, in *RekeyRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	ListSessions(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*Sessions, error)
+	RevokeSession(ctx context.Context, in *SessionRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	RevokeAllSessions(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error)
+	ListAuditEvents(ctx context.Context, in *AuditEventsRequest, opts ...grpc.CallOption) (*AuditEvents, error)
+	SetSecrets(ctx context.Context, in *Secrets, opts ...grpc.CallOption) (*SyncResult, error)
+	SealSecrets(ctx context.Context, in *SealSecretsRequest, opts ...grpc.CallOption) (*SyncResult, error)
 	GetSecrets(ctx context.Context, in *SecretsRequest, opts ...grpc.CallOption) (*Secrets, error)
+	GetChanges(ctx context.Context, in *ChangesRequest, opts ...grpc.CallOption) (*Changes, error)
+	ListSecretVersions(ctx context.Context, in *SecretVersionsRequest, opts ...grpc.CallOption) (*SecretVersions, error)
+	RestoreSecretVersion(ctx context.Context, in *RestoreVersionRequest, opts ...grpc.CallOption) (*Secret, error)
 }
 
 type gophKeeperClient struct {
@@ -65,8 +82,125 @@ func (c *gophKeeperClient) Login(ctx context.Context, in *User, opts ...grpc.Cal
 	return out, nil
 }
 
-func (c *gophKeeperClient) SetSecrets(ctx context.Context, in *Secrets, opts ...grpc.CallOption) (*empty.Empty, error) {
+func (c *gophKeeperClient) RefreshToken(ctx context.Context, in *RefreshRequest, opts ...grpc.CallOption) (*Token, error) {
+	out := new(Token)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/RefreshToken", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) GetTokenKeys(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*TokenKeys, error) {
+	out := new(TokenKeys)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/GetTokenKeys", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) ChangePassword(ctx context.Context, in *ChangePasswordRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/ChangePassword", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) DeleteAccount(ctx context.Context, in *DeleteAccountRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/DeleteAccount", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) EnrollTOTP(ctx context.Context, in *TOTPEnrollRequest, opts ...grpc.CallOption) (*TOTPEnrollment, error) {
+	out := new(TOTPEnrollment)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/EnrollTOTP", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) ConfirmTOTP(ctx context.Context, in *TOTPCode, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/ConfirmTOTP", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) GetVaultKey(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*VaultKey, error) {
+	out := new(VaultKey)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/GetVaultKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) CreateVaultKey(ctx context.Context, in *VaultKey, opts ...grpc.CallOption) (*VaultKey, error) {
+	out := new(VaultKey)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/CreateVaultKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) Rekey(ctx context.Context, in *RekeyRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/Rekey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) ListSessions(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*Sessions, error) {
+	out := new(Sessions)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/ListSessions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) RevokeSession(ctx context.Context, in *SessionRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/RevokeSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) RevokeAllSessions(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error) {
 	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/RevokeAllSessions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) ListAuditEvents(ctx context.Context, in *AuditEventsRequest, opts ...grpc.CallOption) (*AuditEvents, error) {
+	out := new(AuditEvents)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/ListAuditEvents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) SetSecrets(ctx context.Context, in *Secrets, opts ...grpc.CallOption) (*SyncResult, error) {
+	out := new(SyncResult)
 	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/SetSecrets", in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -74,6 +208,15 @@ func (c *gophKeeperClient) SetSecrets(ctx context.Context, in *Secrets, opts ...
 	return out, nil
 }
 
+func (c *gophKeeperClient) SealSecrets(ctx context.Context, in *SealSecretsRequest, opts ...grpc.CallOption) (*SyncResult, error) {
+	out := new(SyncResult)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/SealSecrets", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *gophKeeperClient) GetSecrets(ctx context.Context, in *SecretsRequest, opts ...grpc.CallOption) (*Secrets, error) {
 	out := new(Secrets)
 	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/GetSecrets", in, out, opts...)
@@ -83,6 +226,33 @@ func (c *gophKeeperClient) GetSecrets(ctx context.Context, in *SecretsRequest, o
 	return out, nil
 }
 
+func (c *gophKeeperClient) GetChanges(ctx context.Context, in *ChangesRequest, opts ...grpc.CallOption) (*Changes, error) {
+	out := new(Changes)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/GetChanges", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) ListSecretVersions(ctx context.Context, in *SecretVersionsRequest, opts ...grpc.CallOption) (*SecretVersions, error) {
+	out := new(SecretVersions)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/ListSecretVersions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) RestoreSecretVersion(ctx context.Context, in *RestoreVersionRequest, opts ...grpc.CallOption) (*Secret, error) {
+	out := new(Secret)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/RestoreSecretVersion", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // GophKeeperServer is the server API for GophKeeper service.
 // All implementations must embed UnimplementedGophKeeperServer
 // for forward compatibility
@@ -90,8 +260,25 @@ type GophKeeperServer interface {
 	Ping(context.Context, *empty.Empty) (*empty.Empty, error)
 	Register(context.Context, *User) (*Token, error)
 	Login(context.Context, *User) (*Token, error)
-	SetSecrets(context.Context, *Secrets) (*empty.Empty, error)
+	RefreshToken(context.Context, *RefreshRequest) (*Token, error)
+	GetTokenKeys(context.Context, *empty.Empty) (*TokenKeys, error)
+	ChangePassword(context.Context, *ChangePasswordRequest) (*empty.Empty, error)
+	DeleteAccount(context.Context, *DeleteAccountRequest) (*empty.Empty, error)
+	EnrollTOTP(context.Context, *TOTPEnrollRequest) (*TOTPEnrollment, error)
+	ConfirmTOTP(context.Context, *TOTPCode) (*empty.Empty, error)
+	GetVaultKey(context.Context, *empty.Empty) (*VaultKey, error)
+	CreateVaultKey(context.Context, *VaultKey) (*VaultKey, error)
+	Rekey(context.Context, *RekeyRequest) (*empty.Empty, error)
+	ListSessions(context.Context, *empty.Empty) (*Sessions, error)
+	RevokeSession(context.Context, *SessionRequest) (*empty.Empty, error)
+	RevokeAllSessions(context.Context, *empty.Empty) (*empty.Empty, error)
+	ListAuditEvents(context.Context, *AuditEventsRequest) (*AuditEvents, error)
+	SetSecrets(context.Context, *Secrets) (*SyncResult, error)
+	SealSecrets(context.Context, *SealSecretsRequest) (*SyncResult, error)
 	GetSecrets(context.Context, *SecretsRequest) (*Secrets, error)
+	GetChanges(context.Context, *ChangesRequest) (*Changes, error)
+	ListSecretVersions(context.Context, *SecretVersionsRequest) (*SecretVersions, error)
+	RestoreSecretVersion(context.Context, *RestoreVersionRequest) (*Secret, error)
 	mustEmbedUnimplementedGophKeeperServer()
 }
 
@@ -108,12 +295,63 @@ func (UnimplementedGophKeeperServer) Register(context.Context, *User) (*Token, e
 func (UnimplementedGophKeeperServer) Login(context.Context, *User) (*Token, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Login not implemented")
 }
-func (UnimplementedGophKeeperServer) SetSecrets(context.Context, *Secrets) (*empty.Empty, error) {
+func (UnimplementedGophKeeperServer) RefreshToken(context.Context, *RefreshRequest) (*Token, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefreshToken not implemented")
+}
+func (UnimplementedGophKeeperServer) GetTokenKeys(context.Context, *empty.Empty) (*TokenKeys, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTokenKeys not implemented")
+}
+func (UnimplementedGophKeeperServer) ChangePassword(context.Context, *ChangePasswordRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangePassword not implemented")
+}
+func (UnimplementedGophKeeperServer) DeleteAccount(context.Context, *DeleteAccountRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteAccount not implemented")
+}
+func (UnimplementedGophKeeperServer) EnrollTOTP(context.Context, *TOTPEnrollRequest) (*TOTPEnrollment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnrollTOTP not implemented")
+}
+func (UnimplementedGophKeeperServer) ConfirmTOTP(context.Context, *TOTPCode) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmTOTP not implemented")
+}
+func (UnimplementedGophKeeperServer) GetVaultKey(context.Context, *empty.Empty) (*VaultKey, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVaultKey not implemented")
+}
+func (UnimplementedGophKeeperServer) CreateVaultKey(context.Context, *VaultKey) (*VaultKey, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateVaultKey not implemented")
+}
+func (UnimplementedGophKeeperServer) Rekey(context.Context, *RekeyRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Rekey not implemented")
+}
+func (UnimplementedGophKeeperServer) ListSessions(context.Context, *empty.Empty) (*Sessions, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSessions not implemented")
+}
+func (UnimplementedGophKeeperServer) RevokeSession(context.Context, *SessionRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeSession not implemented")
+}
+func (UnimplementedGophKeeperServer) RevokeAllSessions(context.Context, *empty.Empty) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeAllSessions not implemented")
+}
+func (UnimplementedGophKeeperServer) ListAuditEvents(context.Context, *AuditEventsRequest) (*AuditEvents, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAuditEvents not implemented")
+}
+func (UnimplementedGophKeeperServer) SetSecrets(context.Context, *Secrets) (*SyncResult, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SetSecrets not implemented")
 }
+func (UnimplementedGophKeeperServer) SealSecrets(context.Context, *SealSecretsRequest) (*SyncResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SealSecrets not implemented")
+}
 func (UnimplementedGophKeeperServer) GetSecrets(context.Context, *SecretsRequest) (*Secrets, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetSecrets not implemented")
 }
+func (UnimplementedGophKeeperServer) GetChanges(context.Context, *ChangesRequest) (*Changes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetChanges not implemented")
+}
+func (UnimplementedGophKeeperServer) ListSecretVersions(context.Context, *SecretVersionsRequest) (*SecretVersions, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSecretVersions not implemented")
+}
+func (UnimplementedGophKeeperServer) RestoreSecretVersion(context.Context, *RestoreVersionRequest) (*Secret, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreSecretVersion not implemented")
+}
 func (UnimplementedGophKeeperServer) mustEmbedUnimplementedGophKeeperServer() {}
 
 // UnsafeGophKeeperServer may be embedded to opt out of forward compatibility for this service.
@@ -181,6 +419,240 @@ func _GophKeeper_Login_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GophKeeper_RefreshToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefreshRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).RefreshToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/RefreshToken",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).RefreshToken(ctx, req.(*RefreshRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_GetTokenKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).GetTokenKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/GetTokenKeys",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).GetTokenKeys(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_ChangePassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangePasswordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).ChangePassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/ChangePassword",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).ChangePassword(ctx, req.(*ChangePasswordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_DeleteAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteAccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).DeleteAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/DeleteAccount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).DeleteAccount(ctx, req.(*DeleteAccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_EnrollTOTP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TOTPEnrollRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).EnrollTOTP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/EnrollTOTP",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).EnrollTOTP(ctx, req.(*TOTPEnrollRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_ConfirmTOTP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TOTPCode)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).ConfirmTOTP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/ConfirmTOTP",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).ConfirmTOTP(ctx, req.(*TOTPCode))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_GetVaultKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).GetVaultKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/GetVaultKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).GetVaultKey(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_CreateVaultKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VaultKey)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).CreateVaultKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/CreateVaultKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).CreateVaultKey(ctx, req.(*VaultKey))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_Rekey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RekeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).Rekey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/Rekey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).Rekey(ctx, req.(*RekeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_ListSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).ListSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/ListSessions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).ListSessions(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_RevokeSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).RevokeSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/RevokeSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).RevokeSession(ctx, req.(*SessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_RevokeAllSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).RevokeAllSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/RevokeAllSessions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).RevokeAllSessions(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_ListAuditEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuditEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).ListAuditEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/ListAuditEvents",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).ListAuditEvents(ctx, req.(*AuditEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _GophKeeper_SetSecrets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Secrets)
 	if err := dec(in); err != nil {
@@ -199,6 +671,24 @@ func _GophKeeper_SetSecrets_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GophKeeper_SealSecrets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SealSecretsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).SealSecrets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/SealSecrets",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).SealSecrets(ctx, req.(*SealSecretsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _GophKeeper_GetSecrets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(SecretsRequest)
 	if err := dec(in); err != nil {
@@ -217,6 +707,60 @@ func _GophKeeper_GetSecrets_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GophKeeper_GetChanges_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).GetChanges(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/GetChanges",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).GetChanges(ctx, req.(*ChangesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_ListSecretVersions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SecretVersionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).ListSecretVersions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/ListSecretVersions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).ListSecretVersions(ctx, req.(*SecretVersionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_RestoreSecretVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreVersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).RestoreSecretVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/RestoreSecretVersion",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).RestoreSecretVersion(ctx, req.(*RestoreVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // GophKeeper_ServiceDesc is the grpc.ServiceDesc for GophKeeper service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -236,14 +780,82 @@ var GophKeeper_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Login",
 			Handler:    _GophKeeper_Login_Handler,
 		},
+		{
+			MethodName: "RefreshToken",
+			Handler:    _GophKeeper_RefreshToken_Handler,
+		},
+		{
+			MethodName: "GetTokenKeys",
+			Handler:    _GophKeeper_GetTokenKeys_Handler,
+		},
+		{
+			MethodName: "ChangePassword",
+			Handler:    _GophKeeper_ChangePassword_Handler,
+		},
+		{
+			MethodName: "DeleteAccount",
+			Handler:    _GophKeeper_DeleteAccount_Handler,
+		},
+		{
+			MethodName: "EnrollTOTP",
+			Handler:    _GophKeeper_EnrollTOTP_Handler,
+		},
+		{
+			MethodName: "ConfirmTOTP",
+			Handler:    _GophKeeper_ConfirmTOTP_Handler,
+		},
+		{
+			MethodName: "GetVaultKey",
+			Handler:    _GophKeeper_GetVaultKey_Handler,
+		},
+		{
+			MethodName: "CreateVaultKey",
+			Handler:    _GophKeeper_CreateVaultKey_Handler,
+		},
+		{
+			MethodName: "Rekey",
+			Handler:    _GophKeeper_Rekey_Handler,
+		},
+		{
+			MethodName: "ListSessions",
+			Handler:    _GophKeeper_ListSessions_Handler,
+		},
+		{
+			MethodName: "RevokeSession",
+			Handler:    _GophKeeper_RevokeSession_Handler,
+		},
+		{
+			MethodName: "RevokeAllSessions",
+			Handler:    _GophKeeper_RevokeAllSessions_Handler,
+		},
+		{
+			MethodName: "ListAuditEvents",
+			Handler:    _GophKeeper_ListAuditEvents_Handler,
+		},
 		{
 			MethodName: "SetSecrets",
 			Handler:    _GophKeeper_SetSecrets_Handler,
 		},
+		{
+			MethodName: "SealSecrets",
+			Handler:    _GophKeeper_SealSecrets_Handler,
+		},
 		{
 			MethodName: "GetSecrets",
 			Handler:    _GophKeeper_GetSecrets_Handler,
 		},
+		{
+			MethodName: "GetChanges",
+			Handler:    _GophKeeper_GetChanges_Handler,
+		},
+		{
+			MethodName: "ListSecretVersions",
+			Handler:    _GophKeeper_ListSecretVersions_Handler,
+		},
+		{
+			MethodName: "RestoreSecretVersion",
+			Handler:    _GophKeeper_RestoreSecretVersion_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "service.proto",
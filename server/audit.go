@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"gophkeeper/audit"
+	"gophkeeper/converter"
+	"gophkeeper/db/db"
+	"gophkeeper/pb"
+)
+
+const (
+	defaultAuditEventsLimit = 100
+	maxAuditEventsLimit     = 1000
+)
+
+// recordEvent appends the event to the audit log chained to the chain head.
+// The head is locked in the db to keep the chain linear across the server replicas.
+func (s *Server) recordEvent(ctx context.Context, eventType, username string, secretKind int32, secretName string) {
+	// Opaque IDs of the end-to-end encrypted secrets would link their events together
+	if secretKind == sealedKind {
+		secretName = ""
+	}
+
+	event := db.AuditEvent{
+		Type:       eventType,
+		Username:   username,
+		Peer:       peerAddress(ctx),
+		SecretKind: secretKind,
+		SecretName: secretName,
+		Created:    audit.Timestamp(time.Now()),
+	}
+
+	err := s.storage.WithTx(ctx, func(q db.Querier) error {
+		head, err := q.LockAuditHead(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to lock audit log head: %w", err)
+		}
+
+		created, err := q.CreateAuditEvent(
+			ctx,
+			db.CreateAuditEventParams{
+				Type:       event.Type,
+				Username:   event.Username,
+				Peer:       event.Peer,
+				SecretKind: event.SecretKind,
+				SecretName: event.SecretName,
+				Created:    event.Created,
+				PrevHash:   head.Hash,
+				Hash:       audit.Hash(s.auditKey, head.Hash, event),
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		return q.UpdateAuditHead(
+			ctx,
+			db.UpdateAuditHeadParams{
+				EventID: created.ID,
+				Hash:    created.Hash,
+				Mac:     audit.HeadMAC(s.auditKey, created.ID, created.Hash),
+			},
+		)
+	})
+	if err != nil {
+		s.log.Error().Err(err).Msgf("failed to record user '%s' %s event", username, eventType)
+	}
+}
+
+// ListAuditEvents returns the latest audit events of the user.
+func (s *Server) ListAuditEvents(ctx context.Context, in *pb.AuditEventsRequest) (*pb.AuditEvents, error) {
+	username, err := usernameFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := in.Limit
+	if limit <= 0 {
+		limit = defaultAuditEventsLimit
+	}
+	if limit > maxAuditEventsLimit {
+		limit = maxAuditEventsLimit
+	}
+
+	events, err := s.storage.GetAuditEventsByUser(
+		ctx,
+		db.GetAuditEventsByUserParams{
+			Username: username,
+			Limit:    limit,
+		},
+	)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("failed to get user '%s' audit events", username)
+		return nil, status.Errorf(codes.Internal, "failed to get audit events from db")
+	}
+
+	pbEvents := []*pb.AuditEvent{}
+	for _, event := range events {
+		pbEvents = append(pbEvents, converter.DBAuditEventToPBAuditEvent(event))
+	}
+
+	return &pb.AuditEvents{Events: pbEvents}, nil
+}
@@ -0,0 +1,179 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"gophkeeper/audit"
+	"gophkeeper/db/db"
+	"gophkeeper/db/mock"
+	"gophkeeper/pb"
+	"gophkeeper/random"
+)
+
+var testUsername8 = random.RandomOwner()
+
+func TestRecordEvent(t *testing.T) {
+	auditKey := []byte(random.RandomString(32))
+	head := db.AuditHead{EventID: 41, Hash: random.RandomString(64)}
+
+	// Create mock storage
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockStore(controller)
+	allowTx(mockStorage)
+
+	mockStorage.EXPECT().
+		LockAuditHead(
+			gomock.Any(),
+		).
+		Times(1).
+		Return(head, nil)
+
+	var created db.AuditEvent
+
+	// New event is chained to the head
+	mockStorage.EXPECT().
+		CreateAuditEvent(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateAuditEventParams) (db.AuditEvent, error) {
+			event := db.AuditEvent{
+				Type:       arg.Type,
+				Username:   arg.Username,
+				Peer:       arg.Peer,
+				SecretKind: arg.SecretKind,
+				SecretName: arg.SecretName,
+				Created:    arg.Created,
+			}
+
+			require.Equal(t, audit.EventSecretCreated, arg.Type)
+			require.Equal(t, testUsername8, arg.Username)
+			require.Equal(t, int32(1), arg.SecretKind)
+			require.Equal(t, "bank", arg.SecretName)
+			require.Equal(t, head.Hash, arg.PrevHash)
+			require.Equal(t, audit.Hash(auditKey, head.Hash, event), arg.Hash)
+
+			created = event
+			created.ID = 42
+			created.PrevHash = arg.PrevHash
+			created.Hash = arg.Hash
+
+			return created, nil
+		})
+
+	// Head is moved to the new event
+	mockStorage.EXPECT().
+		UpdateAuditHead(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.UpdateAuditHeadParams) error {
+			require.Equal(t, created.ID, arg.EventID)
+			require.Equal(t, created.Hash, arg.Hash)
+			require.Equal(t, audit.HeadMAC(auditKey, created.ID, created.Hash), arg.Mac)
+
+			return nil
+		})
+
+	testServer := &Server{
+		config:   Config{},
+		storage:  mockStorage,
+		auditKey: auditKey,
+	}
+
+	testServer.recordEvent(context.Background(), audit.EventSecretCreated, testUsername8, 1, "bank")
+}
+
+func TestRecordSealedEvent(t *testing.T) {
+	// Create mock storage
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockStore(controller)
+	allowTx(mockStorage)
+
+	mockStorage.EXPECT().
+		LockAuditHead(
+			gomock.Any(),
+		).
+		Times(1).
+		Return(db.AuditHead{}, nil)
+
+	// Opaque IDs of the end-to-end encrypted secrets are not recorded
+	mockStorage.EXPECT().
+		CreateAuditEvent(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateAuditEventParams) (db.AuditEvent, error) {
+			require.Equal(t, sealedKind, arg.SecretKind)
+			require.Empty(t, arg.SecretName)
+			return db.AuditEvent{ID: 1}, nil
+		})
+
+	mockStorage.EXPECT().
+		UpdateAuditHead(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(1).
+		Return(nil)
+
+	testServer := &Server{
+		config:   Config{},
+		storage:  mockStorage,
+		auditKey: []byte(random.RandomString(32)),
+	}
+
+	testServer.recordEvent(context.Background(), audit.EventSecretCreated, testUsername8, sealedKind, random.RandomString(32))
+}
+
+func TestRPCListAuditEvents(t *testing.T) {
+	// Create mock storage
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockStore(controller)
+
+	mockStorage.EXPECT().
+		GetAuditEventsByUser(
+			gomock.Any(),
+			db.GetAuditEventsByUserParams{
+				Username: testUsername8,
+				Limit:    defaultAuditEventsLimit,
+			},
+		).
+		Times(1).
+		Return(
+			[]db.AuditEvent{
+				{ID: 2, Type: audit.EventSecretCreated, Username: testUsername8, SecretName: "bank", Created: time.Now()},
+				{ID: 1, Type: audit.EventLogin, Username: testUsername8, Created: time.Now()},
+			},
+			nil,
+		)
+
+	// Create server
+	testServer := &Server{
+		config:  Config{},
+		storage: mockStorage,
+		tm:      newTestMaker(t),
+	}
+
+	// Run test gRPC server
+	client, closer := runTestServer(testServer, grpc.UnaryInterceptor(testServer.checkAuth))
+	defer closer()
+
+	ctx := newAuthContext(t, testServer.tm, mockStorage, testUsername8)
+
+	pbEvents, err := client.ListAuditEvents(ctx, &pb.AuditEventsRequest{})
+	require.NoError(t, err)
+	require.Len(t, pbEvents.Events, 2)
+	require.Equal(t, audit.EventSecretCreated, pbEvents.Events[0].Type)
+	require.Equal(t, "bank", pbEvents.Events[0].SecretName)
+	require.Equal(t, audit.EventLogin, pbEvents.Events[1].Type)
+}
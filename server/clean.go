@@ -3,6 +3,8 @@ package server
 import (
 	"context"
 	"time"
+
+	"gophkeeper/db/db"
 )
 
 func (s *Server) cleanJob(ctx context.Context) {
@@ -21,12 +23,71 @@ func (s *Server) cleanJob(ctx context.Context) {
 	}
 }
 
+// clean runs every cleanup on its own, a failed one doesn't skip the others.
 func (s *Server) clean(ctx context.Context) {
-	deletedSecrets, err := s.storage.CleanSecrets(ctx)
+	purgedSecrets, err := s.purgeSecrets(ctx)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to clean deleted secrets")
+	} else {
+		s.log.Info().Msgf("cleaned up %v deleted secrets", len(purgedSecrets))
+	}
+
+	oldVersions, err := s.storage.PruneSecretVersions(ctx, s.config.Versions)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to clean old secret versions")
+	} else {
+		s.log.Info().Msgf("cleaned up %v old secret versions", oldVersions)
+	}
+
+	expiredTokens, err := s.storage.CleanRefreshTokens(ctx)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to clean expired refresh tokens")
+	} else {
+		s.log.Info().Msgf("cleaned up %v expired refresh tokens", expiredTokens)
+	}
+
+	staleSessions, err := s.storage.CleanSessions(ctx, time.Now().Add(-refreshTokenDuration))
 	if err != nil {
-		s.log.Error().Msg("failed to clean deleted secrets")
-		return
+		s.log.Error().Err(err).Msg("failed to clean revoked and stale sessions")
+	} else {
+		s.log.Info().Msgf("cleaned up %v revoked and stale sessions", staleSessions)
 	}
+}
+
+// purgeSecrets removes the tombstones older than the retention period.
+// Users get the highest purged revision so clients synced before it resync all the secrets.
+func (s *Server) purgeSecrets(ctx context.Context) ([]db.Secret, error) {
+	var purgedSecrets []db.Secret
+
+	err := s.storage.WithTx(ctx, func(q db.Querier) error {
+		var err error
+		purgedSecrets, err = q.PurgeSecrets(ctx, time.Now().Add(-s.config.Tombstones))
+		if err != nil {
+			return err
+		}
+
+		purgedRevisions := map[string]int64{}
+		for _, secret := range purgedSecrets {
+			if secret.Revision > purgedRevisions[secret.Owner] {
+				purgedRevisions[secret.Owner] = secret.Revision
+			}
+		}
+
+		for owner, revision := range purgedRevisions {
+			err := q.SetPurgedRevision(
+				ctx,
+				db.SetPurgedRevisionParams{
+					Owner:    owner,
+					Revision: revision,
+				},
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 
-	s.log.Info().Msgf("cleaned up %v deleted secrets", len(deletedSecrets))
+	return purgedSecrets, err
 }
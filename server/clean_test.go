@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -14,18 +15,66 @@ import (
 func TestClean(t *testing.T) {
 	// Create mock storage
 	controller := gomock.NewController(t)
-	mockStorage := mock.NewMockQuerier(controller)
+	mockStorage := mock.NewMockStore(controller)
+	allowTx(mockStorage)
 
 	mockStorage.EXPECT().
-		CleanSecrets(
+		PurgeSecrets(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(2).
+		Return(
+			[]db.Secret{
+				{Owner: testUsername, Revision: 3},
+				{Owner: testUsername, Revision: 7},
+				{Owner: testUsername2, Revision: 5},
+			},
+			nil,
+		)
+
+	mockStorage.EXPECT().
+		SetPurgedRevision(
+			gomock.Any(),
+			db.SetPurgedRevisionParams{Owner: testUsername, Revision: 7},
+		).
+		Times(2).
+		Return(nil)
+
+	mockStorage.EXPECT().
+		SetPurgedRevision(
+			gomock.Any(),
+			db.SetPurgedRevisionParams{Owner: testUsername2, Revision: 5},
+		).
+		Times(2).
+		Return(nil)
+
+	mockStorage.EXPECT().
+		PruneSecretVersions(
+			gomock.Any(),
+			int32(10),
+		).
+		Times(2).
+		Return(int64(0), nil)
+
+	mockStorage.EXPECT().
+		CleanRefreshTokens(
 			gomock.Any(),
 		).
 		Times(2).
-		Return([]db.Secret{}, nil)
+		Return(int64(0), nil)
+
+	mockStorage.EXPECT().
+		CleanSessions(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(2).
+		Return(int64(0), nil)
 
 	// Create server
 	testServer := &Server{
-		config:  Config{Clean: time.Second},
+		config:  Config{Clean: time.Second, Tombstones: time.Hour, Versions: 10},
 		storage: mockStorage,
 	}
 
@@ -34,3 +83,50 @@ func TestClean(t *testing.T) {
 
 	testServer.cleanJob(ctx)
 }
+
+func TestCleanFailure(t *testing.T) {
+	// Create mock storage
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockStore(controller)
+	allowTx(mockStorage)
+
+	mockStorage.EXPECT().
+		PurgeSecrets(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(1).
+		Return(nil, sql.ErrConnDone)
+
+	mockStorage.EXPECT().
+		PruneSecretVersions(
+			gomock.Any(),
+			int32(10),
+		).
+		Times(1).
+		Return(int64(0), sql.ErrConnDone)
+
+	// Failed secrets cleanup doesn't skip the tokens and sessions
+	mockStorage.EXPECT().
+		CleanRefreshTokens(
+			gomock.Any(),
+		).
+		Times(1).
+		Return(int64(0), nil)
+
+	mockStorage.EXPECT().
+		CleanSessions(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(1).
+		Return(int64(0), nil)
+
+	// Create server
+	testServer := &Server{
+		config:  Config{Tombstones: time.Hour, Versions: 10},
+		storage: mockStorage,
+	}
+
+	testServer.clean(context.Background())
+}
@@ -2,15 +2,120 @@ package server
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"net"
+	"time"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"gophkeeper/db/db"
+	"gophkeeper/pb"
+	"gophkeeper/token"
+	"gophkeeper/totp"
 )
 
+type contextKey int
+
+// payloadKey is the context key of the token payload verified by checkAuth.
+const payloadKey contextKey = iota
+
+// sessionTouchInterval limits how often session last seen time is updated.
+const sessionTouchInterval = time.Minute
+
+// publicMethods could be called without authorization token.
+var publicMethods = map[string]bool{
+	"/gophkeeper.GophKeeper/Ping":         true,
+	"/gophkeeper.GophKeeper/Register":     true,
+	"/gophkeeper.GophKeeper/Login":        true,
+	"/gophkeeper.GophKeeper/RefreshToken": true,
+	"/gophkeeper.GophKeeper/GetTokenKeys": true,
+}
+
+// limitLogin throttles Login and Register attempts per username and peer address
+// and locks them out after repeated failures.
+func (s *Server) limitLogin(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	keys := []limiterKey{{id: "address:" + peerHost(ctx), limit: addressLimit}}
+	var usernameKeys []limiterKey
+
+	switch info.FullMethod {
+	case "/gophkeeper.GophKeeper/Login":
+		if user, ok := req.(*pb.User); ok {
+			usernameKeys = append(usernameKeys, limiterKey{id: "username:" + user.Name, limit: usernameLimit})
+			keys = append(keys, usernameKeys...)
+		}
+	case "/gophkeeper.GophKeeper/Register":
+	default:
+		return handler(ctx, req)
+	}
+
+	wait := s.limiter.allow(keys...)
+	if wait > 0 {
+		s.log.Warn().Msgf("%s attempt from '%s' throttled for %s", info.FullMethod, peerHost(ctx), wait)
+		return nil, retryError(wait)
+	}
+
+	resp, err := handler(ctx, req)
+	if err == nil {
+		// Login to an own account doesn't clear the failures of the address
+		s.limiter.succeed(usernameKeys...)
+	} else if isLoginFailure(err) {
+		s.limiter.fail(keys...)
+	}
+
+	return resp, err
+}
+
+// retryError returns ResourceExhausted status with the time to wait before the next attempt.
+func retryError(wait time.Duration) error {
+	retryInfo := &errdetails.RetryInfo{RetryDelay: durationpb.New(wait)}
+	statusExhausted := status.New(codes.ResourceExhausted, "too many attempts, try again later")
+
+	statusDetails, err := statusExhausted.WithDetails(retryInfo)
+	if err != nil {
+		return statusExhausted.Err()
+	}
+
+	return statusDetails.Err()
+}
+
+// isLoginFailure reports whether the error is a guessing attempt:
+// wrong credentials or second factor code, or an existing username on register.
+func isLoginFailure(err error) bool {
+	e, _ := status.FromError(err)
+
+	switch e.Code() {
+	case codes.Unauthenticated:
+		for _, detail := range e.Details() {
+			if errorInfo, ok := detail.(*errdetails.ErrorInfo); ok && errorInfo.Reason == totp.SecondFactorRequired {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+// peerHost returns the request peer address without the port.
+func peerHost(ctx context.Context) string {
+	address := peerAddress(ctx)
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+
+	return host
+}
+
 func (s *Server) checkAuth(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-	if info.FullMethod == "/gophkeeper.GophKeeper/Register" || info.FullMethod == "/gophkeeper.GophKeeper/Login" || info.FullMethod == "/gophkeeper.GophKeeper/Ping" {
+	if publicMethods[info.FullMethod] {
 		return handler(ctx, req)
 	}
 
@@ -27,11 +132,69 @@ func (s *Server) checkAuth(ctx context.Context, req interface{}, info *grpc.Unar
 	}
 
 	token := authHeader[0]
-	_, err := s.tm.VerifyToken(token)
+	payload, err := s.tm.VerifyToken(token)
 	if err != nil {
 		s.log.Error().Msgf("rpc failed due to %s", err.Error())
 		return nil, status.Errorf(codes.Unauthenticated, err.Error())
 	}
 
-	return handler(ctx, req)
+	err = s.checkSession(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(context.WithValue(ctx, payloadKey, payload), req)
+}
+
+// checkSession rejects tokens of revoked sessions and keeps track of session activity.
+func (s *Server) checkSession(ctx context.Context, payload *token.Payload) error {
+	session, err := s.storage.GetSession(ctx, payload.SessionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.log.Error().Msgf("rpc failed due to user '%s' session is not found", payload.Username)
+			return status.Errorf(codes.Unauthenticated, "session is revoked")
+		}
+		s.log.Error().Err(err).Msgf("failed to get user '%s' session", payload.Username)
+		return status.Errorf(codes.Internal, "failed to get session")
+	}
+
+	if session.Revoked || session.Username != payload.Username {
+		s.log.Error().Msgf("rpc failed due to user '%s' session is revoked", payload.Username)
+		return status.Errorf(codes.Unauthenticated, "session is revoked")
+	}
+
+	if time.Since(session.LastSeen) > sessionTouchInterval {
+		err = s.storage.TouchSession(
+			ctx,
+			db.TouchSessionParams{
+				ID: session.ID,
+				Ip: peerAddress(ctx),
+			},
+		)
+		if err != nil {
+			s.log.Error().Err(err).Msgf("failed to update user '%s' session last seen time", payload.Username)
+		}
+	}
+
+	return nil
+}
+
+// payloadFromContext returns the token payload checkAuth verified for the request.
+func payloadFromContext(ctx context.Context) (*token.Payload, error) {
+	payload, ok := ctx.Value(payloadKey).(*token.Payload)
+	if !ok || payload.Username == "" {
+		return nil, status.Errorf(codes.Unauthenticated, "request is not authenticated")
+	}
+
+	return payload, nil
+}
+
+// usernameFromContext returns the username checkAuth verified for the request.
+func usernameFromContext(ctx context.Context) (string, error) {
+	payload, err := payloadFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return payload.Username, nil
 }
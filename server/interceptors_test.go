@@ -2,21 +2,23 @@ package server
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
+	"gophkeeper/crypto"
 	"gophkeeper/db/db"
 	"gophkeeper/db/mock"
 	"gophkeeper/pb"
 	"gophkeeper/random"
-	"gophkeeper/token"
 )
 
 var testUsername3 = random.RandomOwner()
@@ -24,7 +26,7 @@ var testUsername3 = random.RandomOwner()
 func TestCheckAuth(t *testing.T) {
 	// Create mock storage
 	controller := gomock.NewController(t)
-	mockStorage := mock.NewMockQuerier(controller)
+	mockStorage := mock.NewMockStore(controller)
 
 	mockStorage.EXPECT().
 		GetSecretsByUser(
@@ -34,11 +36,34 @@ func TestCheckAuth(t *testing.T) {
 		Times(1).
 		Return([]db.Secret{}, nil)
 
+	mockStorage.EXPECT().
+		GetSession(
+			gomock.Any(),
+			testSessionID,
+		).
+		Times(1).
+		Return(
+			db.Session{
+				ID:       testSessionID,
+				Username: testUsername3,
+				LastSeen: time.Now().Add(-time.Hour),
+			},
+			nil,
+		)
+
+	mockStorage.EXPECT().
+		TouchSession(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(1).
+		Return(nil)
+
 	// Create server
 	testServer := &Server{
 		config:  Config{},
 		storage: mockStorage,
-		tm:      token.NewPasetoMaker(),
+		tm:      newTestMaker(t),
 	}
 
 	// Run test gRPC server
@@ -46,8 +71,7 @@ func TestCheckAuth(t *testing.T) {
 	defer closer()
 
 	// Generate token
-	tm := token.NewPasetoMaker()
-	token, err := tm.CreateToken(testUsername3, time.Hour)
+	token, err := testServer.tm.CreateToken(testUsername3, testSessionID, time.Hour)
 	require.NoError(t, err)
 
 	// Add token to metadata
@@ -63,7 +87,7 @@ func TestCheckAuth(t *testing.T) {
 func TestCheckAuthMissingToken(t *testing.T) {
 	// Create mock storage
 	controller := gomock.NewController(t)
-	mockStorage := mock.NewMockQuerier(controller)
+	mockStorage := mock.NewMockStore(controller)
 
 	mockStorage.EXPECT().
 		GetSecretsByUser(
@@ -77,7 +101,7 @@ func TestCheckAuthMissingToken(t *testing.T) {
 	testServer := &Server{
 		config:  Config{},
 		storage: mockStorage,
-		tm:      token.NewPasetoMaker(),
+		tm:      newTestMaker(t),
 	}
 
 	// Run test gRPC server
@@ -102,3 +126,146 @@ func TestCheckAuthMissingToken(t *testing.T) {
 	require.Equal(t, codes.Unauthenticated, e.Code())
 	require.Equal(t, e.Message(), "token is invalid")
 }
+
+func TestCheckAuthRevokedSession(t *testing.T) {
+	// Create mock storage
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockStore(controller)
+
+	mockStorage.EXPECT().
+		GetSecretsByUser(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(0)
+
+	mockStorage.EXPECT().
+		GetSession(
+			gomock.Any(),
+			testSessionID,
+		).
+		Times(1).
+		Return(
+			db.Session{
+				ID:       testSessionID,
+				Username: testUsername3,
+				LastSeen: time.Now(),
+				Revoked:  true,
+			},
+			nil,
+		)
+
+	// Create server
+	testServer := &Server{
+		config:  Config{},
+		storage: mockStorage,
+		tm:      newTestMaker(t),
+	}
+
+	// Run test gRPC server
+	client, closer := runTestServer(testServer, grpc.UnaryInterceptor(testServer.checkAuth))
+	defer closer()
+
+	// Generate token of the revoked session
+	token, err := testServer.tm.CreateToken(testUsername3, testSessionID, time.Hour)
+	require.NoError(t, err)
+
+	md := metadata.Pairs("token", token)
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	// Run rpc with valid token of the revoked session
+	_, err = client.GetSecrets(ctx, &pb.SecretsRequest{Owner: testUsername3})
+	require.Error(t, err)
+	e, _ := status.FromError(err)
+	require.Equal(t, codes.Unauthenticated, e.Code())
+	require.Equal(t, e.Message(), "session is revoked")
+}
+
+func TestLimitLogin(t *testing.T) {
+	// Create mock storage
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockStore(controller)
+	allowAuditEvents(mockStorage)
+
+	mockStorage.EXPECT().
+		GetUser(
+			gomock.Any(),
+			testUsername3,
+		).
+		Times(usernameLimit.maxFailures).
+		Return(db.User{}, sql.ErrNoRows)
+
+	// Create server
+	testServer := &Server{
+		config:  Config{},
+		storage: mockStorage,
+		tm:      newTestMaker(t),
+		limiter: newLoginLimiter(),
+		hasher:  crypto.DefaultPasswordHasher,
+	}
+
+	// Run test gRPC server
+	client, closer := runTestServer(testServer, grpc.ChainUnaryInterceptor(testServer.limitLogin, testServer.checkAuth))
+	defer closer()
+
+	// Failures don't reveal the user does not exist
+	for i := 0; i < usernameLimit.maxFailures; i++ {
+		_, err := client.Login(context.Background(), &pb.User{Name: testUsername3, Password: testPassword})
+		require.Error(t, err)
+
+		e, _ := status.FromError(err)
+		require.Equal(t, codes.Unauthenticated, e.Code())
+		require.Equal(t, "invalid username or password", e.Message())
+	}
+
+	// User is locked out
+	_, err := client.Login(context.Background(), &pb.User{Name: testUsername3, Password: testPassword})
+	require.Error(t, err)
+
+	e, _ := status.FromError(err)
+	require.Equal(t, codes.ResourceExhausted, e.Code())
+	require.Len(t, e.Details(), 1)
+
+	retryInfo, ok := e.Details()[0].(*errdetails.RetryInfo)
+	require.True(t, ok)
+	require.Greater(t, retryInfo.RetryDelay.AsDuration(), time.Duration(0))
+}
+
+func TestLimitLoginSuccess(t *testing.T) {
+	now := time.Now()
+
+	testServer := &Server{limiter: newLoginLimiter()}
+	testServer.limiter.now = func() time.Time { return now }
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/gophkeeper.GophKeeper/Login"}
+
+	login := func(name string, err error) error {
+		// Attempts are spread so the address bucket never runs out
+		now = now.Add(addressLimit.period)
+
+		_, loginErr := testServer.limitLogin(
+			context.Background(),
+			&pb.User{Name: name},
+			info,
+			func(context.Context, interface{}) (interface{}, error) { return nil, err },
+		)
+		return loginErr
+	}
+
+	for i := 0; i < addressLimit.maxFailures-1; i++ {
+		err := login(random.RandomOwner(), errInvalidCredentials)
+		require.ErrorIs(t, err, errInvalidCredentials)
+	}
+
+	// Login to an own account keeps the address failures
+	require.NoError(t, login(testUsername3, nil))
+
+	err := login(random.RandomOwner(), errInvalidCredentials)
+	require.ErrorIs(t, err, errInvalidCredentials)
+
+	err = login(testUsername3, nil)
+	require.Error(t, err)
+
+	e, _ := status.FromError(err)
+	require.Equal(t, codes.ResourceExhausted, e.Code())
+}
@@ -0,0 +1,166 @@
+package server
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	baseLockout     = 30 * time.Second
+	maxLockout      = time.Hour
+	limiterIdleTime = 2 * maxLockout // entries idle for longer are forgotten
+)
+
+// rateLimit is a token bucket limit of login attempts
+// with the lockout after too many consecutive failures.
+type rateLimit struct {
+	burst       float64
+	period      time.Duration // time to regain one attempt
+	maxFailures int           // failures before the lockout
+}
+
+var (
+	usernameLimit = rateLimit{burst: 5, period: 12 * time.Second, maxFailures: 5}
+	addressLimit  = rateLimit{burst: 20, period: 3 * time.Second, maxFailures: 20}
+)
+
+// limiterKey is a subject to be limited (e.g. a username or a peer address) with its limit.
+type limiterKey struct {
+	id    string
+	limit rateLimit
+}
+
+type attempts struct {
+	tokens      float64
+	updated     time.Time
+	failures    int
+	lockedUntil time.Time
+}
+
+// loginLimiter limits login attempts and locks out subjects
+// with exponential backoff after repeated failures.
+type loginLimiter struct {
+	mu        sync.Mutex
+	entries   map[string]*attempts
+	now       func() time.Time
+	lastPrune time.Time
+}
+
+func newLoginLimiter() *loginLimiter {
+	return &loginLimiter{
+		entries: map[string]*attempts{},
+		now:     time.Now,
+	}
+}
+
+func (l *loginLimiter) get(key limiterKey, now time.Time) *attempts {
+	entry, ok := l.entries[key.id]
+	if !ok {
+		entry = &attempts{tokens: key.limit.burst, updated: now}
+		l.entries[key.id] = entry
+	}
+
+	// Refill the bucket
+	entry.tokens += float64(now.Sub(entry.updated)) / float64(key.limit.period)
+	if entry.tokens > key.limit.burst {
+		entry.tokens = key.limit.burst
+	}
+	entry.updated = now
+
+	return entry
+}
+
+// allow takes an attempt from every key. If any of them is limited or locked
+// nothing is taken and the time to wait before the next attempt is returned.
+func (l *loginLimiter) allow(keys ...limiterKey) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.prune(now)
+
+	var wait time.Duration
+	for _, key := range keys {
+		entry := l.get(key, now)
+
+		if entry.lockedUntil.After(now) {
+			wait = maxDuration(wait, entry.lockedUntil.Sub(now))
+		}
+
+		if entry.tokens < 1 {
+			wait = maxDuration(wait, time.Duration((1-entry.tokens)*float64(key.limit.period)))
+		}
+	}
+
+	if wait > 0 {
+		return wait
+	}
+
+	for _, key := range keys {
+		l.entries[key.id].tokens--
+	}
+
+	return 0
+}
+
+// fail records the failed attempt and locks the keys out
+// for twice as long after every failure above the limit.
+func (l *loginLimiter) fail(keys ...limiterKey) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	for _, key := range keys {
+		entry := l.get(key, now)
+		entry.failures++
+
+		excess := entry.failures - key.limit.maxFailures
+		if excess < 0 {
+			continue
+		}
+
+		lockout := maxLockout
+		if excess < 16 {
+			lockout = baseLockout << excess
+		}
+		if lockout > maxLockout {
+			lockout = maxLockout
+		}
+
+		entry.lockedUntil = now.Add(lockout)
+	}
+}
+
+// succeed resets failures of the keys.
+func (l *loginLimiter) succeed(keys ...limiterKey) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, key := range keys {
+		if entry, ok := l.entries[key.id]; ok {
+			entry.failures = 0
+			entry.lockedUntil = time.Time{}
+		}
+	}
+}
+
+// prune forgets idle entries so the limiter doesn't grow forever.
+func (l *loginLimiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < time.Minute {
+		return
+	}
+	l.lastPrune = now
+
+	for id, entry := range l.entries {
+		if now.Sub(entry.updated) > limiterIdleTime && entry.lockedUntil.Before(now) {
+			delete(l.entries, id)
+		}
+	}
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLimiter() (*loginLimiter, *time.Time) {
+	now := time.Now()
+
+	limiter := newLoginLimiter()
+	limiter.now = func() time.Time { return now }
+
+	return limiter, &now
+}
+
+func TestLimiterRate(t *testing.T) {
+	limiter, now := newTestLimiter()
+	key := limiterKey{id: "username:bob", limit: usernameLimit}
+
+	for i := 0; i < int(usernameLimit.burst); i++ {
+		require.Zero(t, limiter.allow(key))
+	}
+
+	wait := limiter.allow(key)
+	require.Equal(t, usernameLimit.period, wait)
+
+	// Attempt is regained after the period
+	*now = now.Add(usernameLimit.period)
+	require.Zero(t, limiter.allow(key))
+
+	// Other keys are not affected
+	require.Zero(t, limiter.allow(limiterKey{id: "username:alice", limit: usernameLimit}))
+}
+
+func TestLimiterLockout(t *testing.T) {
+	limiter, now := newTestLimiter()
+	key := limiterKey{id: "username:bob", limit: usernameLimit}
+
+	for i := 0; i < usernameLimit.maxFailures-1; i++ {
+		limiter.fail(key)
+	}
+
+	// Refill the bucket to check the lockout only
+	*now = now.Add(time.Hour)
+	require.Zero(t, limiter.allow(key))
+
+	limiter.fail(key)
+	require.Equal(t, baseLockout, limiter.allow(key))
+
+	// Every next failure doubles the lockout
+	*now = now.Add(baseLockout)
+	limiter.fail(key)
+	require.Equal(t, 2*baseLockout, limiter.allow(key))
+
+	*now = now.Add(2 * baseLockout)
+	limiter.fail(key)
+	require.Equal(t, 4*baseLockout, limiter.allow(key))
+
+	// Lockout is capped
+	for i := 0; i < 100; i++ {
+		limiter.fail(key)
+	}
+	require.Equal(t, maxLockout, limiter.allow(key))
+
+	// Success resets the failures
+	limiter.succeed(key)
+	*now = now.Add(time.Hour)
+	require.Zero(t, limiter.allow(key))
+}
+
+func TestLimiterPrune(t *testing.T) {
+	limiter, now := newTestLimiter()
+
+	require.Zero(t, limiter.allow(limiterKey{id: "username:bob", limit: usernameLimit}))
+	require.Len(t, limiter.entries, 1)
+
+	*now = now.Add(limiterIdleTime + time.Minute)
+	require.Zero(t, limiter.allow(limiterKey{id: "username:alice", limit: usernameLimit}))
+	require.Len(t, limiter.entries, 1)
+}
@@ -4,12 +4,21 @@ import (
 	"context"
 	"log"
 	"net"
+	"testing"
+	"time"
 
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/test/bufconn"
 
+	"gophkeeper/db/db"
+	"gophkeeper/db/mock"
 	"gophkeeper/pb"
+	"gophkeeper/random"
+	"gophkeeper/token"
 )
 
 func runTestServer(server *Server, opts ...grpc.ServerOption) (pb.GophKeeperClient, func()) {
@@ -46,3 +55,80 @@ func runTestServer(server *Server, opts ...grpc.ServerOption) (pb.GophKeeperClie
 
 	return client, closer
 }
+
+var testSessionID = random.RandomString(32)
+
+// newAuthContext returns a context with the user token of an active session.
+func newAuthContext(t *testing.T, tm token.PasetoMaker, storage *mock.MockStore, username string) context.Context {
+	token, err := tm.CreateToken(username, testSessionID, time.Hour)
+	require.NoError(t, err)
+
+	storage.EXPECT().
+		GetSession(
+			gomock.Any(),
+			testSessionID,
+		).
+		AnyTimes().
+		Return(
+			db.Session{
+				ID:       testSessionID,
+				Username: username,
+				LastSeen: time.Now(),
+			},
+			nil,
+		)
+
+	md := metadata.Pairs("token", token)
+	return metadata.NewOutgoingContext(context.Background(), md)
+}
+
+// allowAuditEvents lets the server record any audit events.
+func allowAuditEvents(storage *mock.MockStore) {
+	allowTx(storage)
+
+	storage.EXPECT().
+		LockAuditHead(
+			gomock.Any(),
+		).
+		AnyTimes().
+		Return(db.AuditHead{}, nil)
+
+	storage.EXPECT().
+		CreateAuditEvent(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		AnyTimes().
+		Return(db.AuditEvent{}, nil)
+
+	storage.EXPECT().
+		UpdateAuditHead(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		AnyTimes().
+		Return(nil)
+}
+
+// allowTx runs transactions with the mock storage itself.
+func allowTx(storage *mock.MockStore) {
+	storage.EXPECT().
+		WithTx(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		AnyTimes().
+		DoAndReturn(func(_ context.Context, fn func(db.Querier) error) error {
+			return fn(storage)
+		})
+}
+
+func newTestMaker(t *testing.T) token.PasetoMaker {
+	key, err := token.GenerateKey()
+	require.NoError(t, err)
+
+	keyring, err := token.NewKeyring(key)
+	require.NoError(t, err)
+
+	return token.NewPasetoMaker(keyring)
+}
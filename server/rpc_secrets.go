@@ -4,147 +4,419 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
-	"google.golang.org/protobuf/types/known/emptypb"
 
+	"gophkeeper/audit"
 	"gophkeeper/converter"
 	"gophkeeper/db/db"
 	"gophkeeper/pb"
+	"gophkeeper/server/validation"
 )
 
-func (s *Server) SetSecrets(ctx context.Context, in *pb.Secrets) (*emptypb.Empty, error) {
-	s.log.Info().Msgf("got %v secrets for sync", len(in.Secrets))
+// maxChanges is the number of secret changes sent at once.
+const maxChanges = 500
 
-	for _, pbSecret := range in.Secrets {
-		remoteSecret := converter.PBSecretToDBSecret(pbSecret)
+// sealedKind is the kind of the end-to-end encrypted secrets.
+// Their names are opaque IDs set by the clients.
+const sealedKind int32 = -1
 
-		localSecret, err := s.storage.GetSecret(
-			ctx,
-			db.GetSecretParams{
-				Owner: remoteSecret.Owner,
-				Kind:  remoteSecret.Kind,
-				Name:  remoteSecret.Name,
-			},
-		)
-		if err != nil && !errors.Is(err, sql.ErrNoRows) {
-			s.log.Error().Err(err).Msgf(
-				"failed to get user '%s' secret '%s' from local db",
-				remoteSecret.Owner,
-				remoteSecret.Name,
+var errInvalidSealedSecret = errors.New("plaintext secret must be replaced with its own sealed copy")
+
+// outcomeEvents are the audit events of the secret changes.
+var outcomeEvents = map[pb.SecretResult_Outcome]string{
+	pb.SecretResult_CREATED: audit.EventSecretCreated,
+	pb.SecretResult_UPDATED: audit.EventSecretUpdated,
+	pb.SecretResult_DELETED: audit.EventSecretDeleted,
+}
+
+// SetSecrets applies the batch of the user secrets in a single transaction
+// and reports the outcome of every secret. Invalid secrets are reported as failed,
+// secrets edited from an outdated revision as conflicts with the current server copy.
+// A db error rolls back the whole batch.
+func (s *Server) SetSecrets(ctx context.Context, in *pb.Secrets) (*pb.SyncResult, error) {
+	username, err := usernameFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pbSecret := range in.Secrets {
+		if pbSecret.Owner != username {
+			s.log.Error().Msgf(
+				"user '%s' tried to sync secret '%s' of user '%s'",
+				username,
+				pbSecret.Name,
+				pbSecret.Owner,
 			)
-			continue
+			return nil, status.Errorf(codes.PermissionDenied, "secret owner differs from token user name")
 		}
-		if errors.Is(err, sql.ErrNoRows) && !remoteSecret.Deleted {
-			_, err := s.storage.CreateSecret(
-				ctx,
-				db.CreateSecretParams{
-					Owner:    remoteSecret.Owner,
-					Kind:     remoteSecret.Kind,
-					Name:     remoteSecret.Name,
-					Value:    remoteSecret.Value,
-					Created:  remoteSecret.Created,
-					Modified: remoteSecret.Modified,
-				},
-			)
+	}
+
+	s.log.Info().Msgf("got %v secrets for sync", len(in.Secrets))
+
+	var results []*pb.SecretResult
+	var changes []*pb.SecretResult
+
+	err = s.storage.WithTx(ctx, func(q db.Querier) error {
+		results = make([]*pb.SecretResult, 0, len(in.Secrets))
+		changes = nil
+
+		err := q.LockUserSecrets(ctx, username)
+		if err != nil {
+			return fmt.Errorf("failed to lock user '%s' secrets: %w", username, err)
+		}
+
+		for _, pbSecret := range in.Secrets {
+			result := &pb.SecretResult{Kind: pbSecret.Kind, Name: pbSecret.Name}
+			results = append(results, result)
+
+			err := validateSecret(pbSecret)
 			if err != nil {
-				s.log.Error().Err(err).Msgf(
-					"failed to sync new user '%s' secret '%s'",
-					remoteSecret.Owner,
-					remoteSecret.Name,
-				)
+				result.Outcome = pb.SecretResult_FAILED
+				result.Reason = err.Error()
 				continue
 			}
 
-			s.log.Info().Msgf(
-				"successfully synced new user '%s' secret '%s'",
-				remoteSecret.Owner,
-				remoteSecret.Name,
-			)
-			continue
+			changed, err := applySecret(ctx, q, converter.PBSecretToDBSecret(pbSecret), result)
+			if err != nil {
+				return fmt.Errorf("failed to sync user '%s' secret '%s': %w", username, pbSecret.Name, err)
+			}
+			if changed {
+				changes = append(changes, result)
+			}
 		}
-		if errors.Is(err, sql.ErrNoRows) && remoteSecret.Deleted {
+
+		return nil
+	})
+	if err != nil {
+		s.log.Error().Err(err).Msgf("failed to sync user '%s' secrets", username)
+		return nil, status.Errorf(codes.Internal, "failed to sync secrets")
+	}
+
+	// Only committed changes are recorded
+	for _, change := range changes {
+		s.recordEvent(ctx, outcomeEvents[change.Outcome], username, change.Kind, change.Name)
+	}
+
+	for _, result := range results {
+		if result.Outcome == pb.SecretResult_FAILED {
+			s.log.Warn().Msgf("user '%s' secret '%s' rejected: %s", username, result.Name, result.Reason)
 			continue
 		}
 
-		if remoteSecret.Deleted {
-			err := s.storage.MarkSecretDeleted(
+		s.log.Info().Msgf("user '%s' secret '%s' %s", username, result.Name, strings.ToLower(result.Outcome.String()))
+	}
+
+	s.log.Info().Msgf("processed %v secrets", len(in.Secrets))
+
+	return &pb.SyncResult{Results: results}, nil
+}
+
+// SealSecrets creates the end-to-end encrypted copies of the secrets synced by their
+// plaintext names and removes the plaintext ones with their versions in a single transaction.
+// Plaintext secrets leave no tombstones with their names and are only removed once
+// their sealed copies are created, the other devices replace their local copies
+// with the sealed ones they pull.
+func (s *Server) SealSecrets(ctx context.Context, in *pb.SealSecretsRequest) (*pb.SyncResult, error) {
+	username, err := usernameFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, secret := range in.Secrets {
+		violations = append(violations, validateSealedSecret(secret, username)...)
+	}
+	if violations != nil {
+		return nil, validation.InvalidArgumentError(violations)
+	}
+
+	var results []*pb.SecretResult
+	var forgotten int64
+
+	err = s.storage.WithTx(ctx, func(q db.Querier) error {
+		results = make([]*pb.SecretResult, 0, len(in.Secrets))
+		forgotten = 0
+
+		err := q.LockUserSecrets(ctx, username)
+		if err != nil {
+			return fmt.Errorf("failed to lock user '%s' secrets: %w", username, err)
+		}
+
+		for _, secret := range in.Secrets {
+			result := &pb.SecretResult{Kind: secret.Sealed.Kind, Name: secret.Sealed.Name}
+			results = append(results, result)
+
+			_, err := applySecret(ctx, q, converter.PBSecretToDBSecret(secret.Sealed), result)
+			if err != nil {
+				return fmt.Errorf("failed to seal user '%s' secret '%s': %w", username, secret.Plain.Name, err)
+			}
+			if result.Outcome != pb.SecretResult_CREATED {
+				continue
+			}
+
+			deleted, err := q.ForgetSecret(
 				ctx,
-				db.MarkSecretDeletedParams{
-					Owner: remoteSecret.Owner,
-					Kind:  remoteSecret.Kind,
-					Name:  remoteSecret.Name,
+				db.ForgetSecretParams{
+					Owner: username,
+					Kind:  secret.Plain.Kind,
+					Name:  secret.Plain.Name,
 				},
 			)
 			if err != nil {
-				s.log.Error().Err(err).Msgf(
-					"failed to mark user '%s' secret '%s' as deleted",
-					remoteSecret.Owner,
-					remoteSecret.Name,
-				)
-				continue
+				return fmt.Errorf("failed to forget user '%s' secret '%s': %w", username, secret.Plain.Name, err)
 			}
 
-			s.log.Info().Msgf(
-				"successfully marked user '%s' secret '%s' for deletion",
-				remoteSecret.Owner,
-				remoteSecret.Name,
-			)
-			continue
+			forgotten += deleted
+		}
+
+		return nil
+	})
+	if err != nil {
+		s.log.Error().Err(err).Msgf("failed to seal user '%s' secrets", username)
+		return nil, status.Errorf(codes.Internal, "failed to seal secrets")
+	}
+
+	for _, result := range results {
+		if result.Outcome == pb.SecretResult_CREATED {
+			s.recordEvent(ctx, audit.EventSecretCreated, username, result.Kind, result.Name)
 		}
+	}
+	if forgotten > 0 {
+		s.recordEvent(ctx, audit.EventSecretsForgotten, username, 0, "")
+	}
+
+	s.log.Info().Msgf("user '%s' sealed %v secrets, %v plaintext ones forgotten", username, len(results), forgotten)
+
+	return &pb.SyncResult{Results: results}, nil
+}
 
-		if remoteSecret.Modified.After(localSecret.Modified) {
-			_, err := s.storage.UpdateSecret(
+// validateSealedSecret checks the sealed copy of the plaintext secret can be created.
+func validateSealedSecret(secret *pb.SealedSecret, username string) (violations []*errdetails.BadRequest_FieldViolation) {
+	if secret.Plain == nil || secret.Sealed == nil {
+		return append(violations, validation.FieldViolation("secrets", errInvalidSealedSecret))
+	}
+
+	if err := validation.ValidateSecretName(secret.Plain.Name); err != nil {
+		violations = append(violations, validation.FieldViolation("plain", err))
+	}
+	if secret.Plain.Kind == sealedKind {
+		violations = append(violations, validation.FieldViolation("plain", errInvalidSealedSecret))
+	}
+
+	if err := validateSecret(secret.Sealed); err != nil {
+		violations = append(violations, validation.FieldViolation("sealed", err))
+	}
+	if secret.Sealed.Kind != sealedKind || secret.Sealed.Owner != username || secret.Sealed.Deleted {
+		violations = append(violations, validation.FieldViolation("sealed", errInvalidSealedSecret))
+	}
+
+	return violations
+}
+
+// validateSecret checks the secret can be synced.
+func validateSecret(secret *pb.Secret) error {
+	if err := validation.ValidateSecretName(secret.Name); err != nil {
+		return err
+	}
+	if !secret.Modified.IsValid() {
+		return errors.New("invalid modification time")
+	}
+
+	return nil
+}
+
+// applySecret saves the secret if it is based on the current server revision
+// and sets the result outcome. It reports whether the stored secret was changed.
+func applySecret(ctx context.Context, q db.Querier, secret db.Secret, result *pb.SecretResult) (bool, error) {
+	updated, err := q.UpdateSecret(
+		ctx,
+		db.UpdateSecretParams{
+			Owner:        secret.Owner,
+			Kind:         secret.Kind,
+			Name:         secret.Name,
+			Value:        secret.Value,
+			Modified:     secret.Modified,
+			Deleted:      secret.Deleted,
+			BaseRevision: secret.Revision,
+		},
+	)
+	if err == nil {
+		result.Outcome = pb.SecretResult_UPDATED
+		if secret.Deleted {
+			result.Outcome = pb.SecretResult_DELETED
+		}
+		result.Revision = updated.Revision
+
+		// Deletion without the value purges the secret, its tombstone keeps nothing
+		if secret.Deleted && len(secret.Value) == 0 {
+			err = q.PurgeSecretVersions(
 				ctx,
-				db.UpdateSecretParams{
-					Owner:    remoteSecret.Owner,
-					Kind:     remoteSecret.Kind,
-					Name:     remoteSecret.Name,
-					Value:    remoteSecret.Value,
-					Created:  remoteSecret.Created,
-					Modified: remoteSecret.Modified,
+				db.PurgeSecretVersionsParams{
+					Owner: secret.Owner,
+					Kind:  secret.Kind,
+					Name:  secret.Name,
 				},
 			)
 			if err != nil {
-				s.log.Error().Err(err).Msgf(
-					"failed to update user '%s' secret '%s'",
-					remoteSecret.Owner,
-					remoteSecret.Name,
-				)
-				continue
+				return false, err
 			}
-
-			s.log.Info().Msgf(
-				"successfully updated user '%s' secret '%s'",
-				remoteSecret.Owner,
-				remoteSecret.Name,
-			)
 		}
+
+		return true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return false, err
 	}
 
-	s.log.Info().Msgf("processed %v secrets", len(in.Secrets))
+	// Stale write
+	current, err := q.GetSecret(
+		ctx,
+		db.GetSecretParams{
+			Owner: secret.Owner,
+			Kind:  secret.Kind,
+			Name:  secret.Name,
+		},
+	)
+	if err == nil {
+		result.Outcome = pb.SecretResult_CONFLICT
+		result.Current = converter.DBSecretToPBSecret(current)
+		return false, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return false, err
+	}
+
+	// Unknown secret is deleted as well
+	if secret.Deleted {
+		result.Outcome = pb.SecretResult_DELETED
+		return false, nil
+	}
+
+	created, err := q.CreateSecret(
+		ctx,
+		db.CreateSecretParams{
+			Owner:    secret.Owner,
+			Kind:     secret.Kind,
+			Name:     secret.Name,
+			Value:    secret.Value,
+			Created:  secret.Created,
+			Modified: secret.Modified,
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	result.Outcome = pb.SecretResult_CREATED
+	result.Revision = created.Revision
 
-	return &emptypb.Empty{}, nil
+	return true, nil
 }
 
 func (s *Server) GetSecrets(ctx context.Context, in *pb.SecretsRequest) (*pb.Secrets, error) {
-	s.log.Info().Msgf("user '%s' requested his secrets", in.Owner)
+	username, err := usernameFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	secrets, err := s.storage.GetSecretsByUser(ctx, in.Owner)
+	if in.Owner != username {
+		s.log.Error().Msgf("user '%s' tried to get secrets of user '%s'", username, in.Owner)
+		return nil, status.Errorf(codes.PermissionDenied, "secrets owner differs from token user name")
+	}
+
+	s.log.Info().Msgf("user '%s' requested his secrets", username)
+
+	secrets, err := s.storage.GetSecretsByUser(ctx, username)
 	if err != nil {
-		s.log.Error().Err(err).Msgf("failed to get user %s secrets", in.Owner)
+		s.log.Error().Err(err).Msgf("failed to get user %s secrets", username)
 		return nil, status.Error(codes.Internal, "failed to get secrets from db")
 	}
 
-	s.log.Info().Msgf("successfully got user '%s' secrets from db", in.Owner)
+	s.log.Info().Msgf("successfully got user '%s' secrets from db", username)
 
 	pbSecrets := []*pb.Secret{}
 	for _, secret := range secrets {
 		pbSecrets = append(pbSecrets, converter.DBSecretToPBSecret(secret))
 	}
 
-	s.log.Info().Msgf("successfully sent user '%s' secrets", in.Owner)
+	s.log.Info().Msgf("successfully sent user '%s' secrets", username)
 
 	return &pb.Secrets{Secrets: pbSecrets}, nil
 }
+
+// GetChanges returns the user secrets changed or deleted after the revision.
+// A client synced before the purged tombstones is told to resync instead.
+func (s *Server) GetChanges(ctx context.Context, in *pb.ChangesRequest) (*pb.Changes, error) {
+	username, err := usernameFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var secrets []db.Secret
+	var purgedRevision int64
+	err = s.storage.WithTx(ctx, func(q db.Querier) error {
+		// Changes in progress would get revisions lower than the ones already committed
+		err := q.LockUserSecretsShared(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		secrets, err = q.GetSecretChanges(
+			ctx,
+			db.GetSecretChangesParams{
+				Owner:    username,
+				Revision: in.SinceRevision,
+				Limit:    maxChanges + 1,
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		// Checked after the changes are read so tombstones purged in between
+		// cause a needless resync rather than a missed deletion
+		purgedRevision, err = q.GetPurgedRevision(ctx, username)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		s.log.Error().Err(err).Msgf("failed to get user '%s' secret changes", username)
+		return nil, status.Error(codes.Internal, "failed to get secret changes from db")
+	}
+
+	if !in.Resync && in.SinceRevision < purgedRevision {
+		s.log.Info().Msgf(
+			"user '%s' revision %v is older than the purged tombstones, resync required",
+			username,
+			in.SinceRevision,
+		)
+		return &pb.Changes{Secrets: []*pb.Secret{}, Revision: in.SinceRevision, Resync: true}, nil
+	}
+
+	changes := &pb.Changes{Secrets: []*pb.Secret{}, Revision: in.SinceRevision}
+	if len(secrets) > maxChanges {
+		secrets = secrets[:maxChanges]
+		changes.More = true
+	}
+
+	for _, secret := range secrets {
+		changes.Secrets = append(changes.Secrets, converter.DBSecretToPBSecret(secret))
+		changes.Revision = secret.Revision
+	}
+
+	s.log.Info().Msgf(
+		"sent user '%s' %v secret changes after revision %v",
+		username,
+		len(changes.Secrets),
+		in.SinceRevision,
+	)
+
+	return changes, nil
+}
@@ -8,6 +8,9 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"gophkeeper/db/db"
@@ -18,12 +21,13 @@ import (
 
 var (
 	testUsername2 = random.RandomOwner()
+	testUsername4 = random.RandomOwner()
 )
 
 func TestRPCGetSecrets(t *testing.T) {
 	// Create mock storage
 	controller := gomock.NewController(t)
-	mockStorage := mock.NewMockQuerier(controller)
+	mockStorage := mock.NewMockStore(controller)
 
 	mockStorage.EXPECT().
 		GetSecretsByUser(
@@ -37,13 +41,16 @@ func TestRPCGetSecrets(t *testing.T) {
 	testServer := &Server{
 		config:  Config{},
 		storage: mockStorage,
+		tm:      newTestMaker(t),
 	}
 
 	// Run test gRPC server
-	client, closer := runTestServer(testServer)
+	client, closer := runTestServer(testServer, grpc.UnaryInterceptor(testServer.checkAuth))
 	defer closer()
 
-	pbSecrets, err := client.GetSecrets(context.Background(), &pb.SecretsRequest{Owner: testUsername2})
+	ctx := newAuthContext(t, testServer.tm, mockStorage, testUsername2)
+
+	pbSecrets, err := client.GetSecrets(ctx, &pb.SecretsRequest{Owner: testUsername2})
 	require.NoError(t, err)
 	require.Equal(t, len(pbSecrets.Secrets), 1)
 }
@@ -51,94 +58,120 @@ func TestRPCGetSecrets(t *testing.T) {
 func TestRPCSetSecrets(t *testing.T) {
 	// Create mock storage
 	controller := gomock.NewController(t)
-	mockStorage := mock.NewMockQuerier(controller)
+	mockStorage := mock.NewMockStore(controller)
+	allowAuditEvents(mockStorage)
+	allowTx(mockStorage)
+
+	mockStorage.EXPECT().
+		LockUserSecrets(
+			gomock.Any(),
+			testUsername2,
+		).
+		Times(5).
+		Return(nil)
 
 	// Mock secret to create
+	mockStorage.EXPECT().
+		UpdateSecret(
+			gomock.Any(),
+			newSecretNameMatcher("testSecretToCreate"),
+		).
+		Times(1).
+		Return(db.Secret{}, sql.ErrNoRows)
+
 	mockStorage.EXPECT().
 		GetSecret(
 			gomock.Any(),
-			db.GetSecretParams{
-				Owner: testUsername2,
-				Kind:  0,
-				Name:  "testSecretToCreate",
-			},
+			newSecretNameMatcher("testSecretToCreate"),
 		).
 		Times(1).
-		Return(
-			db.Secret{},
-			sql.ErrNoRows,
-		)
+		Return(db.Secret{}, sql.ErrNoRows)
 
 	mockStorage.EXPECT().
 		CreateSecret(
 			gomock.Any(),
-			gomock.Any(),
+			newSecretNameMatcher("testSecretToCreate"),
 		).
 		Times(1).
-		Return(
-			db.Secret{},
-			nil,
-		)
+		Return(db.Secret{Revision: 1}, nil)
 
 	// Mock secret to delete
 	mockStorage.EXPECT().
-		GetSecret(
+		UpdateSecret(
 			gomock.Any(),
-			db.GetSecretParams{
-				Owner: testUsername2,
-				Kind:  0,
-				Name:  "testSecretToDelete",
+			db.UpdateSecretParams{
+				Owner:        testUsername2,
+				Kind:         0,
+				Name:         "testSecretToDelete",
+				Value:        []byte("deleted"),
+				Modified:     testModified,
+				Deleted:      true,
+				BaseRevision: 1,
 			},
 		).
 		Times(1).
-		Return(
-			db.Secret{
-				Owner: testUsername2,
-				Kind:  0,
-				Name:  "testSecretToDelete",
-			},
-			nil,
-		)
+		Return(db.Secret{Revision: 2, Deleted: true}, nil)
 
+	// Mock secret to purge
 	mockStorage.EXPECT().
-		MarkSecretDeleted(
-			gomock.Any(),
+		UpdateSecret(
 			gomock.Any(),
+			db.UpdateSecretParams{
+				Owner:        testUsername2,
+				Kind:         0,
+				Name:         "testSecretToPurge",
+				Modified:     testModified,
+				Deleted:      true,
+				BaseRevision: 6,
+			},
 		).
 		Times(1).
-		Return(
-			nil,
-		)
+		Return(db.Secret{Revision: 7, Deleted: true}, nil)
 
-	// Mock secret to update
 	mockStorage.EXPECT().
-		GetSecret(
+		PurgeSecretVersions(
 			gomock.Any(),
-			db.GetSecretParams{
+			db.PurgeSecretVersionsParams{
 				Owner: testUsername2,
 				Kind:  0,
-				Name:  "testSecretToUpdate",
+				Name:  "testSecretToPurge",
 			},
 		).
 		Times(1).
-		Return(
-			db.Secret{
-				Owner:    testUsername2,
-				Kind:     0,
-				Name:     "testSecretToUpdate",
-				Modified: time.Now().Add(-time.Minute),
-			},
-			nil,
-		)
+		Return(nil)
 
+	// Mock secret to update
 	mockStorage.EXPECT().
 		UpdateSecret(
 			gomock.Any(),
+			newSecretNameMatcher("testSecretToUpdate"),
+		).
+		Times(1).
+		Return(db.Secret{Revision: 3}, nil)
+
+	// Mock secret edited from an outdated revision
+	mockStorage.EXPECT().
+		UpdateSecret(
 			gomock.Any(),
+			newSecretNameMatcher("testStaleSecret"),
+		).
+		Times(1).
+		Return(db.Secret{}, sql.ErrNoRows)
+
+	mockStorage.EXPECT().
+		GetSecret(
+			gomock.Any(),
+			newSecretNameMatcher("testStaleSecret"),
 		).
 		Times(1).
 		Return(
-			db.Secret{},
+			db.Secret{
+				Owner:    testUsername2,
+				Kind:     0,
+				Name:     "testStaleSecret",
+				Value:    []byte("theirs"),
+				Revision: 5,
+			},
 			nil,
 		)
 
@@ -146,46 +179,77 @@ func TestRPCSetSecrets(t *testing.T) {
 	testServer := &Server{
 		config:  Config{},
 		storage: mockStorage,
+		tm:      newTestMaker(t),
 	}
 
 	// Run test gRPC server
-	client, closer := runTestServer(testServer)
+	client, closer := runTestServer(testServer, grpc.UnaryInterceptor(testServer.checkAuth))
 	defer closer()
 
+	ctx := newAuthContext(t, testServer.tm, mockStorage, testUsername2)
+
 	// Test create secret
-	_, err := client.SetSecrets(
-		context.Background(),
+	result, err := client.SetSecrets(
+		ctx,
 		&pb.Secrets{
 			Secrets: []*pb.Secret{
 				{
-					Owner: testUsername2,
-					Kind:  0,
-					Name:  "testSecretToCreate",
+					Owner:    testUsername2,
+					Kind:     0,
+					Name:     "testSecretToCreate",
+					Modified: timestamppb.Now(),
 				},
 			},
 		},
 	)
 	require.NoError(t, err)
+	require.Equal(t, pb.SecretResult_CREATED, result.Results[0].Outcome)
+	require.Equal(t, int64(1), result.Results[0].Revision)
 
 	// Test delete secret
-	_, err = client.SetSecrets(
-		context.Background(),
+	result, err = client.SetSecrets(
+		ctx,
 		&pb.Secrets{
 			Secrets: []*pb.Secret{
 				{
-					Owner:   testUsername2,
-					Kind:    0,
-					Name:    "testSecretToDelete",
-					Deleted: true,
+					Owner:    testUsername2,
+					Kind:     0,
+					Name:     "testSecretToDelete",
+					Value:    []byte("deleted"),
+					Modified: timestamppb.New(testModified),
+					Deleted:  true,
+					Revision: 1,
 				},
 			},
 		},
 	)
 	require.NoError(t, err)
+	require.Equal(t, pb.SecretResult_DELETED, result.Results[0].Outcome)
+	require.Equal(t, int64(2), result.Results[0].Revision)
+
+	// Test deletion without the value purges the secret versions
+	result, err = client.SetSecrets(
+		ctx,
+		&pb.Secrets{
+			Secrets: []*pb.Secret{
+				{
+					Owner:    testUsername2,
+					Kind:     0,
+					Name:     "testSecretToPurge",
+					Modified: timestamppb.New(testModified),
+					Deleted:  true,
+					Revision: 6,
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, pb.SecretResult_DELETED, result.Results[0].Outcome)
+	require.Equal(t, int64(7), result.Results[0].Revision)
 
 	// Test update secret
-	_, err = client.SetSecrets(
-		context.Background(),
+	result, err = client.SetSecrets(
+		ctx,
 		&pb.Secrets{
 			Secrets: []*pb.Secret{
 				{
@@ -193,9 +257,476 @@ func TestRPCSetSecrets(t *testing.T) {
 					Kind:     0,
 					Name:     "testSecretToUpdate",
 					Modified: timestamppb.Now(),
+					Revision: 2,
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, pb.SecretResult_UPDATED, result.Results[0].Outcome)
+	require.Equal(t, int64(3), result.Results[0].Revision)
+
+	// Test stale secret conflicts and invalid one is rejected
+	result, err = client.SetSecrets(
+		ctx,
+		&pb.Secrets{
+			Secrets: []*pb.Secret{
+				{
+					Owner:    testUsername2,
+					Kind:     0,
+					Name:     "testStaleSecret",
+					Value:    []byte("mine"),
+					Modified: timestamppb.Now(),
+					Revision: 4,
+				},
+				{
+					Owner: testUsername2,
+					Kind:  0,
+					Name:  "testSecretWithoutModified",
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	require.Len(t, result.Results, 2)
+	require.Equal(t, pb.SecretResult_CONFLICT, result.Results[0].Outcome)
+	require.Equal(t, []byte("theirs"), result.Results[0].Current.Value)
+	require.Equal(t, int64(5), result.Results[0].Current.Revision)
+	require.Equal(t, pb.SecretResult_FAILED, result.Results[1].Outcome)
+	require.NotEmpty(t, result.Results[1].Reason)
+}
+
+var testModified = time.Now().UTC().Truncate(time.Microsecond)
+
+type secretNameMatcher struct {
+	name string
+}
+
+// newSecretNameMatcher matches secret query params by the secret name.
+func newSecretNameMatcher(name string) gomock.Matcher {
+	return secretNameMatcher{name}
+}
+
+func (m secretNameMatcher) Matches(x interface{}) bool {
+	switch params := x.(type) {
+	case db.CreateSecretParams:
+		return params.Name == m.name
+	case db.UpdateSecretParams:
+		return params.Name == m.name
+	case db.GetSecretParams:
+		return params.Name == m.name
+	}
+	return false
+}
+
+func (m secretNameMatcher) String() string {
+	return "is secret " + m.name
+}
+
+func TestRPCSetSecretsRollback(t *testing.T) {
+	// Create mock storage
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockStore(controller)
+
+	mockStorage.EXPECT().
+		WithTx(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(1).
+		DoAndReturn(func(_ context.Context, fn func(db.Querier) error) error {
+			return fn(mockStorage)
+		})
+
+	mockStorage.EXPECT().
+		LockUserSecrets(
+			gomock.Any(),
+			testUsername2,
+		).
+		Times(1).
+		Return(nil)
+
+	mockStorage.EXPECT().
+		UpdateSecret(
+			gomock.Any(),
+			newSecretNameMatcher("testSecretToUpdate"),
+		).
+		Times(1).
+		Return(db.Secret{Revision: 1}, nil)
+
+	mockStorage.EXPECT().
+		UpdateSecret(
+			gomock.Any(),
+			newSecretNameMatcher("testBrokenSecret"),
+		).
+		Times(1).
+		Return(db.Secret{}, sql.ErrConnDone)
+
+	// Nothing is recorded for the rolled back batch
+	mockStorage.EXPECT().CreateAuditEvent(gomock.Any(), gomock.Any()).Times(0)
+
+	// Create server
+	testServer := &Server{
+		config:  Config{},
+		storage: mockStorage,
+		tm:      newTestMaker(t),
+	}
+
+	// Run test gRPC server
+	client, closer := runTestServer(testServer, grpc.UnaryInterceptor(testServer.checkAuth))
+	defer closer()
+
+	ctx := newAuthContext(t, testServer.tm, mockStorage, testUsername2)
+
+	_, err := client.SetSecrets(
+		ctx,
+		&pb.Secrets{
+			Secrets: []*pb.Secret{
+				{
+					Owner:    testUsername2,
+					Kind:     0,
+					Name:     "testSecretToUpdate",
+					Modified: timestamppb.Now(),
+				},
+				{
+					Owner:    testUsername2,
+					Kind:     0,
+					Name:     "testBrokenSecret",
+					Modified: timestamppb.Now(),
+				},
+			},
+		},
+	)
+	require.Error(t, err)
+
+	e, _ := status.FromError(err)
+	require.Equal(t, codes.Internal, e.Code())
+}
+
+func TestRPCSealSecrets(t *testing.T) {
+	createdID := random.RandomString(32)
+	conflictID := random.RandomString(32)
+
+	// Create mock storage
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockStore(controller)
+	allowTx(mockStorage)
+	allowAuditEvents(mockStorage)
+
+	mockStorage.EXPECT().
+		LockUserSecrets(
+			gomock.Any(),
+			testUsername2,
+		).
+		Times(1).
+		Return(nil)
+
+	mockStorage.EXPECT().
+		UpdateSecret(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(2).
+		Return(db.Secret{}, sql.ErrNoRows)
+
+	mockStorage.EXPECT().
+		GetSecret(
+			gomock.Any(),
+			db.GetSecretParams{Owner: testUsername2, Kind: sealedKind, Name: createdID},
+		).
+		Times(1).
+		Return(db.Secret{}, sql.ErrNoRows)
+
+	mockStorage.EXPECT().
+		GetSecret(
+			gomock.Any(),
+			db.GetSecretParams{Owner: testUsername2, Kind: sealedKind, Name: conflictID},
+		).
+		Times(1).
+		Return(db.Secret{Owner: testUsername2, Kind: sealedKind, Name: conflictID, Revision: 4}, nil)
+
+	mockStorage.EXPECT().
+		CreateSecret(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(1).
+		Return(db.Secret{Revision: 7}, nil)
+
+	// Only the secret with the created sealed copy goes, with its versions and without a tombstone
+	mockStorage.EXPECT().
+		ForgetSecret(
+			gomock.Any(),
+			db.ForgetSecretParams{Owner: testUsername2, Kind: 1, Name: "bank"},
+		).
+		Times(1).
+		Return(int64(1), nil)
+
+	// Create server
+	testServer := &Server{
+		config:  Config{},
+		storage: mockStorage,
+		tm:      newTestMaker(t),
+	}
+
+	// Run test gRPC server
+	client, closer := runTestServer(testServer, grpc.UnaryInterceptor(testServer.checkAuth))
+	defer closer()
+
+	ctx := newAuthContext(t, testServer.tm, mockStorage, testUsername2)
+
+	sealed := func(name string) *pb.Secret {
+		return &pb.Secret{Owner: testUsername2, Kind: sealedKind, Name: name, Value: []byte("sealed"), Modified: timestamppb.Now()}
+	}
+
+	// Plaintext secret is only replaced with a sealed one
+	_, err := client.SealSecrets(
+		ctx,
+		&pb.SealSecretsRequest{
+			Secrets: []*pb.SealedSecret{
+				{
+					Plain:  &pb.SecretRequest{Kind: 1, Name: "bank"},
+					Sealed: &pb.Secret{Owner: testUsername2, Kind: 1, Name: "mail", Modified: timestamppb.Now()},
 				},
 			},
 		},
 	)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.SealSecrets(
+		ctx,
+		&pb.SealSecretsRequest{Secrets: []*pb.SealedSecret{{Plain: &pb.SecretRequest{Kind: 1}, Sealed: sealed(createdID)}}},
+	)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	result, err := client.SealSecrets(
+		ctx,
+		&pb.SealSecretsRequest{
+			Secrets: []*pb.SealedSecret{
+				{Plain: &pb.SecretRequest{Kind: 1, Name: "bank"}, Sealed: sealed(createdID)},
+				{Plain: &pb.SecretRequest{Kind: 1, Name: "mail"}, Sealed: sealed(conflictID)},
+			},
+		},
+	)
 	require.NoError(t, err)
+	require.Len(t, result.Results, 2)
+	require.Equal(t, pb.SecretResult_CREATED, result.Results[0].Outcome)
+	require.Equal(t, int64(7), result.Results[0].Revision)
+	require.Equal(t, pb.SecretResult_CONFLICT, result.Results[1].Outcome)
+}
+
+func TestRPCGetChanges(t *testing.T) {
+	// Create mock storage
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockStore(controller)
+	allowTx(mockStorage)
+
+	mockStorage.EXPECT().
+		LockUserSecretsShared(
+			gomock.Any(),
+			testUsername2,
+		).
+		Times(4).
+		Return(nil)
+
+	mockStorage.EXPECT().
+		GetPurgedRevision(
+			gomock.Any(),
+			testUsername2,
+		).
+		Times(4).
+		Return(int64(5), nil)
+
+	mockStorage.EXPECT().
+		GetSecretChanges(
+			gomock.Any(),
+			db.GetSecretChangesParams{
+				Owner:    testUsername2,
+				Revision: 3,
+				Limit:    maxChanges + 1,
+			},
+		).
+		Times(2).
+		Return(
+			[]db.Secret{
+				{Owner: testUsername2, Name: "changed", Revision: 11},
+			},
+			nil,
+		)
+
+	mockStorage.EXPECT().
+		GetSecretChanges(
+			gomock.Any(),
+			db.GetSecretChangesParams{
+				Owner:    testUsername2,
+				Revision: 10,
+				Limit:    maxChanges + 1,
+			},
+		).
+		Times(1).
+		Return(
+			[]db.Secret{
+				{Owner: testUsername2, Name: "changed", Revision: 11},
+				{Owner: testUsername2, Name: "deleted", Revision: 15, Deleted: true},
+			},
+			nil,
+		)
+
+	mockStorage.EXPECT().
+		GetSecretChanges(
+			gomock.Any(),
+			db.GetSecretChangesParams{
+				Owner:    testUsername2,
+				Revision: 15,
+				Limit:    maxChanges + 1,
+			},
+		).
+		Times(1).
+		Return([]db.Secret{}, nil)
+
+	// Create server
+	testServer := &Server{
+		config:  Config{},
+		storage: mockStorage,
+		tm:      newTestMaker(t),
+	}
+
+	// Run test gRPC server
+	client, closer := runTestServer(testServer, grpc.UnaryInterceptor(testServer.checkAuth))
+	defer closer()
+
+	ctx := newAuthContext(t, testServer.tm, mockStorage, testUsername2)
+
+	changes, err := client.GetChanges(ctx, &pb.ChangesRequest{SinceRevision: 10})
+	require.NoError(t, err)
+	require.Len(t, changes.Secrets, 2)
+	require.True(t, changes.Secrets[1].Deleted)
+	require.Equal(t, int64(15), changes.Revision)
+	require.False(t, changes.More)
+
+	// No changes keep the cursor
+	changes, err = client.GetChanges(ctx, &pb.ChangesRequest{SinceRevision: 15})
+	require.NoError(t, err)
+	require.Empty(t, changes.Secrets)
+	require.Equal(t, int64(15), changes.Revision)
+
+	// Tombstones after the revision are purged
+	changes, err = client.GetChanges(ctx, &pb.ChangesRequest{SinceRevision: 3})
+	require.NoError(t, err)
+	require.True(t, changes.Resync)
+	require.Empty(t, changes.Secrets)
+
+	changes, err = client.GetChanges(ctx, &pb.ChangesRequest{SinceRevision: 3, Resync: true})
+	require.NoError(t, err)
+	require.False(t, changes.Resync)
+	require.Len(t, changes.Secrets, 1)
+	require.Equal(t, int64(11), changes.Revision)
+}
+
+func TestRPCGetSecretsOfAnotherUser(t *testing.T) {
+	// Create mock storage
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockStore(controller)
+
+	mockStorage.EXPECT().
+		GetSecretsByUser(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(0)
+
+	// Create server
+	testServer := &Server{
+		config:  Config{},
+		storage: mockStorage,
+		tm:      newTestMaker(t),
+	}
+
+	// Run test gRPC server
+	client, closer := runTestServer(testServer, grpc.UnaryInterceptor(testServer.checkAuth))
+	defer closer()
+
+	ctx := newAuthContext(t, testServer.tm, mockStorage, testUsername4)
+
+	// Test get secrets of another user
+	_, err := client.GetSecrets(ctx, &pb.SecretsRequest{Owner: testUsername2})
+	require.Error(t, err)
+
+	e, _ := status.FromError(err)
+	require.Equal(t, codes.PermissionDenied, e.Code())
+}
+
+func TestRPCSetSecretsOfAnotherUser(t *testing.T) {
+	// Create mock storage
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockStore(controller)
+
+	mockStorage.EXPECT().CreateSecret(gomock.Any(), gomock.Any()).Times(0)
+	mockStorage.EXPECT().UpdateSecret(gomock.Any(), gomock.Any()).Times(0)
+
+	// Create server
+	testServer := &Server{
+		config:  Config{},
+		storage: mockStorage,
+		tm:      newTestMaker(t),
+	}
+
+	// Run test gRPC server
+	client, closer := runTestServer(testServer, grpc.UnaryInterceptor(testServer.checkAuth))
+	defer closer()
+
+	ctx := newAuthContext(t, testServer.tm, mockStorage, testUsername4)
+
+	// Test overwrite secret of another user
+	_, err := client.SetSecrets(
+		ctx,
+		&pb.Secrets{
+			Secrets: []*pb.Secret{
+				{
+					Owner:    testUsername4,
+					Kind:     0,
+					Name:     "testSecretOfMine",
+					Modified: timestamppb.Now(),
+				},
+				{
+					Owner:    testUsername2,
+					Kind:     0,
+					Name:     "testSecretToOverwrite",
+					Modified: timestamppb.Now(),
+				},
+			},
+		},
+	)
+	require.Error(t, err)
+
+	e, _ := status.FromError(err)
+	require.Equal(t, codes.PermissionDenied, e.Code())
+}
+
+func TestRPCSecretsWithoutAuth(t *testing.T) {
+	// Create mock storage
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockStore(controller)
+
+	// Create server
+	testServer := &Server{
+		config:  Config{},
+		storage: mockStorage,
+	}
+
+	// Run test gRPC server without auth interceptor
+	client, closer := runTestServer(testServer)
+	defer closer()
+
+	_, err := client.GetSecrets(context.Background(), &pb.SecretsRequest{Owner: testUsername2})
+	require.Error(t, err)
+
+	e, _ := status.FromError(err)
+	require.Equal(t, codes.Unauthenticated, e.Code())
+
+	_, err = client.GetChanges(context.Background(), &pb.ChangesRequest{})
+	require.Error(t, err)
+
+	e, _ = status.FromError(err)
+	require.Equal(t, codes.Unauthenticated, e.Code())
 }
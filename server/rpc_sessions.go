@@ -0,0 +1,168 @@
+package server
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"gophkeeper/audit"
+	"gophkeeper/converter"
+	"gophkeeper/db/db"
+	"gophkeeper/pb"
+	"gophkeeper/token"
+)
+
+// peerAddress returns the request peer address.
+func peerAddress(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	return p.Addr.String()
+}
+
+// metadataValue returns the first request metadata value of the key.
+func metadataValue(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+// newSession records a new user session with the device info the client provided.
+func (s *Server) newSession(ctx context.Context, username string) (string, error) {
+	sessionID, err := token.NewFamilyID()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = s.storage.CreateSession(
+		ctx,
+		db.CreateSessionParams{
+			ID:            sessionID,
+			Username:      username,
+			Device:        metadataValue(ctx, "device"),
+			ClientVersion: metadataValue(ctx, "version"),
+			Ip:            peerAddress(ctx),
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return sessionID, nil
+}
+
+func (s *Server) ListSessions(ctx context.Context, in *emptypb.Empty) (*pb.Sessions, error) {
+	payload, err := payloadFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.storage.GetSessionsByUser(ctx, payload.Username)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("failed to get user '%s' sessions", payload.Username)
+		return nil, status.Errorf(codes.Internal, "failed to get sessions from db")
+	}
+
+	pbSessions := []*pb.Session{}
+	for _, session := range sessions {
+		pbSession := converter.DBSessionToPBSession(session)
+		pbSession.Current = session.ID == payload.SessionID
+		pbSessions = append(pbSessions, pbSession)
+	}
+
+	return &pb.Sessions{Sessions: pbSessions}, nil
+}
+
+func (s *Server) RevokeSession(ctx context.Context, in *pb.SessionRequest) (*emptypb.Empty, error) {
+	username, err := usernameFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked, err := s.storage.RevokeSession(
+		ctx,
+		db.RevokeSessionParams{
+			ID:       in.Id,
+			Username: username,
+		},
+	)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("failed to revoke user '%s' session", username)
+		return nil, status.Errorf(codes.Internal, "failed to revoke session")
+	}
+
+	if revoked == 0 {
+		return nil, status.Errorf(codes.NotFound, "session not found")
+	}
+
+	err = s.storage.RevokeRefreshTokenFamily(ctx, in.Id)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("failed to revoke user '%s' session refresh tokens", username)
+		return nil, status.Errorf(codes.Internal, "failed to revoke session")
+	}
+
+	s.recordEvent(ctx, audit.EventSessionRevoked, username, 0, "")
+	s.log.Info().Msgf("user '%s' revoked session '%s'", username, in.Id)
+
+	return &emptypb.Empty{}, nil
+}
+
+// RevokeAllSessions revokes all the user sessions except the current one.
+func (s *Server) RevokeAllSessions(ctx context.Context, in *emptypb.Empty) (*emptypb.Empty, error) {
+	payload, err := payloadFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked, err := s.revokeOtherSessions(ctx, payload.Username, payload.SessionID)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("failed to revoke user '%s' sessions", payload.Username)
+		return nil, status.Errorf(codes.Internal, "failed to revoke sessions")
+	}
+
+	s.recordEvent(ctx, audit.EventSessionRevoked, payload.Username, 0, "")
+	s.log.Info().Msgf("user '%s' revoked %v other sessions", payload.Username, revoked)
+
+	return &emptypb.Empty{}, nil
+}
+
+// revokeOtherSessions revokes all the user sessions (and their refresh tokens) but the one provided.
+func (s *Server) revokeOtherSessions(ctx context.Context, username, sessionID string) (int64, error) {
+	revoked, err := s.storage.RevokeOtherSessions(
+		ctx,
+		db.RevokeOtherSessionsParams{
+			Username: username,
+			ID:       sessionID,
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	err = s.storage.RevokeOtherRefreshTokens(
+		ctx,
+		db.RevokeOtherRefreshTokensParams{
+			Username: username,
+			Family:   sessionID,
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return revoked, nil
+}
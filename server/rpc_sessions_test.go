@@ -0,0 +1,171 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"gophkeeper/db/db"
+	"gophkeeper/db/mock"
+	"gophkeeper/pb"
+	"gophkeeper/random"
+)
+
+var testUsername5 = random.RandomOwner()
+
+func TestRPCListSessions(t *testing.T) {
+	otherSessionID := random.RandomString(32)
+
+	// Create mock storage
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockStore(controller)
+
+	mockStorage.EXPECT().
+		GetSessionsByUser(
+			gomock.Any(),
+			testUsername5,
+		).
+		Times(1).
+		Return(
+			[]db.Session{
+				{ID: testSessionID, Username: testUsername5, Device: "laptop", LastSeen: time.Now()},
+				{ID: otherSessionID, Username: testUsername5, Device: "phone", LastSeen: time.Now()},
+			},
+			nil,
+		)
+
+	// Create server
+	testServer := &Server{
+		config:  Config{},
+		storage: mockStorage,
+		tm:      newTestMaker(t),
+	}
+
+	// Run test gRPC server
+	client, closer := runTestServer(testServer, grpc.UnaryInterceptor(testServer.checkAuth))
+	defer closer()
+
+	ctx := newAuthContext(t, testServer.tm, mockStorage, testUsername5)
+
+	pbSessions, err := client.ListSessions(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	require.Len(t, pbSessions.Sessions, 2)
+
+	require.Equal(t, "laptop", pbSessions.Sessions[0].Device)
+	require.True(t, pbSessions.Sessions[0].Current)
+	require.Equal(t, "phone", pbSessions.Sessions[1].Device)
+	require.False(t, pbSessions.Sessions[1].Current)
+}
+
+func TestRPCRevokeSession(t *testing.T) {
+	otherSessionID := random.RandomString(32)
+
+	// Create mock storage
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockStore(controller)
+	allowAuditEvents(mockStorage)
+
+	mockStorage.EXPECT().
+		RevokeSession(
+			gomock.Any(),
+			db.RevokeSessionParams{
+				ID:       otherSessionID,
+				Username: testUsername5,
+			},
+		).
+		Times(1).
+		Return(int64(1), nil)
+
+	mockStorage.EXPECT().
+		RevokeRefreshTokenFamily(
+			gomock.Any(),
+			otherSessionID,
+		).
+		Times(1).
+		Return(nil)
+
+	// Session of another user is not found for the user
+	mockStorage.EXPECT().
+		RevokeSession(
+			gomock.Any(),
+			db.RevokeSessionParams{
+				ID:       "anotherusersession",
+				Username: testUsername5,
+			},
+		).
+		Times(1).
+		Return(int64(0), nil)
+
+	// Create server
+	testServer := &Server{
+		config:  Config{},
+		storage: mockStorage,
+		tm:      newTestMaker(t),
+	}
+
+	// Run test gRPC server
+	client, closer := runTestServer(testServer, grpc.UnaryInterceptor(testServer.checkAuth))
+	defer closer()
+
+	ctx := newAuthContext(t, testServer.tm, mockStorage, testUsername5)
+
+	_, err := client.RevokeSession(ctx, &pb.SessionRequest{Id: otherSessionID})
+	require.NoError(t, err)
+
+	_, err = client.RevokeSession(ctx, &pb.SessionRequest{Id: "anotherusersession"})
+	require.Error(t, err)
+
+	e, _ := status.FromError(err)
+	require.Equal(t, codes.NotFound, e.Code())
+}
+
+func TestRPCRevokeAllSessions(t *testing.T) {
+	// Create mock storage
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockStore(controller)
+	allowAuditEvents(mockStorage)
+
+	mockStorage.EXPECT().
+		RevokeOtherSessions(
+			gomock.Any(),
+			db.RevokeOtherSessionsParams{
+				Username: testUsername5,
+				ID:       testSessionID,
+			},
+		).
+		Times(1).
+		Return(int64(3), nil)
+
+	mockStorage.EXPECT().
+		RevokeOtherRefreshTokens(
+			gomock.Any(),
+			db.RevokeOtherRefreshTokensParams{
+				Username: testUsername5,
+				Family:   testSessionID,
+			},
+		).
+		Times(1).
+		Return(nil)
+
+	// Create server
+	testServer := &Server{
+		config:  Config{},
+		storage: mockStorage,
+		tm:      newTestMaker(t),
+	}
+
+	// Run test gRPC server
+	client, closer := runTestServer(testServer, grpc.UnaryInterceptor(testServer.checkAuth))
+	defer closer()
+
+	ctx := newAuthContext(t, testServer.tm, mockStorage, testUsername5)
+
+	_, err := client.RevokeAllSessions(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+}
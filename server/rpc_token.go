@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"gophkeeper/db/db"
+	"gophkeeper/pb"
+	"gophkeeper/token"
+)
+
+const (
+	accessTokenDuration  = 15 * time.Minute
+	refreshTokenDuration = 30 * 24 * time.Hour
+)
+
+// issueTokens creates a new access token and a new refresh token of the session.
+// Session ID is also the refresh token family. Empty session starts a new one (e.g. on login).
+func (s *Server) issueTokens(ctx context.Context, username, family string) (*pb.Token, error) {
+	var err error
+	if family == "" {
+		family, err = s.newSession(ctx, username)
+		if err != nil {
+			s.log.Error().Err(err).Msgf("failed to create user '%s' session", username)
+			return nil, status.Errorf(codes.Internal, "failed to create session")
+		}
+	}
+
+	accessToken, err := s.tm.CreateToken(username, family, accessTokenDuration)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create access token")
+	}
+
+	refreshToken, err := token.NewRefreshToken()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create refresh token")
+	}
+
+	_, err = s.storage.CreateRefreshToken(
+		ctx,
+		db.CreateRefreshTokenParams{
+			Hash:      token.HashRefreshToken(refreshToken),
+			Family:    family,
+			Username:  username,
+			ExpiresAt: time.Now().Add(refreshTokenDuration),
+		},
+	)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("failed to save user '%s' refresh token", username)
+		return nil, status.Errorf(codes.Internal, "failed to save refresh token")
+	}
+
+	return &pb.Token{Value: accessToken, Refresh: refreshToken}, nil
+}
+
+// RefreshToken exchanges a refresh token for a new pair of access and refresh tokens.
+// Every refresh token could be used only once. Reuse of a refresh token means
+// it has leaked so the whole token family gets revoked.
+func (s *Server) RefreshToken(ctx context.Context, in *pb.RefreshRequest) (*pb.Token, error) {
+	hash := token.HashRefreshToken(in.Refresh)
+
+	dbToken, err := s.storage.GetRefreshToken(ctx, hash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.Unauthenticated, "refresh token is invalid")
+		}
+		s.log.Error().Err(err).Msg("failed to get refresh token")
+		return nil, status.Errorf(codes.Internal, "failed to get refresh token")
+	}
+
+	if dbToken.Revoked {
+		return nil, status.Errorf(codes.Unauthenticated, "refresh token is revoked")
+	}
+
+	session, err := s.storage.GetSession(ctx, dbToken.Family)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		s.log.Error().Err(err).Msg("failed to get refresh token session")
+		return nil, status.Errorf(codes.Internal, "failed to get session")
+	}
+	if errors.Is(err, sql.ErrNoRows) || session.Revoked {
+		return nil, status.Errorf(codes.Unauthenticated, "session is revoked")
+	}
+
+	if time.Now().After(dbToken.ExpiresAt) {
+		return nil, status.Errorf(codes.Unauthenticated, "refresh token has expired")
+	}
+
+	marked := int64(0)
+	if !dbToken.Used {
+		marked, err = s.storage.MarkRefreshTokenUsed(ctx, hash)
+		if err != nil {
+			s.log.Error().Err(err).Msg("failed to mark refresh token used")
+			return nil, status.Errorf(codes.Internal, "failed to rotate refresh token")
+		}
+	}
+
+	if marked == 0 {
+		s.log.Warn().Msgf("user '%s' refresh token reuse detected, revoking token family", dbToken.Username)
+
+		err = s.storage.RevokeRefreshTokenFamily(ctx, dbToken.Family)
+		if err != nil {
+			s.log.Error().Err(err).Msgf("failed to revoke user '%s' refresh token family", dbToken.Username)
+			return nil, status.Errorf(codes.Internal, "failed to revoke refresh tokens")
+		}
+
+		_, err = s.storage.RevokeSession(
+			ctx,
+			db.RevokeSessionParams{
+				ID:       dbToken.Family,
+				Username: dbToken.Username,
+			},
+		)
+		if err != nil {
+			s.log.Error().Err(err).Msgf("failed to revoke user '%s' session", dbToken.Username)
+			return nil, status.Errorf(codes.Internal, "failed to revoke session")
+		}
+
+		return nil, status.Errorf(codes.Unauthenticated, "refresh token is revoked")
+	}
+
+	pbToken, err := s.issueTokens(ctx, dbToken.Username, dbToken.Family)
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info().Msgf("user '%s' successfully refreshed token", dbToken.Username)
+
+	return pbToken, nil
+}
+
+// GetTokenKeys returns public keys clients could verify their tokens with.
+func (s *Server) GetTokenKeys(ctx context.Context, in *emptypb.Empty) (*pb.TokenKeys, error) {
+	pbKeys := []*pb.TokenKey{}
+	for _, key := range s.tm.PublicKeys() {
+		pbKeys = append(pbKeys, &pb.TokenKey{Id: key.ID, Key: key.Key})
+	}
+
+	return &pb.TokenKeys{Keys: pbKeys}, nil
+}
@@ -0,0 +1,243 @@
+package server
+
+import (
+	"context"
+	"crypto/ed25519"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"gophkeeper/db/db"
+	"gophkeeper/db/mock"
+	"gophkeeper/pb"
+	"gophkeeper/token"
+)
+
+func TestRPCGetTokenKeys(t *testing.T) {
+	// Create server
+	testServer := &Server{
+		config: Config{},
+		tm:     newTestMaker(t),
+	}
+
+	// Run test gRPC server
+	client, closer := runTestServer(testServer, grpc.UnaryInterceptor(testServer.checkAuth))
+	defer closer()
+
+	// Test keys are available without token
+	pbKeys, err := client.GetTokenKeys(context.Background(), &emptypb.Empty{})
+	require.NoError(t, err)
+	require.Len(t, pbKeys.Keys, 1)
+
+	publicKeys := []token.PublicKey{}
+	for _, pbKey := range pbKeys.Keys {
+		publicKeys = append(publicKeys, token.PublicKey{ID: pbKey.Id, Key: ed25519.PublicKey(pbKey.Key)})
+	}
+
+	// Test server tokens could be verified with the keys
+	testToken, err := testServer.tm.CreateToken(testUsername, testSessionID, time.Minute)
+	require.NoError(t, err)
+
+	verifier := token.NewPasetoVerifier(publicKeys...)
+	payload, err := verifier.VerifyToken(testToken)
+	require.NoError(t, err)
+	require.Equal(t, testUsername, payload.Username)
+}
+
+func TestRPCRefreshToken(t *testing.T) {
+	testRefreshToken, err := token.NewRefreshToken()
+	require.NoError(t, err)
+
+	testHash := token.HashRefreshToken(testRefreshToken)
+	testFamily, err := token.NewFamilyID()
+	require.NoError(t, err)
+
+	// Create mock storage
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockStore(controller)
+
+	mockStorage.EXPECT().
+		GetRefreshToken(
+			gomock.Any(),
+			testHash,
+		).
+		Times(1).
+		Return(
+			db.RefreshToken{
+				Hash:      testHash,
+				Family:    testFamily,
+				Username:  testUsername,
+				ExpiresAt: time.Now().Add(time.Hour),
+			},
+			nil,
+		)
+
+	mockStorage.EXPECT().
+		GetSession(
+			gomock.Any(),
+			testFamily,
+		).
+		Times(1).
+		Return(db.Session{ID: testFamily, Username: testUsername}, nil)
+
+	mockStorage.EXPECT().
+		MarkRefreshTokenUsed(
+			gomock.Any(),
+			testHash,
+		).
+		Times(1).
+		Return(int64(1), nil)
+
+	mockStorage.EXPECT().
+		CreateRefreshToken(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, arg db.CreateRefreshTokenParams) (db.RefreshToken, error) {
+			// Rotated token must stay in the same family
+			require.Equal(t, testFamily, arg.Family)
+			require.Equal(t, testUsername, arg.Username)
+			require.NotEqual(t, testHash, arg.Hash)
+			return db.RefreshToken{}, nil
+		})
+
+	// Create server
+	testServer := &Server{
+		config:  Config{},
+		storage: mockStorage,
+		tm:      newTestMaker(t),
+	}
+
+	// Run test gRPC server
+	client, closer := runTestServer(testServer, grpc.UnaryInterceptor(testServer.checkAuth))
+	defer closer()
+
+	pbToken, err := client.RefreshToken(context.Background(), &pb.RefreshRequest{Refresh: testRefreshToken})
+	require.NoError(t, err)
+	require.NotEmpty(t, pbToken.Refresh)
+	require.NotEqual(t, testRefreshToken, pbToken.Refresh)
+
+	payload, err := testServer.tm.VerifyToken(pbToken.Value)
+	require.NoError(t, err)
+	require.Equal(t, testUsername, payload.Username)
+}
+
+func TestRPCRefreshTokenReuse(t *testing.T) {
+	testRefreshToken, err := token.NewRefreshToken()
+	require.NoError(t, err)
+
+	testHash := token.HashRefreshToken(testRefreshToken)
+	testFamily, err := token.NewFamilyID()
+	require.NoError(t, err)
+
+	// Create mock storage
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockStore(controller)
+
+	mockStorage.EXPECT().
+		GetRefreshToken(
+			gomock.Any(),
+			testHash,
+		).
+		Times(1).
+		Return(
+			db.RefreshToken{
+				Hash:      testHash,
+				Family:    testFamily,
+				Username:  testUsername,
+				ExpiresAt: time.Now().Add(time.Hour),
+				Used:      true,
+			},
+			nil,
+		)
+
+	mockStorage.EXPECT().
+		GetSession(
+			gomock.Any(),
+			testFamily,
+		).
+		Times(1).
+		Return(db.Session{ID: testFamily, Username: testUsername}, nil)
+
+	mockStorage.EXPECT().
+		RevokeRefreshTokenFamily(
+			gomock.Any(),
+			testFamily,
+		).
+		Times(1).
+		Return(nil)
+
+	mockStorage.EXPECT().
+		RevokeSession(
+			gomock.Any(),
+			db.RevokeSessionParams{
+				ID:       testFamily,
+				Username: testUsername,
+			},
+		).
+		Times(1).
+		Return(int64(1), nil)
+
+	mockStorage.EXPECT().
+		CreateRefreshToken(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(0)
+
+	// Create server
+	testServer := &Server{
+		config:  Config{},
+		storage: mockStorage,
+		tm:      newTestMaker(t),
+	}
+
+	// Run test gRPC server
+	client, closer := runTestServer(testServer, grpc.UnaryInterceptor(testServer.checkAuth))
+	defer closer()
+
+	_, err = client.RefreshToken(context.Background(), &pb.RefreshRequest{Refresh: testRefreshToken})
+	require.Error(t, err)
+
+	e, _ := status.FromError(err)
+	require.Equal(t, codes.Unauthenticated, e.Code())
+}
+
+func TestRPCRefreshTokenInvalid(t *testing.T) {
+	// Create mock storage
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockStore(controller)
+
+	mockStorage.EXPECT().
+		GetRefreshToken(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(1).
+		Return(db.RefreshToken{}, sql.ErrNoRows)
+
+	// Create server
+	testServer := &Server{
+		config:  Config{},
+		storage: mockStorage,
+		tm:      newTestMaker(t),
+	}
+
+	// Run test gRPC server
+	client, closer := runTestServer(testServer, grpc.UnaryInterceptor(testServer.checkAuth))
+	defer closer()
+
+	_, err := client.RefreshToken(context.Background(), &pb.RefreshRequest{Refresh: "someinvalidtoken"})
+	require.Error(t, err)
+
+	e, _ := status.FromError(err)
+	require.Equal(t, codes.Unauthenticated, e.Code())
+}
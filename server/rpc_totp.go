@@ -0,0 +1,175 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"gophkeeper/audit"
+	"gophkeeper/db/db"
+	"gophkeeper/pb"
+	"gophkeeper/totp"
+)
+
+const (
+	totpIssuer        = "gophkeeper"
+	recoveryCodeCount = 10
+)
+
+// secondFactorError returns Unauthenticated status the client could tell
+// from a bad password by its ErrorInfo reason.
+func secondFactorError(reason, msg string) error {
+	errorInfo := &errdetails.ErrorInfo{Reason: reason, Domain: totp.ErrorDomain}
+	statusUnauthenticated := status.New(codes.Unauthenticated, msg)
+
+	statusDetails, err := statusUnauthenticated.WithDetails(errorInfo)
+	if err != nil {
+		return statusUnauthenticated.Err()
+	}
+
+	return statusDetails.Err()
+}
+
+// checkSecondFactor validates the TOTP or recovery code of the user with two-factor authentication enabled.
+func (s *Server) checkSecondFactor(ctx context.Context, dbUser db.User, code string) error {
+	if code == "" {
+		return secondFactorError(totp.SecondFactorRequired, "second factor required")
+	}
+
+	step, ok := totp.Validate(dbUser.TotpSecret, code, time.Now())
+	if ok {
+		used, err := s.storage.UseTOTPStep(ctx, db.UseTOTPStepParams{Name: dbUser.Name, TotpLastStep: step})
+		if err != nil {
+			s.log.Error().Err(err).Msgf("failed to save user '%s' totp step", dbUser.Name)
+			return status.Errorf(codes.Internal, "failed to check second factor")
+		}
+
+		if used == 0 {
+			return secondFactorError(totp.SecondFactorInvalid, "second factor code was already used")
+		}
+
+		return nil
+	}
+
+	used, err := s.storage.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{Username: dbUser.Name, Hash: totp.HashRecoveryCode(code)})
+	if err != nil {
+		s.log.Error().Err(err).Msgf("failed to use user '%s' recovery code", dbUser.Name)
+		return status.Errorf(codes.Internal, "failed to check second factor")
+	}
+
+	if used == 0 {
+		return secondFactorError(totp.SecondFactorInvalid, "second factor code is invalid")
+	}
+
+	s.log.Warn().Msgf("user '%s' logged in with recovery code", dbUser.Name)
+
+	return nil
+}
+
+// EnrollTOTP generates the new TOTP secret and recovery codes for the user.
+// Two-factor authentication is not enabled until the first code is confirmed with ConfirmTOTP.
+func (s *Server) EnrollTOTP(ctx context.Context, in *pb.TOTPEnrollRequest) (*pb.TOTPEnrollment, error) {
+	username, err := usernameFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.checkUserPassword(ctx, username, in.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	dbUser, err := s.storage.GetUser(ctx, username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find user")
+	}
+
+	if dbUser.TotpEnabled {
+		return nil, status.Errorf(codes.FailedPrecondition, "two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate totp secret: %s", err)
+	}
+
+	recoveryCodes, err := totp.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate recovery codes: %s", err)
+	}
+
+	err = s.storage.SetTOTPSecret(ctx, db.SetTOTPSecretParams{Name: username, TotpSecret: secret})
+	if err != nil {
+		s.log.Error().Err(err).Msgf("failed to save user '%s' totp secret", username)
+		return nil, status.Errorf(codes.Internal, "failed to save totp secret")
+	}
+
+	err = s.storage.DeleteRecoveryCodes(ctx, username)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("failed to delete user '%s' recovery codes", username)
+		return nil, status.Errorf(codes.Internal, "failed to save recovery codes")
+	}
+
+	for _, code := range recoveryCodes {
+		err = s.storage.CreateRecoveryCode(ctx, db.CreateRecoveryCodeParams{Username: username, Hash: totp.HashRecoveryCode(code)})
+		if err != nil {
+			s.log.Error().Err(err).Msgf("failed to save user '%s' recovery code", username)
+			return nil, status.Errorf(codes.Internal, "failed to save recovery codes")
+		}
+	}
+
+	s.log.Info().Msgf("user '%s' started totp enrollment", username)
+
+	return &pb.TOTPEnrollment{
+		Secret:        secret,
+		Uri:           totp.ProvisioningURI(totpIssuer, username, secret),
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
+
+// ConfirmTOTP enables two-factor authentication once the user proves the authenticator is set up.
+func (s *Server) ConfirmTOTP(ctx context.Context, in *pb.TOTPCode) (*emptypb.Empty, error) {
+	username, err := usernameFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dbUser, err := s.storage.GetUser(ctx, username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find user")
+	}
+
+	if dbUser.TotpEnabled {
+		return nil, status.Errorf(codes.FailedPrecondition, "two-factor authentication is already enabled")
+	}
+
+	if dbUser.TotpSecret == "" {
+		return nil, status.Errorf(codes.FailedPrecondition, "totp enrollment is not started")
+	}
+
+	step, ok := totp.Validate(dbUser.TotpSecret, in.Code, time.Now())
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "totp code is invalid")
+	}
+
+	_, err = s.storage.UseTOTPStep(ctx, db.UseTOTPStepParams{Name: username, TotpLastStep: step})
+	if err != nil {
+		s.log.Error().Err(err).Msgf("failed to save user '%s' totp step", username)
+		return nil, status.Errorf(codes.Internal, "failed to enable two-factor authentication")
+	}
+
+	err = s.storage.EnableTOTP(ctx, username)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("failed to enable user '%s' totp", username)
+		return nil, status.Errorf(codes.Internal, "failed to enable two-factor authentication")
+	}
+
+	s.recordEvent(ctx, audit.EventTOTPEnabled, username, 0, "")
+	s.log.Info().Msgf("user '%s' enabled two-factor authentication", username)
+
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,266 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"gophkeeper/crypto"
+	"gophkeeper/db/db"
+	"gophkeeper/db/mock"
+	"gophkeeper/pb"
+	"gophkeeper/random"
+	"gophkeeper/totp"
+)
+
+var testUsername7 = random.RandomOwner()
+
+func requireSecondFactorReason(t *testing.T, err error, reason string) {
+	require.Error(t, err)
+
+	e, _ := status.FromError(err)
+	require.Equal(t, codes.Unauthenticated, e.Code())
+	require.Len(t, e.Details(), 1)
+
+	errorInfo, ok := e.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	require.Equal(t, reason, errorInfo.Reason)
+}
+
+func TestRPCLoginSecondFactor(t *testing.T) {
+	testUserPasshash, err := crypto.HashPassword(testPassword)
+	require.NoError(t, err)
+
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+
+	recoveryCode := "abcde-12345"
+
+	// Create mock storage
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockStore(controller)
+	allowAuditEvents(mockStorage)
+
+	mockStorage.EXPECT().
+		GetUser(
+			gomock.Any(),
+			testUsername7,
+		).
+		AnyTimes().
+		Return(
+			db.User{
+				Name:        testUsername7,
+				Passhash:    testUserPasshash,
+				TotpSecret:  secret,
+				TotpEnabled: true,
+			},
+			nil,
+		)
+
+	// First use of the code succeeds, replay fails
+	gomock.InOrder(
+		mockStorage.EXPECT().
+			UseTOTPStep(
+				gomock.Any(),
+				gomock.Any(),
+			).
+			Return(int64(1), nil),
+		mockStorage.EXPECT().
+			UseTOTPStep(
+				gomock.Any(),
+				gomock.Any(),
+			).
+			Return(int64(0), nil),
+	)
+
+	mockStorage.EXPECT().
+		UseRecoveryCode(
+			gomock.Any(),
+			db.UseRecoveryCodeParams{
+				Username: testUsername7,
+				Hash:     totp.HashRecoveryCode(recoveryCode),
+			},
+		).
+		Times(1).
+		Return(int64(1), nil)
+
+	mockStorage.EXPECT().
+		UseRecoveryCode(
+			gomock.Any(),
+			db.UseRecoveryCodeParams{
+				Username: testUsername7,
+				Hash:     totp.HashRecoveryCode("000000"),
+			},
+		).
+		Times(1).
+		Return(int64(0), nil)
+
+	mockStorage.EXPECT().
+		CreateSession(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(2).
+		Return(db.Session{}, nil)
+
+	mockStorage.EXPECT().
+		CreateRefreshToken(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(2).
+		Return(db.RefreshToken{}, nil)
+
+	// Create server
+	testServer := &Server{
+		config:  Config{},
+		storage: mockStorage,
+		tm:      newTestMaker(t),
+		hasher:  crypto.DefaultPasswordHasher,
+		limiter: newLoginLimiter(),
+	}
+
+	// Run test gRPC server
+	client, closer := runTestServer(testServer)
+	defer closer()
+
+	// Test missing code
+	_, err = client.Login(context.Background(), &pb.User{Name: testUsername7, Password: testPassword})
+	requireSecondFactorReason(t, err, totp.SecondFactorRequired)
+
+	// Test invalid code
+	_, err = client.Login(context.Background(), &pb.User{Name: testUsername7, Password: testPassword, Otp: "000000"})
+	requireSecondFactorReason(t, err, totp.SecondFactorInvalid)
+
+	// Test valid code
+	code, err := totp.GenerateCode(secret, time.Now())
+	require.NoError(t, err)
+
+	pbToken, err := client.Login(context.Background(), &pb.User{Name: testUsername7, Password: testPassword, Otp: code})
+	require.NoError(t, err)
+	require.NotEmpty(t, pbToken.Value)
+
+	// Test replayed code
+	_, err = client.Login(context.Background(), &pb.User{Name: testUsername7, Password: testPassword, Otp: code})
+	requireSecondFactorReason(t, err, totp.SecondFactorInvalid)
+
+	// Test recovery code
+	pbToken, err = client.Login(context.Background(), &pb.User{Name: testUsername7, Password: testPassword, Otp: recoveryCode})
+	require.NoError(t, err)
+	require.NotEmpty(t, pbToken.Value)
+}
+
+func TestRPCEnrollTOTP(t *testing.T) {
+	testUserPasshash, err := crypto.HashPassword(testPassword)
+	require.NoError(t, err)
+
+	var secret string
+
+	// Create mock storage
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockStore(controller)
+	allowAuditEvents(mockStorage)
+
+	mockStorage.EXPECT().
+		GetUser(
+			gomock.Any(),
+			testUsername7,
+		).
+		AnyTimes().
+		DoAndReturn(func(_ context.Context, _ string) (db.User, error) {
+			return db.User{Name: testUsername7, Passhash: testUserPasshash, TotpSecret: secret}, nil
+		})
+
+	mockStorage.EXPECT().
+		SetTOTPSecret(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.SetTOTPSecretParams) error {
+			secret = arg.TotpSecret
+			return nil
+		})
+
+	mockStorage.EXPECT().
+		DeleteRecoveryCodes(
+			gomock.Any(),
+			testUsername7,
+		).
+		Times(1).
+		Return(nil)
+
+	mockStorage.EXPECT().
+		CreateRecoveryCode(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(recoveryCodeCount).
+		Return(nil)
+
+	mockStorage.EXPECT().
+		UseTOTPStep(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(1).
+		Return(int64(1), nil)
+
+	mockStorage.EXPECT().
+		EnableTOTP(
+			gomock.Any(),
+			testUsername7,
+		).
+		Times(1).
+		Return(nil)
+
+	// Create server
+	testServer := &Server{
+		config:  Config{},
+		storage: mockStorage,
+		tm:      newTestMaker(t),
+		hasher:  crypto.DefaultPasswordHasher,
+		limiter: newLoginLimiter(),
+	}
+
+	// Run test gRPC server
+	client, closer := runTestServer(testServer, grpc.UnaryInterceptor(testServer.checkAuth))
+	defer closer()
+
+	ctx := newAuthContext(t, testServer.tm, mockStorage, testUsername7)
+
+	// Test confirm before enrollment
+	_, err = client.ConfirmTOTP(ctx, &pb.TOTPCode{Code: "000000"})
+	require.Error(t, err)
+
+	e, _ := status.FromError(err)
+	require.Equal(t, codes.FailedPrecondition, e.Code())
+
+	// Test enrollment
+	enrollment, err := client.EnrollTOTP(ctx, &pb.TOTPEnrollRequest{Password: testPassword})
+	require.NoError(t, err)
+	require.Equal(t, secret, enrollment.Secret)
+	require.Contains(t, enrollment.Uri, "otpauth://totp/")
+	require.Len(t, enrollment.RecoveryCodes, recoveryCodeCount)
+
+	// Test invalid code
+	_, err = client.ConfirmTOTP(ctx, &pb.TOTPCode{Code: "abcdef"})
+	require.Error(t, err)
+
+	e, _ = status.FromError(err)
+	require.Equal(t, codes.InvalidArgument, e.Code())
+
+	// Test valid code
+	code, err := totp.GenerateCode(secret, time.Now())
+	require.NoError(t, err)
+
+	_, err = client.ConfirmTOTP(ctx, &pb.TOTPCode{Code: code})
+	require.NoError(t, err)
+}
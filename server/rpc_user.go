@@ -3,20 +3,31 @@ package server
 import (
 	"context"
 	"database/sql"
-	"time"
 
 	"github.com/lib/pq"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
 
+	"gophkeeper/audit"
 	"gophkeeper/db/db"
 	"gophkeeper/pb"
-	"gophkeeper/crypto"
 	"gophkeeper/server/validation"
 )
 
-const defaultTokenDuration = time.Duration(time.Hour * 24 * 30)
+// errInvalidCredentials is the same for unknown users and wrong passwords
+// so login doesn't reveal whether the account exists.
+var errInvalidCredentials = status.Errorf(codes.Unauthenticated, "invalid username or password")
+
+// dummyPasshashValue returns the hash to check passwords of unknown users against.
+func (s *Server) dummyPasshashValue() string {
+	s.dummyPasshashOnce.Do(func() {
+		s.dummyPasshash, _ = s.hasher.Hash("gophkeeper-dummy-password")
+	})
+
+	return s.dummyPasshash
+}
 
 func (s *Server) Register(ctx context.Context, in *pb.User) (*pb.Token, error) {
 	violations := validateUser(in)
@@ -24,7 +35,7 @@ func (s *Server) Register(ctx context.Context, in *pb.User) (*pb.Token, error) {
 		return nil, validation.InvalidArgumentError(violations)
 	}
 
-	hashedPassword, err := crypto.HashPassword(in.Password)
+	hashedPassword, err := s.hasher.Hash(in.Password)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to hash password: %s", err)
 	}
@@ -40,23 +51,23 @@ func (s *Server) Register(ctx context.Context, in *pb.User) (*pb.Token, error) {
 		if pqErr, ok := err.(*pq.Error); ok {
 			switch pqErr.Code.Name() {
 			case "unique_violation":
-				return nil, status.Errorf(codes.AlreadyExists, "username already exists: %s", err)
+				// Existing user is reported as a failed login not to reveal the account exists
+				s.recordEvent(ctx, audit.EventLoginFailed, in.Name, 0, "")
+				return nil, errInvalidCredentials
 			}
 		}
 		return nil, status.Errorf(codes.Internal, "failed to create user: %s", err)
 	}
 
-	token, err := s.tm.CreateToken(
-		in.Name,
-		defaultTokenDuration,
-	)
+	pbToken, err := s.issueTokens(ctx, in.Name, "")
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to create access token")
+		return nil, err
 	}
 
+	s.recordEvent(ctx, audit.EventRegister, in.Name, 0, "")
 	s.log.Info().Msgf("new user '%s' successfully registered", in.Name)
 
-	return &pb.Token{Value: token}, nil
+	return pbToken, nil
 }
 
 func (s *Server) Login(ctx context.Context, in *pb.User) (*pb.Token, error) {
@@ -71,27 +82,169 @@ func (s *Server) Login(ctx context.Context, in *pb.User) (*pb.Token, error) {
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, status.Errorf(codes.NotFound, "user not found")
+			// Spend the same time as for the existing user not to reveal the user does not exist
+			s.hasher.Check(in.Password, s.dummyPasshashValue())
+			s.recordEvent(ctx, audit.EventLoginFailed, in.Name, 0, "")
+			return nil, errInvalidCredentials
 		}
 		return nil, status.Errorf(codes.Internal, "failed to find user")
 	}
 
-	err = crypto.CheckPassword(in.Password, dbUser.Passhash)
+	err = s.hasher.Check(in.Password, dbUser.Passhash)
 	if err != nil {
-		return nil, status.Errorf(codes.NotFound, "incorrect password")
+		s.recordEvent(ctx, audit.EventLoginFailed, in.Name, 0, "")
+		return nil, errInvalidCredentials
 	}
 
-	token, err := s.tm.CreateToken(
-		dbUser.Name,
-		defaultTokenDuration,
-	)
+	if dbUser.TotpEnabled {
+		err = s.checkSecondFactor(ctx, dbUser, in.Otp)
+		if err != nil {
+			if isLoginFailure(err) {
+				s.recordEvent(ctx, audit.EventLoginFailed, in.Name, 0, "")
+			}
+			return nil, err
+		}
+	}
+
+	if s.hasher.NeedsRehash(dbUser.Passhash) {
+		s.rehashPassword(ctx, dbUser.Name, in.Password)
+	}
+
+	pbToken, err := s.issueTokens(ctx, dbUser.Name, "")
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to create access token")
+		return nil, err
 	}
 
+	s.recordEvent(ctx, audit.EventLogin, dbUser.Name, 0, "")
 	s.log.Info().Msgf("existing user '%s' successfully logged in", in.Name)
 
-	return &pb.Token{Value: token}, nil
+	return pbToken, nil
+}
+
+// rehashPassword upgrades the password hash to the current algorithm and parameters.
+// Login doesn't fail if it can't be done - the hash is upgraded next time.
+func (s *Server) rehashPassword(ctx context.Context, username, password string) {
+	hashedPassword, err := s.hasher.Hash(password)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("failed to rehash user '%s' password", username)
+		return
+	}
+
+	err = s.storage.UpdateUserPassword(
+		ctx,
+		db.UpdateUserPasswordParams{
+			Name:     username,
+			Passhash: hashedPassword,
+		},
+	)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("failed to update user '%s' password hash", username)
+		return
+	}
+
+	s.log.Info().Msgf("user '%s' password hash upgraded", username)
+}
+
+// ChangePassword sets the new user password and revokes all the user sessions except the current one.
+func (s *Server) ChangePassword(ctx context.Context, in *pb.ChangePasswordRequest) (*emptypb.Empty, error) {
+	payload, err := payloadFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validation.ValidatePassword(in.NewPassword); err != nil {
+		violations := []*errdetails.BadRequest_FieldViolation{validation.FieldViolation("new_password", err)}
+		return nil, validation.InvalidArgumentError(violations)
+	}
+
+	err = s.checkUserPassword(ctx, payload.Username, in.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := s.hasher.Hash(in.NewPassword)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to hash password: %s", err)
+	}
+
+	err = s.storage.UpdateUserPassword(
+		ctx,
+		db.UpdateUserPasswordParams{
+			Name:     payload.Username,
+			Passhash: hashedPassword,
+		},
+	)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("failed to update user '%s' password", payload.Username)
+		return nil, status.Errorf(codes.Internal, "failed to update password")
+	}
+
+	revoked, err := s.revokeOtherSessions(ctx, payload.Username, payload.SessionID)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("failed to revoke user '%s' sessions", payload.Username)
+		return nil, status.Errorf(codes.Internal, "failed to revoke sessions")
+	}
+
+	s.recordEvent(ctx, audit.EventPasswordChanged, payload.Username, 0, "")
+	s.log.Info().Msgf("user '%s' changed password, %v other sessions revoked", payload.Username, revoked)
+
+	return &emptypb.Empty{}, nil
+}
+
+// DeleteAccount removes the user with all the user secrets, sessions and tokens.
+func (s *Server) DeleteAccount(ctx context.Context, in *pb.DeleteAccountRequest) (*emptypb.Empty, error) {
+	username, err := usernameFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.checkUserPassword(ctx, username, in.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.storage.DeleteUserWithSecrets(ctx, username)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("failed to delete user '%s'", username)
+		return nil, status.Errorf(codes.Internal, "failed to delete account")
+	}
+
+	s.recordEvent(ctx, audit.EventAccountDeleted, username, 0, "")
+	s.log.Info().Msgf("user '%s' deleted account", username)
+
+	return &emptypb.Empty{}, nil
+}
+
+// checkUserPassword re-authenticates the token user for sensitive operations.
+// Failures are limited along with the logins of the user, so a stolen token
+// doesn't allow guessing the password.
+func (s *Server) checkUserPassword(ctx context.Context, username, password string) error {
+	key := limiterKey{id: "username:" + username, limit: usernameLimit}
+
+	wait := s.limiter.allow(key)
+	if wait > 0 {
+		s.log.Warn().Msgf("user '%s' password check from '%s' throttled for %s", username, peerHost(ctx), wait)
+		return retryError(wait)
+	}
+
+	dbUser, err := s.storage.GetUser(ctx, username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return status.Errorf(codes.NotFound, "user not found")
+		}
+		return status.Errorf(codes.Internal, "failed to find user")
+	}
+
+	err = s.hasher.Check(password, dbUser.Passhash)
+	if err != nil {
+		s.limiter.fail(key)
+		s.recordEvent(ctx, audit.EventLoginFailed, username, 0, "")
+		return status.Errorf(codes.PermissionDenied, "incorrect password")
+	}
+
+	s.limiter.succeed(key)
+
+	return nil
 }
 
 func validateUser(user *pb.User) (violations []*errdetails.BadRequest_FieldViolation) {
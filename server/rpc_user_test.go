@@ -2,30 +2,37 @@ package server
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"gophkeeper/audit"
 	"gophkeeper/db/db"
 	"gophkeeper/db/mock"
 	"gophkeeper/pb"
 	"gophkeeper/random"
 	"gophkeeper/crypto"
-	"gophkeeper/token"
 )
 
 var (
 	testUsername = random.RandomOwner()
 	testPassword = random.RandomString(20)
+
+	testUsername6 = random.RandomOwner()
 )
 
 func TestRPCRegister(t *testing.T) {
 	// Create mock storage
 	controller := gomock.NewController(t)
-	mockStorage := mock.NewMockQuerier(controller)
+	mockStorage := mock.NewMockStore(controller)
+	allowAuditEvents(mockStorage)
 
 	mockStorage.EXPECT().
 		CreateUser(
@@ -35,11 +42,29 @@ func TestRPCRegister(t *testing.T) {
 		Times(1).
 		Return(db.User{}, nil)
 
+	mockStorage.EXPECT().
+		CreateSession(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(1).
+		Return(db.Session{}, nil)
+
+	mockStorage.EXPECT().
+		CreateRefreshToken(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(1).
+		Return(db.RefreshToken{}, nil)
+
 	// Create server
 	testServer := &Server{
 		config:  Config{},
 		storage: mockStorage,
-		tm:      token.NewPasetoMaker(),
+		tm:      newTestMaker(t),
+		hasher:  crypto.DefaultPasswordHasher,
+		limiter: newLoginLimiter(),
 	}
 
 	// Run test gRPC server
@@ -54,6 +79,7 @@ func TestRPCRegister(t *testing.T) {
 	require.NoError(t, err)
 
 	require.Equal(t, payload.Username, testUsername)
+	require.NotEmpty(t, pbToken.Refresh)
 
 	// Test invalid (too short) username and password
 	pbToken, err = client.Register(context.Background(), &pb.User{Name: "bo", Password: "bla"})
@@ -63,27 +89,82 @@ func TestRPCRegister(t *testing.T) {
 	require.Equal(t, codes.InvalidArgument, e.Code())
 }
 
+func TestRPCRegisterExisting(t *testing.T) {
+	// Create mock storage
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockStore(controller)
+	allowAuditEvents(mockStorage)
+
+	mockStorage.EXPECT().
+		CreateUser(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(1).
+		Return(db.User{}, &pq.Error{Code: "23505"})
+
+	// Create server
+	testServer := &Server{
+		config:  Config{},
+		storage: mockStorage,
+		tm:      newTestMaker(t),
+		hasher:  crypto.DefaultPasswordHasher,
+		limiter: newLoginLimiter(),
+	}
+
+	// Run test gRPC server
+	client, closer := runTestServer(testServer)
+	defer closer()
+
+	// Existing user is not revealed
+	_, err := client.Register(context.Background(), &pb.User{Name: testUsername, Password: testPassword})
+	require.Error(t, err)
+
+	e, _ := status.FromError(err)
+	require.Equal(t, codes.Unauthenticated, e.Code())
+	require.Equal(t, "invalid username or password", e.Message())
+}
+
 func TestRPCLogin(t *testing.T) {
 	testUserPasshash, err := crypto.HashPassword(testPassword)
 	require.NoError(t, err)
 
 	// Create mock storage
 	controller := gomock.NewController(t)
-	mockStorage := mock.NewMockQuerier(controller)
+	mockStorage := mock.NewMockStore(controller)
+	allowAuditEvents(mockStorage)
 
 	mockStorage.EXPECT().
 		GetUser(
 			gomock.Any(),
 			gomock.Any(),
 		).
-		Times(1).
+		Times(2).
 		Return(db.User{Name: testUsername, Passhash: testUserPasshash}, nil)
 
+	mockStorage.EXPECT().
+		CreateSession(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(1).
+		Return(db.Session{}, nil)
+
+	mockStorage.EXPECT().
+		CreateRefreshToken(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(1).
+		Return(db.RefreshToken{}, nil)
+
 	// Create server
 	testServer := &Server{
 		config:  Config{},
 		storage: mockStorage,
-		tm:      token.NewPasetoMaker(),
+		tm:      newTestMaker(t),
+		hasher:  crypto.DefaultPasswordHasher,
+		limiter: newLoginLimiter(),
 	}
 
 	// Run test gRPC server
@@ -98,11 +179,274 @@ func TestRPCLogin(t *testing.T) {
 	require.NoError(t, err)
 
 	require.Equal(t, payload.Username, testUsername)
+	require.NotEmpty(t, pbToken.Refresh)
+
+	// Test incorrect password
+	_, err = client.Login(context.Background(), &pb.User{Name: testUsername, Password: "incorrect"})
+	require.Error(t, err)
+
+	e, _ := status.FromError(err)
+	require.Equal(t, codes.Unauthenticated, e.Code())
+	require.Equal(t, "invalid username or password", e.Message())
 
 	// Test invalid (too short) username and password
 	pbToken, err = client.Login(context.Background(), &pb.User{Name: "bo", Password: "bla"})
 	require.Error(t, err)
 
+	e, _ = status.FromError(err)
+	require.Equal(t, codes.InvalidArgument, e.Code())
+}
+
+func TestRPCChangePassword(t *testing.T) {
+	testUserPasshash, err := crypto.HashPassword(testPassword)
+	require.NoError(t, err)
+
+	newPassword := random.RandomString(20)
+
+	// Create mock storage
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockStore(controller)
+	allowAuditEvents(mockStorage)
+
+	mockStorage.EXPECT().
+		GetUser(
+			gomock.Any(),
+			testUsername6,
+		).
+		Times(2).
+		Return(db.User{Name: testUsername6, Passhash: testUserPasshash}, nil)
+
+	mockStorage.EXPECT().
+		UpdateUserPassword(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.UpdateUserPasswordParams) error {
+			require.Equal(t, testUsername6, arg.Name)
+			require.NoError(t, crypto.CheckPassword(newPassword, arg.Passhash))
+			return nil
+		})
+
+	mockStorage.EXPECT().
+		RevokeOtherSessions(
+			gomock.Any(),
+			db.RevokeOtherSessionsParams{
+				Username: testUsername6,
+				ID:       testSessionID,
+			},
+		).
+		Times(1).
+		Return(int64(2), nil)
+
+	mockStorage.EXPECT().
+		RevokeOtherRefreshTokens(
+			gomock.Any(),
+			db.RevokeOtherRefreshTokensParams{
+				Username: testUsername6,
+				Family:   testSessionID,
+			},
+		).
+		Times(1).
+		Return(nil)
+
+	// Create server
+	testServer := &Server{
+		config:  Config{},
+		storage: mockStorage,
+		tm:      newTestMaker(t),
+		hasher:  crypto.DefaultPasswordHasher,
+		limiter: newLoginLimiter(),
+	}
+
+	// Run test gRPC server
+	client, closer := runTestServer(testServer, grpc.UnaryInterceptor(testServer.checkAuth))
+	defer closer()
+
+	ctx := newAuthContext(t, testServer.tm, mockStorage, testUsername6)
+
+	// Test valid old password
+	_, err = client.ChangePassword(ctx, &pb.ChangePasswordRequest{Password: testPassword, NewPassword: newPassword})
+	require.NoError(t, err)
+
+	// Test incorrect old password
+	_, err = client.ChangePassword(ctx, &pb.ChangePasswordRequest{Password: "incorrect", NewPassword: newPassword})
+	require.Error(t, err)
+
 	e, _ := status.FromError(err)
+	require.Equal(t, codes.PermissionDenied, e.Code())
+
+	// Test invalid (too short) new password
+	_, err = client.ChangePassword(ctx, &pb.ChangePasswordRequest{Password: testPassword, NewPassword: "bla"})
+	require.Error(t, err)
+
+	e, _ = status.FromError(err)
 	require.Equal(t, codes.InvalidArgument, e.Code())
 }
+
+func TestRPCDeleteAccount(t *testing.T) {
+	testUserPasshash, err := crypto.HashPassword(testPassword)
+	require.NoError(t, err)
+
+	// Create mock storage
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockStore(controller)
+	allowAuditEvents(mockStorage)
+
+	mockStorage.EXPECT().
+		GetUser(
+			gomock.Any(),
+			testUsername6,
+		).
+		Times(2).
+		Return(db.User{Name: testUsername6, Passhash: testUserPasshash}, nil)
+
+	mockStorage.EXPECT().
+		DeleteUserWithSecrets(
+			gomock.Any(),
+			testUsername6,
+		).
+		Times(1).
+		Return(nil)
+
+	// Create server
+	testServer := &Server{
+		config:  Config{},
+		storage: mockStorage,
+		tm:      newTestMaker(t),
+		hasher:  crypto.DefaultPasswordHasher,
+		limiter: newLoginLimiter(),
+	}
+
+	// Run test gRPC server
+	client, closer := runTestServer(testServer, grpc.UnaryInterceptor(testServer.checkAuth))
+	defer closer()
+
+	ctx := newAuthContext(t, testServer.tm, mockStorage, testUsername6)
+
+	// Test incorrect password
+	_, err = client.DeleteAccount(ctx, &pb.DeleteAccountRequest{Password: "incorrect"})
+	require.Error(t, err)
+
+	e, _ := status.FromError(err)
+	require.Equal(t, codes.PermissionDenied, e.Code())
+
+	// Test valid password
+	_, err = client.DeleteAccount(ctx, &pb.DeleteAccountRequest{Password: testPassword})
+	require.NoError(t, err)
+}
+
+func TestRPCLoginRehash(t *testing.T) {
+	bcryptPasshash, err := crypto.BcryptHasher{Cost: bcrypt.MinCost}.Hash(testPassword)
+	require.NoError(t, err)
+
+	// Create mock storage
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockStore(controller)
+	allowAuditEvents(mockStorage)
+
+	mockStorage.EXPECT().
+		GetUser(
+			gomock.Any(),
+			testUsername,
+		).
+		Times(1).
+		Return(db.User{Name: testUsername, Passhash: bcryptPasshash}, nil)
+
+	// Old bcrypt hash is upgraded to argon2id
+	mockStorage.EXPECT().
+		UpdateUserPassword(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.UpdateUserPasswordParams) error {
+			require.Equal(t, testUsername, arg.Name)
+			require.True(t, strings.HasPrefix(arg.Passhash, "$argon2id$"))
+			require.NoError(t, crypto.CheckPassword(testPassword, arg.Passhash))
+			return nil
+		})
+
+	mockStorage.EXPECT().
+		CreateSession(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(1).
+		Return(db.Session{}, nil)
+
+	mockStorage.EXPECT().
+		CreateRefreshToken(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(1).
+		Return(db.RefreshToken{}, nil)
+
+	// Create server
+	testServer := &Server{
+		config:  Config{},
+		storage: mockStorage,
+		tm:      newTestMaker(t),
+		hasher:  crypto.DefaultPasswordHasher,
+		limiter: newLoginLimiter(),
+	}
+
+	// Run test gRPC server
+	client, closer := runTestServer(testServer)
+	defer closer()
+
+	_, err = client.Login(context.Background(), &pb.User{Name: testUsername, Password: testPassword})
+	require.NoError(t, err)
+}
+
+func TestCheckUserPasswordLimit(t *testing.T) {
+	testName := random.RandomOwner()
+	testUserPasshash, err := crypto.HashPassword(testPassword)
+	require.NoError(t, err)
+
+	// Create mock storage
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockStore(controller)
+	allowTx(mockStorage)
+
+	mockStorage.EXPECT().
+		GetUser(gomock.Any(), testName).
+		Times(usernameLimit.maxFailures).
+		Return(db.User{Name: testName, Passhash: testUserPasshash}, nil)
+
+	mockStorage.EXPECT().
+		LockAuditHead(gomock.Any()).
+		AnyTimes().
+		Return(db.AuditHead{}, nil)
+
+	// Every wrong password is recorded as a failed login
+	mockStorage.EXPECT().
+		CreateAuditEvent(gomock.Any(), gomock.Any()).
+		Times(usernameLimit.maxFailures).
+		DoAndReturn(func(_ context.Context, arg db.CreateAuditEventParams) (db.AuditEvent, error) {
+			require.Equal(t, audit.EventLoginFailed, arg.Type)
+			require.Equal(t, testName, arg.Username)
+			return db.AuditEvent{}, nil
+		})
+
+	mockStorage.EXPECT().
+		UpdateAuditHead(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		Return(nil)
+
+	testServer := &Server{
+		storage: mockStorage,
+		hasher:  crypto.DefaultPasswordHasher,
+		limiter: newLoginLimiter(),
+	}
+
+	for i := 0; i < usernameLimit.maxFailures; i++ {
+		err := testServer.checkUserPassword(context.Background(), testName, "incorrect")
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+	}
+
+	// The user is locked out even with the right password
+	err = testServer.checkUserPassword(context.Background(), testName, testPassword)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
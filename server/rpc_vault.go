@@ -0,0 +1,287 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"gophkeeper/audit"
+	"gophkeeper/converter"
+	"gophkeeper/db/db"
+	"gophkeeper/pb"
+	"gophkeeper/server/validation"
+)
+
+var (
+	// errVaultKeyChanged is returned when the vault key to replace is not the current one.
+	errVaultKeyChanged = errors.New("vault key was changed")
+	// errSecretsChanged is returned when the rewrapped secrets are not the current ones.
+	errSecretsChanged = errors.New("secrets were changed")
+	// errInvalidVersion is returned for the rewrapped version without a value or time.
+	errInvalidVersion = errors.New("invalid secret version")
+)
+
+// GetVaultKey returns the user master password key derivation params.
+func (s *Server) GetVaultKey(ctx context.Context, in *emptypb.Empty) (*pb.VaultKey, error) {
+	username, err := usernameFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	vaultKey, err := s.storage.GetVaultKey(ctx, username)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Error(codes.NotFound, "vault key is not set up")
+	}
+	if err != nil {
+		s.log.Error().Err(err).Msgf("failed to get user '%s' vault key", username)
+		return nil, status.Error(codes.Internal, "failed to get vault key from db")
+	}
+
+	return converter.DBVaultKeyToPBVaultKey(vaultKey), nil
+}
+
+// CreateVaultKey saves the key derivation params made by the first user device.
+// The params are never replaced - a device that was late gets the stored ones.
+func (s *Server) CreateVaultKey(ctx context.Context, in *pb.VaultKey) (*pb.VaultKey, error) {
+	username, err := usernameFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if violations := validateVaultKey(in); violations != nil {
+		return nil, validation.InvalidArgumentError(violations)
+	}
+
+	created, err := s.storage.CreateVaultKey(
+		ctx,
+		db.CreateVaultKeyParams{
+			Owner:       username,
+			Salt:        in.Salt,
+			Memory:      int64(in.Memory),
+			Iterations:  int64(in.Iterations),
+			Parallelism: int32(in.Parallelism),
+			KeyCheck:    in.Check,
+		},
+	)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("failed to create user '%s' vault key", username)
+		return nil, status.Error(codes.Internal, "failed to save vault key to db")
+	}
+
+	if created > 0 {
+		s.log.Info().Msgf("created user '%s' vault key", username)
+		s.recordEvent(ctx, audit.EventVaultKeyCreated, username, 0, "")
+	}
+
+	return s.GetVaultKey(ctx, &emptypb.Empty{})
+}
+
+// Rekey replaces the user vault key with the one derived from the new master password.
+// The client rewraps the data keys of all the current secrets and their versions,
+// they are replaced along with the key in a single transaction. Secrets get new revisions
+// so the other devices pull them. Tombstones are rewrapped if the client keeps them
+// in the trash, the values of the others are dropped.
+func (s *Server) Rekey(ctx context.Context, in *pb.RekeyRequest) (*emptypb.Empty, error) {
+	username, err := usernameFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.VaultKey == nil {
+		return nil, status.Error(codes.InvalidArgument, "vault key is required")
+	}
+	if violations := validateVaultKey(in.VaultKey); violations != nil {
+		return nil, validation.InvalidArgumentError(violations)
+	}
+	if violations := validateRekey(in); violations != nil {
+		return nil, validation.InvalidArgumentError(violations)
+	}
+
+	// The key check is readable with the token, rewrapping the vault takes the password
+	err = s.checkUserPassword(ctx, username, in.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.storage.WithTx(ctx, func(q db.Querier) error {
+		err := q.LockUserSecrets(ctx, username)
+		if err != nil {
+			return fmt.Errorf("failed to lock user '%s' secrets: %w", username, err)
+		}
+
+		vaultKey, err := q.GetVaultKey(ctx, username)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errVaultKeyChanged
+		}
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(vaultKey.KeyCheck, in.Check) {
+			return errVaultKeyChanged
+		}
+
+		secrets, err := q.GetSecretsByUser(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		current := 0
+		// Trashed secrets keep their versions too
+		existing := map[secretKey]bool{}
+		for _, secret := range secrets {
+			if !secret.Deleted {
+				current++
+			}
+			existing[secretKey{secret.Kind, secret.Name}] = true
+		}
+
+		rewrapping := 0
+		for _, secret := range in.Secrets {
+			if !secret.Deleted {
+				rewrapping++
+			}
+		}
+		if current != rewrapping {
+			return errSecretsChanged
+		}
+
+		// Tombstones the client doesn't keep in the trash can't be rewrapped
+		err = q.ClearSecretTombstones(ctx, username)
+		if err != nil {
+			return fmt.Errorf("failed to clear user '%s' tombstones: %w", username, err)
+		}
+
+		for _, secret := range in.Secrets {
+			rewrapped, err := q.RewrapSecret(
+				ctx,
+				db.RewrapSecretParams{
+					Owner:        username,
+					Kind:         secret.Kind,
+					Name:         secret.Name,
+					Value:        secret.Value,
+					BaseRevision: secret.Revision,
+					Deleted:      secret.Deleted,
+				},
+			)
+			if err != nil {
+				return fmt.Errorf("failed to rewrap secret '%s': %w", secret.Name, err)
+			}
+			// Tombstone may be purged since the client pulled it
+			if rewrapped == 0 && !secret.Deleted {
+				return errSecretsChanged
+			}
+		}
+
+		err = q.DeleteSecretVersions(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		for _, version := range in.Versions {
+			if !existing[secretKey{version.Kind, version.Name}] {
+				return errSecretsChanged
+			}
+
+			err = q.AddSecretVersion(
+				ctx,
+				db.AddSecretVersionParams{
+					Owner:    username,
+					Kind:     version.Kind,
+					Name:     version.Name,
+					Value:    version.Version.Value,
+					Modified: version.Version.Modified.AsTime(),
+					Revision: version.Version.Revision,
+				},
+			)
+			if err != nil {
+				return fmt.Errorf("failed to save secret '%s' version: %w", version.Name, err)
+			}
+		}
+
+		updated, err := q.UpdateVaultKey(
+			ctx,
+			db.UpdateVaultKeyParams{
+				Owner:       username,
+				Salt:        in.VaultKey.Salt,
+				Memory:      int64(in.VaultKey.Memory),
+				Iterations:  int64(in.VaultKey.Iterations),
+				Parallelism: int32(in.VaultKey.Parallelism),
+				KeyCheck:    in.VaultKey.Check,
+				OldKeyCheck: in.Check,
+			},
+		)
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return errVaultKeyChanged
+		}
+
+		return nil
+	})
+	if errors.Is(err, errVaultKeyChanged) {
+		return nil, status.Error(codes.FailedPrecondition, "vault key was changed, unlock the vault again")
+	}
+	if errors.Is(err, errSecretsChanged) {
+		return nil, status.Error(codes.Aborted, "secrets were changed, sync and retry")
+	}
+	if err != nil {
+		s.log.Error().Err(err).Msgf("failed to rekey user '%s' vault", username)
+		return nil, status.Error(codes.Internal, "failed to rekey vault")
+	}
+
+	s.recordEvent(ctx, audit.EventVaultRekeyed, username, 0, "")
+
+	s.log.Info().Msgf("rekeyed user '%s' vault with %v secrets", username, len(in.Secrets))
+
+	return &emptypb.Empty{}, nil
+}
+
+func validateVaultKey(vaultKey *pb.VaultKey) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateSalt(vaultKey.Salt); err != nil {
+		violations = append(violations, validation.FieldViolation("salt", err))
+	}
+
+	if err := validation.ValidateKeyParams(vaultKey.Memory, vaultKey.Iterations, vaultKey.Parallelism); err != nil {
+		violations = append(violations, validation.FieldViolation("params", err))
+	}
+
+	if err := validation.ValidateKeyCheck(vaultKey.Check); err != nil {
+		violations = append(violations, validation.FieldViolation("check", err))
+	}
+
+	return violations
+}
+
+// secretKey identifies the user secret.
+type secretKey struct {
+	kind int32
+	name string
+}
+
+// validateRekey checks the rewrapped secrets and versions can be saved.
+func validateRekey(in *pb.RekeyRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	for _, secret := range in.Secrets {
+		if err := validateSecret(secret); err != nil {
+			violations = append(violations, validation.FieldViolation("secrets", err))
+		}
+	}
+
+	for _, version := range in.Versions {
+		if err := validation.ValidateSecretName(version.Name); err != nil {
+			violations = append(violations, validation.FieldViolation("versions", err))
+		}
+		if version.Version == nil || !version.Version.Modified.IsValid() {
+			violations = append(violations, validation.FieldViolation("versions", errInvalidVersion))
+		}
+	}
+
+	return violations
+}
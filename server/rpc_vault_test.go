@@ -0,0 +1,393 @@
+package server
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"gophkeeper/crypto"
+	"gophkeeper/db/db"
+	"gophkeeper/db/mock"
+	"gophkeeper/pb"
+	"gophkeeper/random"
+)
+
+var testUsername10 = random.RandomOwner()
+
+func newTestVaultKey() *pb.VaultKey {
+	return &pb.VaultKey{
+		Salt:        []byte(random.RandomString(16)),
+		Memory:      64 * 1024,
+		Iterations:  3,
+		Parallelism: 4,
+		Check:       []byte(random.RandomString(32)),
+	}
+}
+
+func TestRPCGetVaultKey(t *testing.T) {
+	vaultKey := newTestVaultKey()
+
+	// Create mock storage
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockStore(controller)
+
+	gomock.InOrder(
+		mockStorage.EXPECT().
+			GetVaultKey(gomock.Any(), testUsername10).
+			Times(1).
+			Return(db.VaultKey{}, sql.ErrNoRows),
+		mockStorage.EXPECT().
+			GetVaultKey(gomock.Any(), testUsername10).
+			Times(1).
+			Return(
+				db.VaultKey{
+					Owner:       testUsername10,
+					Salt:        vaultKey.Salt,
+					Memory:      int64(vaultKey.Memory),
+					Iterations:  int64(vaultKey.Iterations),
+					Parallelism: int32(vaultKey.Parallelism),
+					KeyCheck:    vaultKey.Check,
+				},
+				nil,
+			),
+	)
+
+	// Create server
+	testServer := &Server{
+		storage: mockStorage,
+		tm:      newTestMaker(t),
+	}
+
+	// Run test gRPC server
+	client, closer := runTestServer(testServer, grpc.UnaryInterceptor(testServer.checkAuth))
+	defer closer()
+
+	ctx := newAuthContext(t, testServer.tm, mockStorage, testUsername10)
+
+	_, err := client.GetVaultKey(ctx, &emptypb.Empty{})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	stored, err := client.GetVaultKey(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	require.Equal(t, vaultKey.Salt, stored.Salt)
+	require.Equal(t, vaultKey.Check, stored.Check)
+}
+
+func TestRPCCreateVaultKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		vaultKey *pb.VaultKey
+		calls    int
+		created  int64
+		events   int
+		code     codes.Code
+	}{
+		{
+			name:     "first device",
+			vaultKey: newTestVaultKey(),
+			calls:    1,
+			created:  1,
+			events:   1,
+			code:     codes.OK,
+		},
+		{
+			name:     "late device",
+			vaultKey: newTestVaultKey(),
+			calls:    1,
+			code:     codes.OK,
+		},
+		{
+			name:     "short salt",
+			vaultKey: &pb.VaultKey{Salt: []byte("salt"), Memory: 64 * 1024, Iterations: 3, Parallelism: 4, Check: []byte(random.RandomString(32))},
+			code:     codes.InvalidArgument,
+		},
+		{
+			name:     "no params",
+			vaultKey: &pb.VaultKey{Salt: []byte(random.RandomString(16)), Check: []byte(random.RandomString(32))},
+			code:     codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := newTestVaultKey()
+			if tt.created > 0 {
+				stored = tt.vaultKey
+			}
+
+			// Create mock storage
+			controller := gomock.NewController(t)
+			mockStorage := mock.NewMockStore(controller)
+
+			mockStorage.EXPECT().
+				CreateVaultKey(
+					gomock.Any(),
+					gomock.Any(),
+				).
+				Times(tt.calls).
+				DoAndReturn(func(_ interface{}, params db.CreateVaultKeyParams) (int64, error) {
+					require.Equal(t, testUsername10, params.Owner)
+					require.Equal(t, tt.vaultKey.Salt, params.Salt)
+					return tt.created, nil
+				})
+
+			mockStorage.EXPECT().
+				GetVaultKey(gomock.Any(), testUsername10).
+				Times(tt.calls).
+				Return(db.VaultKey{Owner: testUsername10, Salt: stored.Salt, KeyCheck: stored.Check}, nil)
+
+			allowTx(mockStorage)
+
+			mockStorage.EXPECT().
+				LockAuditHead(gomock.Any()).
+				Times(tt.events).
+				Return(db.AuditHead{}, nil)
+
+			mockStorage.EXPECT().
+				CreateAuditEvent(gomock.Any(), gomock.Any()).
+				Times(tt.events).
+				Return(db.AuditEvent{}, nil)
+
+			mockStorage.EXPECT().
+				UpdateAuditHead(gomock.Any(), gomock.Any()).
+				Times(tt.events).
+				Return(nil)
+
+			// Create server
+			testServer := &Server{
+				storage: mockStorage,
+				tm:      newTestMaker(t),
+			}
+
+			// Run test gRPC server
+			client, closer := runTestServer(testServer, grpc.UnaryInterceptor(testServer.checkAuth))
+			defer closer()
+
+			ctx := newAuthContext(t, testServer.tm, mockStorage, testUsername10)
+
+			vaultKey, err := client.CreateVaultKey(ctx, tt.vaultKey)
+			require.Equal(t, tt.code, status.Code(err))
+			if tt.code == codes.OK {
+				require.Equal(t, stored.Salt, vaultKey.Salt)
+			}
+		})
+	}
+}
+
+func TestRPCRekey(t *testing.T) {
+	testName := random.RandomString(10)
+	oldKey := newTestVaultKey()
+	newKey := newTestVaultKey()
+
+	testUserPasshash, err := crypto.HashPassword(testPassword)
+	require.NoError(t, err)
+
+	// Create mock storage
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockStore(controller)
+	allowTx(mockStorage)
+	allowAuditEvents(mockStorage)
+
+	mockStorage.EXPECT().
+		GetUser(gomock.Any(), testUsername10).
+		AnyTimes().
+		Return(db.User{Name: testUsername10, Passhash: testUserPasshash}, nil)
+
+	mockStorage.EXPECT().
+		LockUserSecrets(gomock.Any(), testUsername10).
+		AnyTimes().
+		Return(nil)
+
+	mockStorage.EXPECT().
+		GetVaultKey(gomock.Any(), testUsername10).
+		AnyTimes().
+		Return(db.VaultKey{Owner: testUsername10, Salt: oldKey.Salt, KeyCheck: oldKey.Check}, nil)
+
+	mockStorage.EXPECT().
+		GetSecretsByUser(gomock.Any(), testUsername10).
+		Times(3).
+		Return(
+			[]db.Secret{
+				{Owner: testUsername10, Kind: 1, Name: testName, Value: []byte("old"), Revision: 5},
+				{Owner: testUsername10, Kind: 1, Name: "deleted", Deleted: true, Revision: 6},
+			},
+			nil,
+		)
+
+	mockStorage.EXPECT().
+		ClearSecretTombstones(gomock.Any(), testUsername10).
+		Times(2).
+		Return(nil)
+
+	mockStorage.EXPECT().
+		RewrapSecret(
+			gomock.Any(),
+			db.RewrapSecretParams{
+				Owner:        testUsername10,
+				Kind:         1,
+				Name:         testName,
+				Value:        []byte("rewrapped"),
+				BaseRevision: 5,
+			},
+		).
+		Times(2).
+		Return(int64(1), nil)
+
+	// Trashed secret is rewrapped, the one purged meanwhile is skipped
+	mockStorage.EXPECT().
+		RewrapSecret(
+			gomock.Any(),
+			db.RewrapSecretParams{
+				Owner:        testUsername10,
+				Kind:         1,
+				Name:         "deleted",
+				Value:        []byte("rewrapped"),
+				BaseRevision: 6,
+				Deleted:      true,
+			},
+		).
+		Times(1).
+		Return(int64(1), nil)
+
+	mockStorage.EXPECT().
+		RewrapSecret(
+			gomock.Any(),
+			db.RewrapSecretParams{
+				Owner:        testUsername10,
+				Kind:         1,
+				Name:         "purged",
+				Value:        []byte("rewrapped"),
+				BaseRevision: 4,
+				Deleted:      true,
+			},
+		).
+		Times(1).
+		Return(int64(0), nil)
+
+	mockStorage.EXPECT().
+		DeleteSecretVersions(gomock.Any(), testUsername10).
+		Times(2).
+		Return(nil)
+
+	mockStorage.EXPECT().
+		AddSecretVersion(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ interface{}, params db.AddSecretVersionParams) error {
+			require.Equal(t, testName, params.Name)
+			require.Equal(t, []byte("version"), params.Value)
+			require.Equal(t, int64(3), params.Revision)
+			return nil
+		})
+
+	mockStorage.EXPECT().
+		UpdateVaultKey(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ interface{}, params db.UpdateVaultKeyParams) (int64, error) {
+			require.Equal(t, newKey.Salt, params.Salt)
+			require.Equal(t, newKey.Check, params.KeyCheck)
+			require.Equal(t, oldKey.Check, params.OldKeyCheck)
+			return 1, nil
+		})
+
+	// Create server
+	testServer := &Server{
+		storage: mockStorage,
+		tm:      newTestMaker(t),
+		hasher:  crypto.DefaultPasswordHasher,
+		limiter: newLoginLimiter(),
+	}
+
+	// Run test gRPC server
+	client, closer := runTestServer(testServer, grpc.UnaryInterceptor(testServer.checkAuth))
+	defer closer()
+
+	ctx := newAuthContext(t, testServer.tm, mockStorage, testUsername10)
+
+	secrets := []*pb.Secret{
+		{Owner: testUsername10, Kind: 1, Name: testName, Value: []byte("rewrapped"), Modified: timestamppb.Now(), Revision: 5},
+	}
+	versions := []*pb.RewrappedVersion{
+		{Kind: 1, Name: testName, Version: &pb.SecretVersion{Value: []byte("version"), Modified: timestamppb.Now(), Revision: 3}},
+	}
+
+	// Key check alone doesn't rekey the vault
+	_, err = client.Rekey(
+		ctx,
+		&pb.RekeyRequest{Check: oldKey.Check, VaultKey: newKey, Secrets: secrets, Password: "incorrect"},
+	)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Vault key was replaced by another device
+	_, err = client.Rekey(
+		ctx,
+		&pb.RekeyRequest{Check: newKey.Check, VaultKey: newKey, Secrets: secrets, Password: testPassword},
+	)
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// Secret was added after the client sync
+	_, err = client.Rekey(ctx, &pb.RekeyRequest{Check: oldKey.Check, VaultKey: newKey, Password: testPassword})
+	require.Equal(t, codes.Aborted, status.Code(err))
+
+	// Version of a secret the user doesn't have
+	_, err = client.Rekey(
+		ctx,
+		&pb.RekeyRequest{
+			Check:    oldKey.Check,
+			VaultKey: newKey,
+			Secrets:  secrets,
+			Versions: []*pb.RewrappedVersion{{Kind: 2, Name: testName, Version: versions[0].Version}},
+			Password: testPassword,
+		},
+	)
+	require.Equal(t, codes.Aborted, status.Code(err))
+
+	// Invalid new key
+	_, err = client.Rekey(
+		ctx,
+		&pb.RekeyRequest{Check: oldKey.Check, VaultKey: &pb.VaultKey{}, Secrets: secrets, Password: testPassword},
+	)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Invalid secrets and versions
+	_, err = client.Rekey(
+		ctx,
+		&pb.RekeyRequest{
+			Check:    oldKey.Check,
+			VaultKey: newKey,
+			Secrets:  []*pb.Secret{{Kind: 1, Modified: timestamppb.Now()}},
+			Password: testPassword,
+		},
+	)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.Rekey(
+		ctx,
+		&pb.RekeyRequest{
+			Check:    oldKey.Check,
+			VaultKey: newKey,
+			Secrets:  secrets,
+			Versions: []*pb.RewrappedVersion{{Kind: 1, Name: testName}},
+			Password: testPassword,
+		},
+	)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	trashed := []*pb.Secret{
+		secrets[0],
+		{Owner: testUsername10, Kind: 1, Name: "deleted", Value: []byte("rewrapped"), Modified: timestamppb.Now(), Deleted: true, Revision: 6},
+		{Owner: testUsername10, Kind: 1, Name: "purged", Value: []byte("rewrapped"), Modified: timestamppb.Now(), Deleted: true, Revision: 4},
+	}
+
+	_, err = client.Rekey(
+		ctx,
+		&pb.RekeyRequest{Check: oldKey.Check, VaultKey: newKey, Secrets: trashed, Versions: versions, Password: testPassword},
+	)
+	require.NoError(t, err)
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"gophkeeper/audit"
+	"gophkeeper/converter"
+	"gophkeeper/db/db"
+	"gophkeeper/pb"
+)
+
+// errVersionNotFound is returned when the secret or its version to restore doesn't exist.
+var errVersionNotFound = errors.New("secret version not found")
+
+// ListSecretVersions returns the latest previous values of the user secret.
+func (s *Server) ListSecretVersions(ctx context.Context, in *pb.SecretVersionsRequest) (*pb.SecretVersions, error) {
+	username, err := usernameFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := s.storage.GetSecretVersions(
+		ctx,
+		db.GetSecretVersionsParams{
+			Owner: username,
+			Kind:  in.Kind,
+			Name:  in.Name,
+			Limit: s.config.Versions,
+		},
+	)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("failed to get user '%s' secret '%s' versions", username, in.Name)
+		return nil, status.Error(codes.Internal, "failed to get secret versions from db")
+	}
+
+	pbVersions := []*pb.SecretVersion{}
+	for _, version := range versions {
+		pbVersions = append(pbVersions, converter.DBSecretVersionToPBSecretVersion(version))
+	}
+
+	s.log.Info().Msgf("sent user '%s' %v secret '%s' versions", username, len(pbVersions), in.Name)
+
+	return &pb.SecretVersions{Versions: pbVersions}, nil
+}
+
+// RestoreSecretVersion replaces the user secret value with the previous one.
+// The replaced value becomes a version as well so the restore can be undone.
+func (s *Server) RestoreSecretVersion(ctx context.Context, in *pb.RestoreVersionRequest) (*pb.Secret, error) {
+	username, err := usernameFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var restored db.Secret
+	err = s.storage.WithTx(ctx, func(q db.Querier) error {
+		err := q.LockUserSecrets(ctx, username)
+		if err != nil {
+			return fmt.Errorf("failed to lock user '%s' secrets: %w", username, err)
+		}
+
+		version, err := q.GetSecretVersion(
+			ctx,
+			db.GetSecretVersionParams{
+				Owner:    username,
+				Kind:     in.Kind,
+				Name:     in.Name,
+				Revision: in.Revision,
+			},
+		)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errVersionNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		current, err := q.GetSecret(
+			ctx,
+			db.GetSecretParams{
+				Owner: username,
+				Kind:  in.Kind,
+				Name:  in.Name,
+			},
+		)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errVersionNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		restored, err = q.UpdateSecret(
+			ctx,
+			db.UpdateSecretParams{
+				Owner:        username,
+				Kind:         in.Kind,
+				Name:         in.Name,
+				Value:        version.Value,
+				Modified:     time.Now(),
+				Deleted:      false,
+				BaseRevision: current.Revision,
+			},
+		)
+		return err
+	})
+	if errors.Is(err, errVersionNotFound) {
+		return nil, status.Error(codes.NotFound, "secret version not found")
+	}
+	if err != nil {
+		s.log.Error().Err(err).Msgf("failed to restore user '%s' secret '%s'", username, in.Name)
+		return nil, status.Error(codes.Internal, "failed to restore secret version")
+	}
+
+	s.recordEvent(ctx, audit.EventSecretRestored, username, in.Kind, in.Name)
+
+	s.log.Info().Msgf("user '%s' secret '%s' restored to revision %v", username, in.Name, in.Revision)
+
+	return converter.DBSecretToPBSecret(restored), nil
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"gophkeeper/db/db"
+	"gophkeeper/db/mock"
+	"gophkeeper/pb"
+	"gophkeeper/random"
+)
+
+var testUsername9 = random.RandomOwner()
+
+func TestRPCListSecretVersions(t *testing.T) {
+	testName := random.RandomString(10)
+
+	// Create mock storage
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockStore(controller)
+
+	mockStorage.EXPECT().
+		GetSecretVersions(
+			gomock.Any(),
+			db.GetSecretVersionsParams{
+				Owner: testUsername9,
+				Kind:  1,
+				Name:  testName,
+				Limit: 5,
+			},
+		).
+		Times(1).
+		Return(
+			[]db.SecretVersion{
+				{Owner: testUsername9, Kind: 1, Name: testName, Value: []byte("second"), Revision: 7},
+				{Owner: testUsername9, Kind: 1, Name: testName, Value: []byte("first"), Revision: 3},
+			},
+			nil,
+		)
+
+	// Create server
+	testServer := &Server{
+		config:  Config{Versions: 5},
+		storage: mockStorage,
+		tm:      newTestMaker(t),
+	}
+
+	// Run test gRPC server
+	client, closer := runTestServer(testServer, grpc.UnaryInterceptor(testServer.checkAuth))
+	defer closer()
+
+	ctx := newAuthContext(t, testServer.tm, mockStorage, testUsername9)
+
+	versions, err := client.ListSecretVersions(ctx, &pb.SecretVersionsRequest{Kind: 1, Name: testName})
+	require.NoError(t, err)
+	require.Len(t, versions.Versions, 2)
+	require.Equal(t, []byte("second"), versions.Versions[0].Value)
+	require.Equal(t, int64(7), versions.Versions[0].Revision)
+}
+
+func TestRPCRestoreSecretVersion(t *testing.T) {
+	testName := random.RandomString(10)
+
+	// Create mock storage
+	controller := gomock.NewController(t)
+	mockStorage := mock.NewMockStore(controller)
+	allowTx(mockStorage)
+	allowAuditEvents(mockStorage)
+
+	mockStorage.EXPECT().
+		LockUserSecrets(
+			gomock.Any(),
+			testUsername9,
+		).
+		Times(2).
+		Return(nil)
+
+	mockStorage.EXPECT().
+		GetSecretVersion(
+			gomock.Any(),
+			db.GetSecretVersionParams{Owner: testUsername9, Kind: 1, Name: testName, Revision: 3},
+		).
+		Times(1).
+		Return(db.SecretVersion{Value: []byte("first"), Revision: 3}, nil)
+
+	mockStorage.EXPECT().
+		GetSecretVersion(
+			gomock.Any(),
+			db.GetSecretVersionParams{Owner: testUsername9, Kind: 1, Name: testName, Revision: 4},
+		).
+		Times(1).
+		Return(db.SecretVersion{}, sql.ErrNoRows)
+
+	mockStorage.EXPECT().
+		GetSecret(
+			gomock.Any(),
+			db.GetSecretParams{Owner: testUsername9, Kind: 1, Name: testName},
+		).
+		Times(1).
+		Return(db.Secret{Owner: testUsername9, Kind: 1, Name: testName, Value: []byte("third"), Revision: 9}, nil)
+
+	mockStorage.EXPECT().
+		UpdateSecret(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Times(1).
+		DoAndReturn(func(_ interface{}, params db.UpdateSecretParams) (db.Secret, error) {
+			require.Equal(t, []byte("first"), params.Value)
+			require.Equal(t, int64(9), params.BaseRevision)
+			require.False(t, params.Deleted)
+			return db.Secret{
+				Owner:    params.Owner,
+				Kind:     params.Kind,
+				Name:     params.Name,
+				Value:    params.Value,
+				Modified: params.Modified,
+				Revision: 10,
+			}, nil
+		})
+
+	// Create server
+	testServer := &Server{
+		config:  Config{},
+		storage: mockStorage,
+		tm:      newTestMaker(t),
+	}
+
+	// Run test gRPC server
+	client, closer := runTestServer(testServer, grpc.UnaryInterceptor(testServer.checkAuth))
+	defer closer()
+
+	ctx := newAuthContext(t, testServer.tm, mockStorage, testUsername9)
+
+	secret, err := client.RestoreSecretVersion(ctx, &pb.RestoreVersionRequest{Kind: 1, Name: testName, Revision: 3})
+	require.NoError(t, err)
+	require.Equal(t, []byte("first"), secret.Value)
+	require.Equal(t, int64(10), secret.Revision)
+
+	// Unknown version
+	_, err = client.RestoreSecretVersion(ctx, &pb.RestoreVersionRequest{Kind: 1, Name: testName, Revision: 4})
+	require.Error(t, err)
+
+	e, _ := status.FromError(err)
+	require.Equal(t, codes.NotFound, e.Code())
+}
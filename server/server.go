@@ -3,6 +3,8 @@ package server
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"log"
 	"net"
 	"sync"
@@ -12,7 +14,9 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 
+	"gophkeeper/audit"
 	"gophkeeper/certs"
+	"gophkeeper/crypto"
 	"gophkeeper/db/db"
 	migrate "gophkeeper/db"
 	"gophkeeper/pb"
@@ -22,13 +26,33 @@ import (
 type Server struct {
 	*pb.UnimplementedGophKeeperServer
 	config  Config
-	storage db.Querier
+	storage db.Store
 	tm      token.PasetoMaker
+	limiter *loginLimiter
+	hasher  crypto.PasswordHasher
 	log     zerolog.Logger
 	wg      sync.WaitGroup
+
+	dummyPasshashOnce sync.Once
+	dummyPasshash     string
+
+	auditKey []byte
 }
 
 func NewServer(config Config, logger zerolog.Logger) (*Server, error) {
+	keyring, err := token.LoadKeyring(config.TokenKeys, config.TokenKey)
+	if err != nil {
+		if errors.Is(err, token.ErrEmptyKeyring) {
+			return nil, fmt.Errorf("%w, please generate a token key with 'gs keygen'", err)
+		}
+		return nil, err
+	}
+
+	auditKey, err := audit.ParseKey(config.AuditKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w, please generate an audit key with 'gs audit keygen'", err)
+	}
+
 	pool, err := sql.Open("postgres", config.DSN)
 	if err != nil {
 		return nil, err
@@ -44,18 +68,39 @@ func NewServer(config Config, logger zerolog.Logger) (*Server, error) {
 		return nil, err
 	}
 
-	queries := db.New(pool)
+	store := db.NewStore(pool)
 
 	return &Server{
 		&pb.UnimplementedGophKeeperServer{},
 		config,
-		queries,
-		token.NewPasetoMaker(),
+		store,
+		token.NewPasetoMaker(keyring),
+		newLoginLimiter(),
+		newPasswordHasher(config),
 		logger,
 		sync.WaitGroup{},
+		sync.Once{},
+		"",
+		auditKey,
 	}, nil
 }
 
+// newPasswordHasher returns the hasher new password hashes are made with.
+// Hashes made with the other algorithm are upgraded on login.
+func newPasswordHasher(config Config) crypto.PasswordHasher {
+	if config.PasswordHash == "bcrypt" {
+		return crypto.NewPasswordHasher(crypto.BcryptHasher{Cost: config.BcryptCost})
+	}
+
+	return crypto.NewPasswordHasher(
+		crypto.Argon2idHasher{
+			Memory:      config.Argon2Memory,
+			Iterations:  config.Argon2Iterations,
+			Parallelism: config.Argon2Parallelism,
+		},
+	)
+}
+
 func (s *Server) Run(ctx context.Context) {
 	listener, err := net.Listen("tcp", s.config.Address)
 	if err != nil {
@@ -75,7 +120,7 @@ func (s *Server) Run(ctx context.Context) {
 	}
 
 	grpcServer := grpc.NewServer(
-		grpc.UnaryInterceptor(s.checkAuth),
+		grpc.ChainUnaryInterceptor(s.limitLogin, s.checkAuth),
 		grpc.Creds(creds),
 	)
 	pb.RegisterGophKeeperServer(grpcServer, s)
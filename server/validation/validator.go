@@ -8,7 +8,14 @@ import (
 var (
 	isValidUsername = regexp.MustCompile(`^[a-z0-9_]+$`).MatchString
 
-	ErrInvalidUsername = fmt.Errorf("must contain only lowercase letters, digits, or underscore")
+	ErrInvalidUsername  = fmt.Errorf("must contain only lowercase letters, digits, or underscore")
+	ErrInvalidKeyParams = fmt.Errorf("must be Argon2id parameters of up to 4 GiB memory and 100 iterations")
+	ErrEmptySecretName  = fmt.Errorf("empty secret name")
+)
+
+const (
+	maxKeyMemory     = 4 * 1024 * 1024 // KiB
+	maxKeyIterations = 100
 )
 
 type ErrValueIsTooShortOrTooLong struct {
@@ -45,3 +52,33 @@ func ValidateUsername(value string) error {
 func ValidatePassword(value string) error {
 	return validateString(value, 6, 50)
 }
+
+func ValidateSalt(value []byte) error {
+	return validateString(string(value), 16, 64)
+}
+
+func ValidateKeyCheck(value []byte) error {
+	return validateString(string(value), 32, 32)
+}
+
+// ValidateSecretName checks the name the secret is synced by.
+// Clients check their new names with it before the server rejects them.
+func ValidateSecretName(value string) error {
+	if value == "" {
+		return ErrEmptySecretName
+	}
+	return nil
+}
+
+func ValidateKeyParams(memory, iterations, parallelism uint32) error {
+	if parallelism == 0 || parallelism > 255 {
+		return ErrInvalidKeyParams
+	}
+	if iterations == 0 || iterations > maxKeyIterations {
+		return ErrInvalidKeyParams
+	}
+	if memory < 8*parallelism || memory > maxKeyMemory {
+		return ErrInvalidKeyParams
+	}
+	return nil
+}
@@ -33,3 +33,59 @@ func TestValidateUsername(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateKeyParams(t *testing.T) {
+	tests := []struct {
+		name        string
+		memory      uint32
+		iterations  uint32
+		parallelism uint32
+		expected    error
+	}{
+		{
+			name:        "defaults",
+			memory:      64 * 1024,
+			iterations:  3,
+			parallelism: 4,
+		},
+		{
+			name:        "no parallelism",
+			memory:      64 * 1024,
+			iterations:  3,
+			parallelism: 0,
+			expected:    ErrInvalidKeyParams,
+		},
+		{
+			name:        "no iterations",
+			memory:      64 * 1024,
+			parallelism: 4,
+			expected:    ErrInvalidKeyParams,
+		},
+		{
+			name:        "too little memory",
+			memory:      16,
+			iterations:  3,
+			parallelism: 4,
+			expected:    ErrInvalidKeyParams,
+		},
+		{
+			name:        "too much memory",
+			memory:      maxKeyMemory + 1,
+			iterations:  3,
+			parallelism: 4,
+			expected:    ErrInvalidKeyParams,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateKeyParams(tt.memory, tt.iterations, tt.parallelism)
+			require.Equal(t, tt.expected, err)
+		})
+	}
+}
+
+func TestValidateSecretName(t *testing.T) {
+	require.NoError(t, ValidateSecretName("bank"))
+	require.Equal(t, ErrEmptySecretName, ValidateSecretName(""))
+}
@@ -0,0 +1,266 @@
+package token
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const keyIDSize = 8 // bytes
+
+var (
+	ErrEmptyKeyring = errors.New("keyring has no keys")
+	ErrUnknownKey   = errors.New("token signing key is unknown")
+)
+
+// Key is a token signing (private) key with its ID.
+type Key struct {
+	ID         string
+	PrivateKey ed25519.PrivateKey
+}
+
+// PublicKey is a token verification key with the ID of the signing key it belongs to.
+type PublicKey struct {
+	ID  string
+	Key ed25519.PublicKey
+}
+
+// GenerateKey returns a new random token signing key.
+func GenerateKey() (Key, error) {
+	id := make([]byte, keyIDSize)
+	if _, err := rand.Read(id); err != nil {
+		return Key{}, err
+	}
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return Key{}, err
+	}
+
+	return Key{ID: hex.EncodeToString(id), PrivateKey: privateKey}, nil
+}
+
+// ParseKey parses the key from its "<id>:<hex seed>" representation.
+func ParseKey(s string) (Key, error) {
+	id, seed, err := parseKeyLine(s, ed25519.SeedSize)
+	if err != nil {
+		return Key{}, err
+	}
+
+	return Key{ID: id, PrivateKey: ed25519.NewKeyFromSeed(seed)}, nil
+}
+
+// String returns the "<id>:<hex seed>" representation of the key.
+func (k Key) String() string {
+	return k.ID + ":" + hex.EncodeToString(k.PrivateKey.Seed())
+}
+
+// Public returns the public part of the key.
+func (k Key) Public() PublicKey {
+	return PublicKey{ID: k.ID, Key: k.PrivateKey.Public().(ed25519.PublicKey)}
+}
+
+// ParsePublicKey parses the public key from its "<id>:<hex key>" representation.
+func ParsePublicKey(s string) (PublicKey, error) {
+	id, key, err := parseKeyLine(s, ed25519.PublicKeySize)
+	if err != nil {
+		return PublicKey{}, err
+	}
+
+	return PublicKey{ID: id, Key: key}, nil
+}
+
+// String returns the "<id>:<hex key>" representation of the public key.
+func (k PublicKey) String() string {
+	return k.ID + ":" + hex.EncodeToString(k.Key)
+}
+
+func parseKeyLine(s string, size int) (string, []byte, error) {
+	id, encodedKey, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok || id == "" {
+		return "", nil, fmt.Errorf("key must be in '<id>:<hex key>' format")
+	}
+
+	key, err := hex.DecodeString(encodedKey)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to decode key '%s': %w", id, err)
+	}
+
+	if len(key) != size {
+		return "", nil, fmt.Errorf("key '%s' must be exactly %d bytes long", id, size)
+	}
+
+	return id, key, nil
+}
+
+// readKeyLines reads key lines from the keys file - one key per line.
+// Empty lines and lines starting with '#' are skipped.
+func readKeyLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	lines := []string{}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines, scanner.Err()
+}
+
+// ReadKeys reads signing keys from the keyring file.
+func ReadKeys(path string) ([]Key, error) {
+	lines, err := readKeyLines(path)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []Key{}
+	for _, line := range lines {
+		key, err := ParseKey(line)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// ReadPublicKeys reads verification keys from the public keys file.
+func ReadPublicKeys(path string) ([]PublicKey, error) {
+	lines, err := readKeyLines(path)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []PublicKey{}
+	for _, line := range lines {
+		key, err := ParsePublicKey(line)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// Keyring holds all the keys tokens could be signed with.
+// New tokens are always signed with the current (the last added) key
+// so old keys may be kept around until tokens signed with them expire.
+type Keyring struct {
+	keys    map[string]Key
+	current Key
+}
+
+func NewKeyring(keys ...Key) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, ErrEmptyKeyring
+	}
+
+	keyring := &Keyring{keys: map[string]Key{}}
+	for _, key := range keys {
+		if _, ok := keyring.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate key id '%s'", key.ID)
+		}
+
+		keyring.keys[key.ID] = key
+		keyring.current = key
+	}
+
+	return keyring, nil
+}
+
+// Current returns the key new tokens are signed with.
+func (k *Keyring) Current() Key {
+	return k.current
+}
+
+// Get returns the key by its ID.
+func (k *Keyring) Get(id string) (Key, error) {
+	key, ok := k.keys[id]
+	if !ok {
+		return Key{}, ErrUnknownKey
+	}
+
+	return key, nil
+}
+
+// PublicKeys returns public parts of all the keyring keys.
+func (k *Keyring) PublicKeys() []PublicKey {
+	publicKeys := []PublicKey{}
+	for _, key := range k.keys {
+		publicKeys = append(publicKeys, key.Public())
+	}
+
+	return publicKeys
+}
+
+// LoadKeyring loads keys from the keyring file and/or the single key string.
+// Either could be empty. The single key (if any) becomes the current key.
+func LoadKeyring(path, key string) (*Keyring, error) {
+	keys := []Key{}
+
+	if path != "" {
+		fileKeys, err := ReadKeys(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read token keyring: %w", err)
+		}
+
+		keys = append(keys, fileKeys...)
+	}
+
+	if key != "" {
+		singleKey, err := ParseKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse token key: %w", err)
+		}
+
+		keys = append(keys, singleKey)
+	}
+
+	return NewKeyring(keys...)
+}
+
+// LoadPublicKeys loads public keys from the public keys file and/or the single key string.
+// Either could be empty.
+func LoadPublicKeys(path, key string) ([]PublicKey, error) {
+	keys := []PublicKey{}
+
+	if path != "" {
+		fileKeys, err := ReadPublicKeys(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read token public keys: %w", err)
+		}
+
+		keys = append(keys, fileKeys...)
+	}
+
+	if key != "" {
+		singleKey, err := ParsePublicKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse token public key: %w", err)
+		}
+
+		keys = append(keys, singleKey)
+	}
+
+	return keys, nil
+}
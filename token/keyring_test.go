@@ -0,0 +1,103 @@
+package token
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKeyring(t *testing.T) *Keyring {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+
+	keyring, err := NewKeyring(key)
+	require.NoError(t, err)
+
+	return keyring
+}
+
+func TestParseKey(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+
+	parsedKey, err := ParseKey(key.String())
+	require.NoError(t, err)
+	require.Equal(t, key, parsedKey)
+
+	_, err = ParseKey("nokeyid")
+	require.Error(t, err)
+
+	_, err = ParseKey("someid:notahexstring")
+	require.Error(t, err)
+
+	_, err = ParseKey("someid:0011")
+	require.Error(t, err)
+
+	publicKey, err := ParsePublicKey(key.Public().String())
+	require.NoError(t, err)
+	require.Equal(t, key.Public(), publicKey)
+}
+
+func TestReadKeys(t *testing.T) {
+	oldKey, err := GenerateKey()
+	require.NoError(t, err)
+
+	newKey, err := GenerateKey()
+	require.NoError(t, err)
+
+	keyringFile, err := os.CreateTemp("", "keyring")
+	require.NoError(t, err)
+	defer os.Remove(keyringFile.Name())
+
+	_, err = keyringFile.WriteString("# test keyring\n" + oldKey.String() + "\n\n" + newKey.String() + "\n")
+	require.NoError(t, err)
+
+	keys, err := ReadKeys(keyringFile.Name())
+	require.NoError(t, err)
+	require.Equal(t, []Key{oldKey, newKey}, keys)
+
+	keyring, err := NewKeyring(keys...)
+	require.NoError(t, err)
+	require.Equal(t, newKey, keyring.Current())
+
+	_, err = NewKeyring()
+	require.ErrorIs(t, err, ErrEmptyKeyring)
+
+	_, err = NewKeyring(oldKey, oldKey)
+	require.Error(t, err)
+}
+
+func TestKeyRotation(t *testing.T) {
+	oldKey, err := GenerateKey()
+	require.NoError(t, err)
+
+	oldKeyring, err := NewKeyring(oldKey)
+	require.NoError(t, err)
+
+	oldMaker := NewPasetoMaker(oldKeyring)
+	oldToken, err := oldMaker.CreateToken(testUsername, testSessionID, time.Minute)
+	require.NoError(t, err)
+
+	// Rotate key
+	newKey, err := GenerateKey()
+	require.NoError(t, err)
+
+	newKeyring, err := NewKeyring(oldKey, newKey)
+	require.NoError(t, err)
+
+	newMaker := NewPasetoMaker(newKeyring)
+
+	// Test token signed with the old key is still valid
+	payload, err := newMaker.VerifyToken(oldToken)
+	require.NoError(t, err)
+	require.Equal(t, testUsername, payload.Username)
+
+	// Test token signed with the new key is unknown to the old keyring
+	newToken, err := newMaker.CreateToken(testUsername, testSessionID, time.Minute)
+	require.NoError(t, err)
+
+	_, err = oldMaker.VerifyToken(newToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
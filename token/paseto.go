@@ -6,33 +6,41 @@ import (
 	"github.com/o1egl/paseto"
 )
 
-const (
-	symmetricKey = "cuzyouwillneverknowthissecretkey"
-)
-
-type PasetoMaker struct {
-	paseto       *paseto.V2
-	symmetricKey []byte
+// PasetoVerifier verifies v2.public tokens with public keys only
+// so it can't be used to forge tokens.
+type PasetoVerifier struct {
+	paseto *paseto.V2
+	keys   map[string]PublicKey
 }
 
-func NewPasetoMaker() PasetoMaker {
-	return PasetoMaker{
-		paseto:       paseto.NewV2(),
-		symmetricKey: []byte(symmetricKey),
+func NewPasetoVerifier(keys ...PublicKey) PasetoVerifier {
+	verifier := PasetoVerifier{
+		paseto: paseto.NewV2(),
+		keys:   map[string]PublicKey{},
 	}
-}
 
-func (maker *PasetoMaker) CreateToken(username string, duration time.Duration) (string, error) {
-	payload := NewPayload(username, duration)
+	for _, key := range keys {
+		verifier.keys[key.ID] = key
+	}
 
-	token, err := maker.paseto.Encrypt(maker.symmetricKey, payload, nil)
-	return token, err
+	return verifier
 }
 
-func (maker *PasetoMaker) VerifyToken(token string) (*Payload, error) {
+func (verifier *PasetoVerifier) VerifyToken(token string) (*Payload, error) {
+	var keyID string
+	err := paseto.ParseFooter(token, &keyID)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	key, ok := verifier.keys[keyID]
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
 	payload := &Payload{}
 
-	err := maker.paseto.Decrypt(token, maker.symmetricKey, payload, nil)
+	err = verifier.paseto.Verify(token, key.Key, payload, nil)
 	if err != nil {
 		return nil, ErrInvalidToken
 	}
@@ -44,3 +52,31 @@ func (maker *PasetoMaker) VerifyToken(token string) (*Payload, error) {
 
 	return payload, nil
 }
+
+// PasetoMaker signs v2.public tokens with the keyring keys.
+type PasetoMaker struct {
+	PasetoVerifier
+	keyring *Keyring
+}
+
+func NewPasetoMaker(keyring *Keyring) PasetoMaker {
+	return PasetoMaker{
+		PasetoVerifier: NewPasetoVerifier(keyring.PublicKeys()...),
+		keyring:        keyring,
+	}
+}
+
+// CreateToken creates a token of the user session signed with the current keyring key.
+// Key ID is put into the token footer so it could be verified after key rotation.
+func (maker *PasetoMaker) CreateToken(username, sessionID string, duration time.Duration) (string, error) {
+	payload := NewPayload(username, sessionID, duration)
+	key := maker.keyring.Current()
+
+	token, err := maker.paseto.Sign(key.PrivateKey, payload, key.ID)
+	return token, err
+}
+
+// PublicKeys returns keys clients could verify tokens with.
+func (maker *PasetoMaker) PublicKeys() []PublicKey {
+	return maker.keyring.PublicKeys()
+}
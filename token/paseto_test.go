@@ -7,16 +7,19 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-var testUsername = "someusername"
+var (
+	testUsername  = "someusername"
+	testSessionID = "somesessionid"
+)
 
 func TestPasetoMaker(t *testing.T) {
-	maker := NewPasetoMaker()
+	maker := NewPasetoMaker(newTestKeyring(t))
 
 	duration := time.Minute
 	issuedAt := time.Now()
 	expiredAt := issuedAt.Add(duration)
 
-	token, err := maker.CreateToken(testUsername, duration)
+	token, err := maker.CreateToken(testUsername, testSessionID, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 
@@ -25,14 +28,15 @@ func TestPasetoMaker(t *testing.T) {
 	require.NotEmpty(t, payload)
 
 	require.Equal(t, testUsername, payload.Username)
+	require.Equal(t, testSessionID, payload.SessionID)
 	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
 	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
 }
 
 func TestExpiredPasetoToken(t *testing.T) {
-	maker := NewPasetoMaker()
+	maker := NewPasetoMaker(newTestKeyring(t))
 
-	token, err := maker.CreateToken(testUsername, -time.Minute)
+	token, err := maker.CreateToken(testUsername, testSessionID, -time.Minute)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 
@@ -41,3 +45,39 @@ func TestExpiredPasetoToken(t *testing.T) {
 	require.EqualError(t, err, ErrExpiredToken.Error())
 	require.Nil(t, payload)
 }
+
+func TestPasetoVerifier(t *testing.T) {
+	maker := NewPasetoMaker(newTestKeyring(t))
+	verifier := NewPasetoVerifier(maker.PublicKeys()...)
+
+	token, err := maker.CreateToken(testUsername, testSessionID, time.Minute)
+	require.NoError(t, err)
+
+	payload, err := verifier.VerifyToken(token)
+	require.NoError(t, err)
+	require.Equal(t, testUsername, payload.Username)
+
+	// Test token signed with unknown key
+	otherMaker := NewPasetoMaker(newTestKeyring(t))
+
+	otherToken, err := otherMaker.CreateToken(testUsername, testSessionID, time.Minute)
+	require.NoError(t, err)
+
+	_, err = verifier.VerifyToken(otherToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	// Test token signed with a forged key under known key ID
+	forgedKey, err := GenerateKey()
+	require.NoError(t, err)
+	forgedKey.ID = maker.keyring.Current().ID
+
+	forgedKeyring, err := NewKeyring(forgedKey)
+	require.NoError(t, err)
+
+	forgedMaker := NewPasetoMaker(forgedKeyring)
+	forgedToken, err := forgedMaker.CreateToken(testUsername, testSessionID, time.Minute)
+	require.NoError(t, err)
+
+	_, err = verifier.VerifyToken(forgedToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
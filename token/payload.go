@@ -12,13 +12,15 @@ var (
 
 type Payload struct {
 	Username  string
+	SessionID string
 	IssuedAt  time.Time
 	ExpiredAt time.Time
 }
 
-func NewPayload(username string, duration time.Duration) Payload {
+func NewPayload(username, sessionID string, duration time.Duration) Payload {
 	return Payload{
 		Username:  username,
+		SessionID: sessionID,
 		IssuedAt:  time.Now(),
 		ExpiredAt: time.Now().Add(duration),
 	}
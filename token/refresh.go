@@ -0,0 +1,40 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+const (
+	refreshTokenSize = 32 // bytes
+	familyIDSize     = 16 // bytes
+)
+
+// NewRefreshToken returns a new random opaque refresh token.
+func NewRefreshToken() (string, error) {
+	value := make([]byte, refreshTokenSize)
+	if _, err := rand.Read(value); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(value), nil
+}
+
+// HashRefreshToken returns the refresh token hash to be stored instead of the token itself.
+func HashRefreshToken(value string) string {
+	hash := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(hash[:])
+}
+
+// NewFamilyID returns a new random refresh token family ID.
+// All refresh tokens rotated from the same login share the family.
+func NewFamilyID() (string, error) {
+	id := make([]byte, familyIDSize)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,136 @@
+// Package totp implements RFC 6238 time-based one-time passwords
+// compatible with common authenticator apps (SHA1, 6 digits, 30s period).
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	secretSize       = 20 // bytes
+	recoveryCodeSize = 5  // bytes
+	digits           = 6
+	period           = 30 // seconds
+	skew             = 1  // periods accepted before and after the current one
+)
+
+// Reasons of the errdetails.ErrorInfo attached to login errors.
+const (
+	ErrorDomain          = "gophkeeper"
+	SecondFactorRequired = "SECOND_FACTOR_REQUIRED"
+	SecondFactorInvalid  = "SECOND_FACTOR_INVALID"
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret returns a new random base32 encoded secret.
+func GenerateSecret() (string, error) {
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return encoding.EncodeToString(secret), nil
+}
+
+// ProvisioningURI returns the otpauth:// URI to be imported into an authenticator app.
+func ProvisioningURI(issuer, account, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(digits))
+	query.Set("period", fmt.Sprint(period))
+
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + account,
+		RawQuery: query.Encode(),
+	}
+
+	return u.String()
+}
+
+// GenerateCode returns the code for the secret at the time provided.
+func GenerateCode(secret string, t time.Time) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode totp secret: %w", err)
+	}
+
+	return hotp(key, uint64(t.Unix()/period), digits), nil
+}
+
+// Validate checks the code against the secret at the time provided.
+// It returns the time step the code belongs to so the caller could
+// reject codes that were already used.
+func Validate(secret, code string, t time.Time) (int64, bool) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != digits {
+		return 0, false
+	}
+
+	current := t.Unix() / period
+	for step := current - skew; step <= current+skew; step++ {
+		if hmac.Equal([]byte(hotp(key, uint64(step), digits)), []byte(code)) {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// hotp is the RFC 4226 HMAC-based one-time password.
+func hotp(key []byte, counter uint64, length int) string {
+	message := make([]byte, 8)
+	binary.BigEndian.PutUint64(message, counter)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulo := uint32(1)
+	for i := 0; i < length; i++ {
+		modulo *= 10
+	}
+
+	return fmt.Sprintf("%0*d", length, value%modulo)
+}
+
+// GenerateRecoveryCodes returns one-time codes to log in with when the authenticator is lost.
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := []string{}
+	for i := 0; i < n; i++ {
+		code := make([]byte, recoveryCodeSize)
+		if _, err := rand.Read(code); err != nil {
+			return nil, err
+		}
+
+		encoded := hex.EncodeToString(code)
+		codes = append(codes, encoded[:5]+"-"+encoded[5:])
+	}
+
+	return codes, nil
+}
+
+// HashRecoveryCode returns the recovery code hash to be stored instead of the code itself.
+// Dashes and case are ignored.
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	hash := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(hash[:])
+}